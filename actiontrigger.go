@@ -3,13 +3,16 @@ package via
 import (
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/go-via/via/h"
 )
 
 // actionTrigger represents a trigger to an event handler fn
 type actionTrigger struct {
-	id string
+	id   string
+	noJS bool
+	page *Context
 }
 
 // ActionTriggerOption configures behavior of action triggers
@@ -18,9 +21,10 @@ type ActionTriggerOption interface {
 }
 
 type triggerOpts struct {
-	hasSignal bool
-	signalID  string
-	value     string
+	hasSignal  bool
+	signalID   string
+	value      string
+	optimistic []optimisticOpt
 }
 
 type withSignalOpt struct {
@@ -50,11 +54,49 @@ func WithSignalInt(sig *signal, value int) ActionTriggerOption {
 	}
 }
 
-func buildOnExpr(base string, opts *triggerOpts) string {
+// reconcileParam is the query parameter of action requests that lists the signals
+// changed optimistically in the browser.
+const reconcileParam = "reconcile"
+
+type optimisticOpt struct {
+	signalID string
+	expr     string
+}
+
+func (o optimisticOpt) apply(opts *triggerOpts) {
+	opts.optimistic = append(opts.optimistic, o)
+}
+
+// Optimistic sets the signal to the result of the JS expression in the browser as soon
+// as the action is triggered, e.g. to show a like before the server confirms it. The
+// action still receives the previous value of the signal. Once the action finished or
+// failed, the server sends the value of the signal, which confirms the change or rolls
+// it back.
+//
+// Example:
+//
+//	like.OnClick(via.Optimistic(likes, "$"+likes.ID()+"+1"))
+func Optimistic(sig *signal, expr string) ActionTriggerOption {
+	return optimisticOpt{signalID: sig.ID(), expr: expr}
+}
+
+func buildOnExpr(id string, opts *triggerOpts) string {
+	expr := actionURL(id)
+	if len(opts.optimistic) > 0 {
+		// the request carries the signals as they were before the optimistic change
+		ids := make([]string, len(opts.optimistic))
+		for i, o := range opts.optimistic {
+			ids[i] = o.signalID
+		}
+		expr = fmt.Sprintf("@get('/_action/%s?%s=%s')", id, reconcileParam, strings.Join(ids, ","))
+		for _, o := range opts.optimistic {
+			expr += fmt.Sprintf(";$%s=(%s)", o.signalID, o.expr)
+		}
+	}
 	if !opts.hasSignal {
-		return base
+		return expr
 	}
-	return fmt.Sprintf("$%s=%s;%s", opts.signalID, opts.value, base)
+	return fmt.Sprintf("$%s=%s;%s", opts.signalID, opts.value, expr)
 }
 
 func applyOptions(options ...ActionTriggerOption) triggerOpts {
@@ -73,14 +115,14 @@ func actionURL(id string) string {
 // to element nodes in a view.
 func (a *actionTrigger) OnClick(options ...ActionTriggerOption) h.H {
 	opts := applyOptions(options...)
-	return h.Data("on:click", buildOnExpr(actionURL(a.id), &opts))
+	return a.withNoJSSubmit(h.Data("on:click", buildOnExpr(a.id, &opts)))
 }
 
 // OnChange returns a via.h DOM attribute that triggers on input change. It can be added
 // to element nodes in a view.
 func (a *actionTrigger) OnChange(options ...ActionTriggerOption) h.H {
 	opts := applyOptions(options...)
-	return h.Data("on:change__debounce.200ms", buildOnExpr(actionURL(a.id), &opts))
+	return h.Data("on:change__debounce.200ms", buildOnExpr(a.id, &opts))
 }
 
 // OnKeyDown returns a via.h DOM attribute that triggers when a key is pressed.
@@ -92,5 +134,66 @@ func (a *actionTrigger) OnKeyDown(key string, options ...ActionTriggerOption) h.
 	if key != "" {
 		condition = fmt.Sprintf("evt.key==='%s' &&", key)
 	}
-	return h.Data("on:keydown", fmt.Sprintf("%s%s", condition, buildOnExpr(actionURL(a.id), &opts)))
+	return h.Data("on:keydown", fmt.Sprintf("%s%s", condition, buildOnExpr(a.id, &opts)))
+}
+
+// OnDragStart returns a via.h DOM attribute that triggers when the user starts dragging
+// the element. The element ID is set as the drag payload so it can be read by OnDrop.
+// The element must also carry the draggable="true" attribute.
+func (a *actionTrigger) OnDragStart(options ...ActionTriggerOption) h.H {
+	opts := applyOptions(options...)
+	return h.Data("on:dragstart", "evt.dataTransfer.setData('text/plain',el.id);"+buildOnExpr(a.id, &opts))
+}
+
+// OnDragOver returns a via.h DOM attribute that marks the element as a drop target and
+// triggers while a dragged element is held over it. The action triggers at most once
+// every 200ms to avoid flooding the server.
+func (a *actionTrigger) OnDragOver(options ...ActionTriggerOption) h.H {
+	opts := applyOptions(options...)
+	return h.Data("on:dragover", fmt.Sprintf(
+		"evt.preventDefault();if(Date.now()-(el._viaDragOver||0)>200){el._viaDragOver=Date.now();%s}",
+		buildOnExpr(a.id, &opts)))
+}
+
+// OnDrop returns a via.h DOM attribute that triggers when a dragged element is dropped on
+// the element. Before the action triggers, the ID of the dragged element is written to
+// the source signal and the ID of the drop target to the target signal.
+//
+// Example:
+//
+//	h.Li(h.ID("todo"), move.OnDragOver(), move.OnDrop(source, target))
+func (a *actionTrigger) OnDrop(source, target *signal, options ...ActionTriggerOption) h.H {
+	opts := applyOptions(options...)
+	return h.Data("on:drop__prevent", fmt.Sprintf("$%s=evt.dataTransfer.getData('text/plain');$%s=el.id;%s",
+		source.ID(), target.ID(), buildOnExpr(a.id, &opts)))
+}
+
+// OnIntersect returns a via.h DOM attribute that triggers every time the element scrolls
+// into the viewport. threshold is the visible fraction of the element required to trigger
+// and is rounded down to one of 0 (any pixel), 0.5 or 1 (fully visible).
+//
+// Example:
+//
+//	h.Div(h.ID("comments"), loadComments.OnIntersect(0.5))
+func (a *actionTrigger) OnIntersect(threshold float64, options ...ActionTriggerOption) h.H {
+	opts := applyOptions(options...)
+	attr := "on-intersect"
+	switch {
+	case threshold >= 1:
+		attr += "__full"
+	case threshold >= 0.5:
+		attr += "__half"
+	}
+	return h.Data(attr, buildOnExpr(a.id, &opts))
+}
+
+// OnEvent returns a via.h DOM attribute that triggers when the element receives the given
+// DOM event. It is useful to connect custom events dispatched by third-party JS to actions.
+//
+// Example:
+//
+//	h.Div(save.OnEvent("editor-save"))
+func (a *actionTrigger) OnEvent(event string, options ...ActionTriggerOption) h.H {
+	opts := applyOptions(options...)
+	return h.Data("on:"+event, buildOnExpr(a.id, &opts))
 }
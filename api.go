@@ -0,0 +1,156 @@
+package via
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// APIContext is the context of a JSON API request registered with V.API.
+type APIContext struct {
+	// The HTTP request.
+	Request *http.Request
+
+	app       *V
+	w         http.ResponseWriter
+	status    int
+	requestID string
+}
+
+// APIError is an error returned by an API handler that is sent to the client with the
+// given HTTP status code. Other errors are logged and sent as 500 Internal Server Error
+// without their message.
+type APIError struct {
+	Status  int
+	Message string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%d %s", e.Status, e.Message)
+}
+
+// NewAPIError returns an *APIError with the given status code and message.
+func NewAPIError(status int, format string, a ...any) *APIError {
+	return &APIError{Status: status, Message: fmt.Sprintf(format, a...)}
+}
+
+// PathParam returns the value of the given wildcard of the route pattern.
+func (c *APIContext) PathParam(name string) string {
+	return c.Request.PathValue(name)
+}
+
+// QueryParam returns the value of the given query parameter or an empty string.
+func (c *APIContext) QueryParam(name string) string {
+	return c.Request.URL.Query().Get(name)
+}
+
+// Decode decodes the JSON request body into v. It returns a 400 *APIError if the body
+// is not valid JSON.
+func (c *APIContext) Decode(v any) error {
+	if err := json.NewDecoder(c.Request.Body).Decode(v); err != nil {
+		return NewAPIError(http.StatusBadRequest, "invalid JSON body: %v", err)
+	}
+	return nil
+}
+
+// Header returns the response headers.
+func (c *APIContext) Header() http.Header {
+	return c.w.Header()
+}
+
+// Status sets the status code of a successful response. Default: 200 OK, or 204 No
+// Content if the handler returns a nil value.
+func (c *APIContext) Status(code int) {
+	c.status = code
+}
+
+// ClientIP returns the IP address of the client, see Context.ClientIP.
+func (c *APIContext) ClientIP() string {
+	return c.app.clientIP(c.Request)
+}
+
+// RequestID returns the ID of the request, see Context.RequestID.
+func (c *APIContext) RequestID() string {
+	return c.requestID
+}
+
+// PageContext returns the live page context whose ID is sent in the 'via-ctx' query
+// parameter or the Via-Ctx header, so API calls made from a page can read and update
+// its state. Call Sync on the context to push changes to the browser.
+func (c *APIContext) PageContext() (*Context, error) {
+	cID := c.Request.Header.Get("Via-Ctx")
+	if cID == "" {
+		cID = c.QueryParam("via-ctx")
+	}
+	ctx, err := c.app.getCtx(cID)
+	if err != nil {
+		return nil, NewAPIError(http.StatusNotFound, "page context not found")
+	}
+	if !c.app.clientMatches(ctx, c.Request) {
+		return nil, NewAPIError(http.StatusForbidden, "client does not match the page context")
+	}
+	return ctx, nil
+}
+
+// API registers a JSON API route. The value returned by the handler is sent as JSON,
+// and a returned error is mapped to a status code, see APIError. API routes are
+// covered by Options.CORS.
+//
+// Example:
+//
+//	v.API("GET /api/items/{id}", func(c *via.APIContext) (any, error) {
+//		item, ok := items[c.PathParam("id")]
+//		if !ok {
+//			return nil, via.NewAPIError(http.StatusNotFound, "item not found")
+//		}
+//		return item, nil
+//	})
+func (v *V) API(pattern string, f func(c *APIContext) (any, error)) {
+	v.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		c := &APIContext{Request: r, app: v, w: w, requestID: requestID(w, r)}
+		res, err := func() (res any, err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					err = panicErr(r)
+				}
+			}()
+			return f(c)
+		}()
+		if err != nil {
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				v.logErr(nil, "api %s failed: req-id=%s err=%v", pattern, c.requestID, err)
+				v.reportErr(nil, PhaseAPI, err)
+				apiErr = NewAPIError(http.StatusInternalServerError, "%s", http.StatusText(http.StatusInternalServerError))
+			}
+			v.writeJSON(w, apiErr.Status, map[string]string{"error": apiErr.Message})
+			return
+		}
+		status := c.status
+		if status == 0 {
+			status = http.StatusOK
+			if res == nil {
+				status = http.StatusNoContent
+			}
+		}
+		if res == nil {
+			w.WriteHeader(status)
+			return
+		}
+		v.writeJSON(w, status, res)
+	})
+	v.apiPatterns[pattern] = true
+}
+
+func (v *V) writeJSON(w http.ResponseWriter, status int, res any) {
+	b, err := json.Marshal(res)
+	if err != nil {
+		v.logErr(nil, "api response marshal failed: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(b)
+}
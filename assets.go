@@ -0,0 +1,136 @@
+package via
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andybalholm/brotli"
+)
+
+// assetsPath is the route prefix of content-hashed framework assets.
+const assetsPath = "/_assets/"
+
+// asset is a framework file, such as the Datastar bundle, served with a content-hashed
+// URL, validators and pre-compressed bodies.
+type asset struct {
+	contentType  string
+	hashedName   string
+	hash         string
+	modTime      time.Time
+	compressOnce sync.Once
+	bodies       map[string][]byte // by content encoding, "" is uncompressed
+}
+
+// newAsset returns an asset with the given file name, e.g. 'datastar.js'.
+func newAsset(name, contentType string, body []byte) *asset {
+	sum := sha256.Sum256(body)
+	hash := hex.EncodeToString(sum[:8])
+	ext := path.Ext(name)
+	return &asset{
+		contentType: contentType,
+		hashedName:  strings.TrimSuffix(name, ext) + "." + hash + ext,
+		hash:        hash,
+		modTime:     time.Now().UTC().Truncate(time.Second),
+		bodies:      map[string][]byte{"": body},
+	}
+}
+
+// compress compresses the body with the best compression on first use, as it is served
+// many times. Bodies of already compressed formats, such as images, are not compressed.
+func (a *asset) compress() {
+	if !compressibleType(a.contentType) {
+		return
+	}
+	body := a.bodies[""]
+	var buf bytes.Buffer
+	bw := brotli.NewWriterLevel(&buf, brotli.BestCompression)
+	if _, err := bw.Write(body); err == nil && bw.Close() == nil {
+		a.bodies["br"] = bytes.Clone(buf.Bytes())
+	}
+	buf.Reset()
+	gw, _ := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if _, err := gw.Write(body); err == nil && gw.Close() == nil {
+		a.bodies["gzip"] = bytes.Clone(buf.Bytes())
+	}
+}
+
+// compressibleType reports whether content of the given type benefits from compression.
+func compressibleType(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	switch {
+	case strings.HasPrefix(mediaType, "text/"), strings.HasSuffix(mediaType, "+xml"), strings.HasSuffix(mediaType, "+json"):
+		return true
+	}
+	switch mediaType {
+	case "application/javascript", "application/json", "application/xml", "application/wasm",
+		"image/svg+xml", "image/x-icon", "font/ttf", "font/otf":
+		return true
+	}
+	return false
+}
+
+// url returns the content-hashed URL of the asset.
+func (a *asset) url() string {
+	return assetsPath + a.hashedName
+}
+
+// serveAsset writes the asset in the encoding preferred by the client. Responses for the
+// content-hashed URL are cached indefinitely; others must be revalidated with the ETag.
+func (v *V) serveAsset(w http.ResponseWriter, r *http.Request, a *asset, hashed bool) {
+	encoding := v.acceptedEncoding(r)
+	a.compressOnce.Do(a.compress)
+	body, ok := a.bodies[encoding]
+	if !ok {
+		encoding, body = "", a.bodies[""]
+	}
+	etag := `"` + a.hash
+	if encoding != "" {
+		etag += "-" + encoding
+		w.Header().Set("Content-Encoding", encoding)
+	}
+	w.Header().Set("ETag", etag+`"`)
+	w.Header().Set("Content-Type", a.contentType)
+	w.Header().Add("Vary", "Accept-Encoding")
+	if hashed {
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	} else {
+		w.Header().Set("Cache-Control", "no-cache")
+	}
+	http.ServeContent(w, r, "", a.modTime, bytes.NewReader(body))
+}
+
+// setAsset makes the asset servable at its content-hashed URL. If slot is not nil, the
+// asset replaces the one in slot, a field of v such as v.datastarAsset that is read
+// with loadAsset.
+func (v *V) setAsset(slot **asset, a *asset) {
+	v.assetsMu.Lock()
+	defer v.assetsMu.Unlock()
+	if slot != nil {
+		if *slot != nil {
+			delete(v.assets, (*slot).hashedName)
+		}
+		*slot = a
+	}
+	v.assets[a.hashedName] = a
+}
+
+// loadAsset returns the asset in slot, see setAsset.
+func (v *V) loadAsset(slot **asset) *asset {
+	v.assetsMu.RLock()
+	defer v.assetsMu.RUnlock()
+	return *slot
+}
+
+func (v *V) getAsset(hashedName string) (*asset, bool) {
+	v.assetsMu.RLock()
+	defer v.assetsMu.RUnlock()
+	a, ok := v.assets[hashedName]
+	return a, ok
+}
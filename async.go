@@ -0,0 +1,94 @@
+package via
+
+import "sync"
+
+// AsyncValue is a value loaded in the background with Async.
+type AsyncValue[T any] struct {
+	c       *Context
+	load    func() (T, error)
+	mu      sync.RWMutex
+	loading bool
+	val     T
+	err     error
+}
+
+// Async runs load in a goroutine and returns a handle to its result, so the view can
+// render a loading state while slow data is fetched. When load returns, the given
+// context is synced; pass the context of a component to only re-render that component.
+// Panics of load are reported as errors.
+//
+// Example:
+//
+//	orders := via.Async(c, func() ([]Order, error) { return db.Orders(ctx) })
+//	c.View(func() h.H {
+//		switch {
+//		case orders.Loading():
+//			return h.P(h.Text("Loading…"))
+//		case orders.Err() != nil:
+//			return h.P(h.Text(orders.Err().Error()))
+//		}
+//		return ordersTable(orders.Value())
+//	})
+func Async[T any](c *Context, load func() (T, error)) *AsyncValue[T] {
+	a := &AsyncValue[T]{c: c, load: load}
+	a.Reload()
+	return a
+}
+
+// Loading reports whether the value is being loaded.
+func (a *AsyncValue[T]) Loading() bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.loading
+}
+
+// Value returns the last loaded value, or the zero value before the first load
+// completes or after a failed load.
+func (a *AsyncValue[T]) Value() T {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.val
+}
+
+// Err returns the error of the last load or nil.
+func (a *AsyncValue[T]) Err() error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.err
+}
+
+// Reload loads the value again in the background. The previous value is kept while
+// loading. Reload does nothing if a load is already running.
+func (a *AsyncValue[T]) Reload() {
+	a.mu.Lock()
+	if a.loading {
+		a.mu.Unlock()
+		return
+	}
+	a.loading = true
+	a.mu.Unlock()
+
+	go func() {
+		val, err := func() (val T, err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					err = panicErr(r)
+					a.c.app.logErr(a.c, "async load failed: %v", err)
+					a.c.app.reportErr(a.c, PhaseAsync, err)
+				}
+			}()
+			return a.load()
+		}()
+		a.mu.Lock()
+		a.loading = false
+		a.err = err
+		if err == nil {
+			a.val = val
+		} else {
+			var zero T
+			a.val = zero
+		}
+		a.mu.Unlock()
+		a.c.Sync()
+	}()
+}
@@ -0,0 +1,138 @@
+package via
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+)
+
+// AuditEntry is a record of an action run, passed to Options.Audit.
+type AuditEntry struct {
+	Time      time.Time `json:"time"`
+	ContextID string    `json:"context_id"`
+	RequestID string    `json:"request_id"`
+	// The user set with Context.SetUser, if any.
+	User string `json:"user,omitempty"`
+	// The name of an action registered with ActionNamed, or its ID.
+	Action   string        `json:"action"`
+	ActionID string        `json:"action_id"`
+	Duration time.Duration `json:"duration"`
+	// IDs of the signals whose values the action changed.
+	Changed []string `json:"changed,omitempty"`
+	// The error of a panic or ErrActionTimeout, if any.
+	Err string `json:"err,omitempty"`
+}
+
+// AuditLog returns an audit sink for Options.Audit that writes each entry to w as a
+// line of JSON.
+func AuditLog(w io.Writer) func(e AuditEntry) {
+	var mu sync.Mutex
+	enc := json.NewEncoder(w)
+	return func(e AuditEntry) {
+		mu.Lock()
+		defer mu.Unlock()
+		_ = enc.Encode(e)
+	}
+}
+
+// ActionNamed registers an action like Action with a name that identifies it in logs
+// and audit entries, e.g. 'delete-user'.
+func (c *Context) ActionNamed(name string, f func()) *actionTrigger {
+	a := c.Action(f)
+	if a == nil {
+		return nil
+	}
+	page := c
+	if c.isComponent() {
+		page = c.parentPageCtx
+	}
+	page.mu.Lock()
+	defer page.mu.Unlock()
+	if page.actionNames == nil {
+		page.actionNames = make(map[string]string)
+	}
+	page.actionNames[a.id] = name
+	return a
+}
+
+// actionName returns the name of the action registered with ActionNamed, or its ID.
+func (c *Context) actionName(id string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if name, ok := c.actionNames[id]; ok {
+		return name
+	}
+	return id
+}
+
+// SetUser identifies the user of the context in audit entries, e.g. after checking
+// authentication in V.BeforePage.
+func (c *Context) SetUser(user string) {
+	if c.isComponent() {
+		c = c.parentPageCtx
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.user = user
+}
+
+// User returns the user set with SetUser, or an empty string.
+func (c *Context) User() string {
+	if c.isComponent() {
+		c = c.parentPageCtx
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.user
+}
+
+// signalValues returns the values of the signals of the context, or nil if auditing is
+// disabled.
+func (c *Context) signalValues() map[string]any {
+	if c.app.cfg.Audit == nil {
+		return nil
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	vals := make(map[string]any)
+	c.signals.Range(func(sigID, value any) bool {
+		switch sig := value.(type) {
+		case *signal:
+			vals[sigID.(string)] = sig.val
+		case collectionSignal:
+			vals[sigID.(string)] = sig.snapshot()
+		}
+		return true
+	})
+	return vals
+}
+
+// audit records the run of an action if Options.Audit is set. before holds the signal
+// values from before the action.
+func (v *V) audit(c *Context, actionID string, before map[string]any, dur time.Duration, err error) {
+	if v.cfg.Audit == nil {
+		return
+	}
+	e := AuditEntry{
+		Time:      time.Now().Add(-dur),
+		ContextID: c.id,
+		RequestID: c.RequestID(),
+		User:      c.User(),
+		Action:    c.actionName(actionID),
+		ActionID:  actionID,
+		Duration:  dur,
+	}
+	for id, val := range c.signalValues() {
+		if prev, ok := before[id]; !ok || !reflect.DeepEqual(prev, val) {
+			e.Changed = append(e.Changed, id)
+		}
+	}
+	slices.Sort(e.Changed)
+	if err != nil {
+		e.Err = err.Error()
+	}
+	v.cfg.Audit(e)
+}
@@ -0,0 +1,91 @@
+package via
+
+import (
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"slices"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// AutoTLS configures automatic TLS certificates from Let's Encrypt.
+type AutoTLS struct {
+	// Domains the certificates are requested for. Requests for other hosts are refused.
+	Domains []string
+
+	// Directory where certificates are cached across restarts. Defaults to
+	// ".via/autocert". Without a persistent cache, Let's Encrypt rate limits are
+	// reached quickly.
+	CacheDir string
+
+	// Optional contact email for the ACME account.
+	Email string
+
+	// Address of the plain HTTP server that answers ACME challenges and redirects
+	// all other requests to HTTPS. Defaults to ":80".
+	HTTPAddr string
+}
+
+// autoTLSServe configures srv with certificates managed by autocert, starts the HTTP
+// challenge and redirect server, and serves srv with TLS. The TLSConfig of
+// Options.HTTPServer is kept, see autoTLSConfig.
+func (v *V) autoTLSServe(srv *http.Server) error {
+	cfg := v.cfg.AutoTLS
+	cacheDir := cfg.CacheDir
+	if cacheDir == "" {
+		cacheDir = ".via/autocert"
+	}
+	httpAddr := cfg.HTTPAddr
+	if httpAddr == "" {
+		httpAddr = ":80"
+	}
+	m := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(cfg.Domains...),
+		Cache:      autocert.DirCache(cacheDir),
+		Email:      cfg.Email,
+	}
+	srv.TLSConfig = autoTLSConfig(m, srv.TLSConfig)
+
+	challengeSrv := &http.Server{Addr: httpAddr, Handler: m.HTTPHandler(nil)}
+	v.serverMu.Lock()
+	v.extraServers = append(v.extraServers, challengeSrv)
+	v.serverMu.Unlock()
+	go func() {
+		err := challengeSrv.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			v.logErr(nil, "autotls http server failed: %v", err)
+		}
+	}()
+	return srv.ListenAndServeTLS("", "")
+}
+
+// autoTLSConfig returns the TLS config served with AutoTLS: a copy of the user config,
+// if any, with the certificates and ACME protocol of m. A GetCertificate of the user
+// config is asked first, and m is used when it returns no certificate.
+func autoTLSConfig(m *autocert.Manager, user *tls.Config) *tls.Config {
+	if user == nil {
+		return m.TLSConfig()
+	}
+	cfg := user.Clone()
+	if userGet := user.GetCertificate; userGet != nil {
+		cfg.GetCertificate = func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			cert, err := userGet(hello)
+			if cert != nil || err != nil {
+				return cert, err
+			}
+			return m.GetCertificate(hello)
+		}
+	} else {
+		cfg.GetCertificate = m.GetCertificate
+	}
+	if len(cfg.NextProtos) == 0 {
+		cfg.NextProtos = []string{"h2", "http/1.1"}
+	}
+	if !slices.Contains(cfg.NextProtos, acme.ALPNProto) {
+		cfg.NextProtos = append(slices.Clip(cfg.NextProtos), acme.ALPNProto)
+	}
+	return cfg
+}
@@ -0,0 +1,76 @@
+package via
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"net/netip"
+)
+
+// ClientBinding selects which client properties a context is bound to. Values can be
+// combined, e.g. BindClientIP | BindUserAgent.
+type ClientBinding int
+
+const (
+	// BindClientIP binds a context to the network of the client that loaded the page:
+	// the /24 prefix of IPv4 and the /64 prefix of IPv6 addresses, so clients whose
+	// address changes within their network are not rejected.
+	BindClientIP ClientBinding = 1 << iota
+	// BindUserAgent binds a context to the User-Agent header of the client that
+	// loaded the page.
+	BindUserAgent
+)
+
+// clientFingerprint returns a hash of the client properties selected by the configured
+// ClientBinding, or an empty string if client binding is disabled.
+func (v *V) clientFingerprint(r *http.Request) string {
+	if v.cfg.ClientBinding == 0 {
+		return ""
+	}
+	hash := sha256.New()
+	if v.cfg.ClientBinding&BindClientIP != 0 {
+		hash.Write([]byte(ipNetwork(v.clientIP(r))))
+	}
+	hash.Write([]byte{0})
+	if v.cfg.ClientBinding&BindUserAgent != 0 {
+		hash.Write([]byte(r.UserAgent()))
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// ipNetwork returns the /24 prefix of an IPv4 or the /64 prefix of an IPv6 address,
+// or the address as is if it can not be parsed.
+func ipNetwork(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ip
+	}
+	addr = addr.Unmap()
+	bits := 64
+	if addr.Is4() {
+		bits = 24
+	}
+	prefix, _ := addr.Prefix(bits)
+	return prefix.String()
+}
+
+// clientMatches reports whether the request comes from the client and tenant the context
+// is bound to.
+func (v *V) clientMatches(c *Context, r *http.Request) bool {
+	if c.tenant != v.tenant(r) {
+		return false
+	}
+	return c.fingerprint == "" || subtle.ConstantTimeCompare([]byte(c.fingerprint), []byte(v.clientFingerprint(r))) == 1
+}
+
+// checkClientBinding reports whether the request comes from the client and tenant the
+// context is bound to. It writes a 403 response and logs a warning if it does not.
+func (v *V) checkClientBinding(c *Context, w http.ResponseWriter, r *http.Request) bool {
+	if v.clientMatches(c, r) {
+		return true
+	}
+	v.logWarn(c, "rejected %s %s: client does not match the context binding (client=%s)", r.Method, r.URL.Path, v.clientIP(r))
+	w.WriteHeader(http.StatusForbidden)
+	return false
+}
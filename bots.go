@@ -0,0 +1,43 @@
+package via
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// botNames are the lower-case product names in the user agents of common crawlers and
+// link unfurlers. Generic fragments such as "bot" or "+http" also occur in the user
+// agents of browsers and phones, e.g. CUBOT, so only vetted names are listed.
+var botNames = []string{
+	// search engines
+	"googlebot", "google-inspectiontool", "googleother", "storebot-google", "adsbot-google",
+	"mediapartners-google", "bingbot", "bingpreview", "msnbot", "adidxbot", "slurp",
+	"duckduckbot", "baiduspider", "yandexbot", "yandeximages", "yandexmobilebot", "applebot",
+	"petalbot", "seznambot", "sogou web spider", "exabot", "yeti",
+	// link unfurlers
+	"facebookexternalhit", "facebookcatalog", "meta-externalagent", "twitterbot", "linkedinbot",
+	"slackbot", "slack-imgproxy", "discordbot", "telegrambot", "whatsapp", "skypeuripreview",
+	"pinterestbot", "redditbot", "embedly", "quora link preview", "vkshare", "iframely",
+	// archivers, validators and SEO tools
+	"ia_archiver", "archive.org_bot", "ccbot", "w3c_validator", "chrome-lighthouse",
+	"ahrefsbot", "semrushbot", "mj12bot", "dotbot", "rogerbot",
+}
+
+// botUserAgentRe matches the bot names as whole words in a lower-case user agent.
+var botUserAgentRe = regexp.MustCompile(`\b(?:` + quoteAll(botNames) + `)\b`)
+
+func quoteAll(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = regexp.QuoteMeta(name)
+	}
+	return strings.Join(quoted, "|")
+}
+
+// IsBot reports whether the request comes from a common search engine crawler or link
+// unfurler, judged by its User-Agent header. Use it for Options.StaticSnapshot.
+func IsBot(r *http.Request) bool {
+	ua := r.UserAgent()
+	return ua != "" && botUserAgentRe.MatchString(strings.ToLower(ua))
+}
@@ -0,0 +1,111 @@
+package via
+
+import (
+	"context"
+	"time"
+)
+
+// Delays between reconnects of a failed change feed.
+const (
+	changeFeedMinBackoff = time.Second
+	changeFeedMaxBackoff = 30 * time.Second
+)
+
+// ChangeFeed is a source of change notifications from outside the app, such as
+// Postgres LISTEN/NOTIFY, a message queue or a database change stream.
+type ChangeFeed interface {
+	// Listen blocks and calls notify for each notification until ctx is done, then
+	// returns ctx.Err(). It returns other errors if the feed fails.
+	Listen(ctx context.Context, notify func(channel, payload string)) error
+}
+
+// ChangeFeedFunc adapts a func to a ChangeFeed.
+//
+// Example, using pgx to listen for Postgres notifications:
+//
+//	feed := via.ChangeFeedFunc(func(ctx context.Context, notify func(channel, payload string)) error {
+//		conn, err := pgx.Connect(ctx, dsn)
+//		if err != nil {
+//			return err
+//		}
+//		defer conn.Close(context.Background())
+//		if _, err := conn.Exec(ctx, "LISTEN orders"); err != nil {
+//			return err
+//		}
+//		for {
+//			n, err := conn.WaitForNotification(ctx)
+//			if err != nil {
+//				return err
+//			}
+//			notify(n.Channel, n.Payload)
+//		}
+//	})
+type ChangeFeedFunc func(ctx context.Context, notify func(channel, payload string)) error
+
+// Listen calls f.
+func (f ChangeFeedFunc) Listen(ctx context.Context, notify func(channel, payload string)) error {
+	return f(ctx, notify)
+}
+
+type changeFeedSub struct {
+	feed   ChangeFeed
+	handle func(channel, payload string) LiveUpdate
+}
+
+// Subscribe listens to the change feed while the app is served and applies the update
+// returned by handle for each notification, so changes made by other services appear in
+// open pages without polling. The feed starts with Start or ServeListener, is restarted
+// with a backoff if it fails, and stops on Shutdown.
+//
+// Example:
+//
+//	v.Subscribe(feed, func(channel, payload string) via.LiveUpdate {
+//		return via.LiveUpdate{Routes: []string{"/orders"}}
+//	})
+func (v *V) Subscribe(feed ChangeFeed, handle func(channel, payload string) LiveUpdate) {
+	sub := &changeFeedSub{feed: feed, handle: handle}
+	v.serverMu.Lock()
+	defer v.serverMu.Unlock()
+	v.changeFeeds = append(v.changeFeeds, sub)
+	if v.schedulerStarted {
+		go v.runChangeFeed(sub)
+	}
+}
+
+func (v *V) runChangeFeed(sub *changeFeedSub) {
+	// canceled by Shutdown
+	ctx := v.baseCtx
+	backoff := changeFeedMinBackoff
+	for {
+		start := time.Now()
+		err := sub.feed.Listen(ctx, func(channel, payload string) {
+			v.handleChange(sub, channel, payload)
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		if time.Since(start) > changeFeedMaxBackoff {
+			backoff = changeFeedMinBackoff
+		}
+		v.logErr(nil, "change feed failed, reconnecting in %v: %v", backoff, err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, changeFeedMaxBackoff)
+	}
+}
+
+// handleChange applies the update returned by the handler of the subscription for a
+// notification. Panics of the handler are logged and reported, so the feed keeps
+// listening.
+func (v *V) handleChange(sub *changeFeedSub, channel, payload string) {
+	defer func() {
+		if r := recover(); r != nil {
+			v.logErr(nil, "change feed handler failed on channel '%s': %v", channel, r)
+			v.reportErr(nil, PhaseUpdate, panicErr(r))
+		}
+	}()
+	v.applyLiveUpdate(sub.handle(channel, payload))
+}
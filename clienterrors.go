@@ -0,0 +1,132 @@
+package via
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/go-via/via/h"
+)
+
+// clientErrorsPath is the endpoint that receives errors reported by the browser.
+const clientErrorsPath = "/_via/client-errors"
+
+// maxClientErrorSize is the maximum size of a reported client error.
+const maxClientErrorSize = 16 << 10
+
+// maxClientErrors is the maximum number of errors reported per context. The browser
+// stops reporting at the same limit, so only forged reports exceed it.
+const maxClientErrors = 10
+
+// Maximum lengths of the fields of a ClientError in bytes, see sanitize.
+const (
+	maxClientErrorMessage = 1 << 10
+	maxClientErrorURL     = 2 << 10
+)
+
+// ClientError is an error that occurred in the browser, reported to Options.OnError with
+// PhaseClient.
+type ClientError struct {
+	// The kind of the error: "error" for uncaught errors, including failed Datastar
+	// expressions, "rejection" for unhandled promise rejections and "sse" for an SSE
+	// stream that failed to reconnect.
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+	Stack   string `json:"stack"`
+	// The URL of the page in the browser.
+	URL string `json:"url"`
+}
+
+func (e *ClientError) Error() string {
+	return fmt.Sprintf("client %s: %s", e.Kind, e.Message)
+}
+
+// clientErrorScript returns the head element that reports uncaught errors, unhandled
+// rejections and failed SSE reconnections of the page to clientErrorsPath. A page
+// reports at most maxClientErrors errors.
+func clientErrorScript(c *Context) h.H {
+	return h.Meta(h.Data("init", fmt.Sprintf(`(() => {
+		let n = 0;
+		const send = (kind, message, stack) => n++ < %d && navigator.sendBeacon('%s',
+			JSON.stringify({ctx: '%s', kind, message: String(message), stack: stack || '', url: location.href}));
+		window.addEventListener('error', (evt) => send('error', evt.message, evt.error?.stack));
+		window.addEventListener('unhandledrejection', (evt) => send('rejection', evt.reason?.message ?? evt.reason, evt.reason?.stack));
+		document.addEventListener('datastar-fetch', (evt) => evt.detail.type === 'retries-failed' &&
+			send('sse', 'failed to reconnect to the server'));
+	})()`, maxClientErrors, clientErrorsPath, c.id)))
+}
+
+// serveClientError logs an error reported by the browser of a context and passes it to
+// Options.OnError. Reports beyond maxClientErrors per context are dropped, and the
+// fields are sanitized first, as any client can post them.
+func (v *V) serveClientError(w http.ResponseWriter, r *http.Request) {
+	var report struct {
+		ClientError
+		Ctx string `json:"ctx"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxClientErrorSize)).Decode(&report); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	c, err := v.getCtx(report.Ctx)
+	if err != nil {
+		v.logWarn(nil, "client error of unknown context dropped: %v", err)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if !v.checkClientBinding(c, w, r) {
+		return
+	}
+	if c.clientErrors.Add(1) > maxClientErrors {
+		w.WriteHeader(http.StatusTooManyRequests)
+		return
+	}
+	cErr := report.ClientError
+	cErr.sanitize()
+	v.logErr(c, "%v (url=%s)", &cErr, cErr.URL)
+	v.reportErr(c, PhaseClient, &cErr)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// sanitize restricts the kind to the known kinds, truncates the message, stack and URL,
+// and removes control characters and invalid UTF-8 from them. The URL keeps only its
+// scheme, host and path: the query and fragment may hold tokens.
+func (e *ClientError) sanitize() {
+	switch e.Kind {
+	case "error", "rejection", "sse":
+	default:
+		e.Kind = "error"
+	}
+	if u, err := url.Parse(e.URL); err == nil {
+		e.URL = (&url.URL{Scheme: u.Scheme, Host: u.Host, Path: u.Path}).String()
+	} else {
+		e.URL = ""
+	}
+	e.Message = sanitizeText(e.Message, maxClientErrorMessage, false)
+	e.Stack = sanitizeText(e.Stack, maxClientErrorSize, true)
+	e.URL = sanitizeText(e.URL, maxClientErrorURL, false)
+}
+
+// sanitizeText returns s truncated to limit bytes, with invalid UTF-8 and control
+// characters replaced by U+FFFD. Newlines are kept if multiline.
+func sanitizeText(s string, limit int, multiline bool) string {
+	s = strings.ToValidUTF8(s, "\uFFFD")
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) && !(multiline && r == '\n') {
+			return unicode.ReplacementChar
+		}
+		return r
+	}, s)
+	if len(s) <= limit {
+		return s
+	}
+	s = s[:limit]
+	for !utf8.ValidString(s) {
+		s = s[:len(s)-1]
+	}
+	return s + "…"
+}
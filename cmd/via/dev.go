@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// pollInterval is the interval in which via dev checks the app for changed files.
+const pollInterval = 500 * time.Millisecond
+
+// watchedExts are the extensions of files whose changes restart the app.
+var watchedExts = []string{".go", ".html", ".tmpl", ".css", ".js", ".json", ".sql"}
+
+// skippedDirs are directories that are not watched.
+var skippedDirs = []string{".git", ".via", "bin", "tmp", "vendor", "node_modules"}
+
+// runDev builds and runs the app in DevMode and restarts it when its files change.
+// Via restores the contexts of open pages after a restart in DevMode, so browsers
+// reconnect and show the changes.
+func runDev(args []string) error {
+	fset := flag.NewFlagSet("dev", flag.ExitOnError)
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), "usage: via dev [dir]")
+	}
+	_ = fset.Parse(args)
+	dir := "."
+	if fset.NArg() > 0 {
+		dir = fset.Arg(0)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	tmp, err := os.MkdirTemp("", "via-dev-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+	bin := filepath.Join(tmp, "app")
+
+	state, err := snapshot(dir)
+	if err != nil {
+		return err
+	}
+	for {
+		app := startApp(ctx, dir, bin)
+		for {
+			select {
+			case <-ctx.Done():
+				app.stop()
+				return nil
+			case <-time.After(pollInterval):
+			}
+			next, err := snapshot(dir)
+			if err != nil {
+				return err
+			}
+			if changed := changedFiles(state, next); len(changed) > 0 {
+				state = next
+				fmt.Printf("via dev: %s changed, restarting\n", strings.Join(changed, ", "))
+				app.stop()
+				break
+			}
+		}
+	}
+}
+
+// devApp is a running build of the app.
+type devApp struct {
+	cmd  *exec.Cmd
+	done chan struct{}
+}
+
+// startApp builds the app in dir and starts it with VIA_DEV set. It returns an app that
+// is not running if the build fails.
+func startApp(ctx context.Context, dir, bin string) *devApp {
+	app := &devApp{done: make(chan struct{})}
+	build := exec.CommandContext(ctx, "go", "build", "-o", bin, ".")
+	build.Dir, build.Stdout, build.Stderr = dir, os.Stdout, os.Stderr
+	if err := build.Run(); err != nil {
+		fmt.Println("via dev: build failed, waiting for changes")
+		close(app.done)
+		return app
+	}
+	app.cmd = exec.Command(bin)
+	app.cmd.Dir, app.cmd.Stdout, app.cmd.Stderr = dir, os.Stdout, os.Stderr
+	app.cmd.Env = append(os.Environ(), "VIA_DEV=1")
+	if err := app.cmd.Start(); err != nil {
+		fmt.Printf("via dev: %v\n", err)
+		close(app.done)
+		return app
+	}
+	go func() {
+		_ = app.cmd.Wait()
+		close(app.done)
+	}()
+	return app
+}
+
+// stop interrupts the app and kills it if it does not exit within 3 seconds.
+func (a *devApp) stop() {
+	if a.cmd == nil || a.cmd.Process == nil {
+		return
+	}
+	if err := a.cmd.Process.Signal(os.Interrupt); err != nil {
+		_ = a.cmd.Process.Kill()
+	}
+	select {
+	case <-a.done:
+	case <-time.After(3 * time.Second):
+		_ = a.cmd.Process.Kill()
+		<-a.done
+	}
+}
+
+// snapshot returns the modification times of the watched files in dir.
+func snapshot(dir string) (map[string]time.Time, error) {
+	files := make(map[string]time.Time)
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != dir && slices.Contains(skippedDirs, d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !slices.Contains(watchedExts, filepath.Ext(p)) || strings.HasSuffix(p, "_test.go") {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		files[p] = info.ModTime()
+		return nil
+	})
+	return files, err
+}
+
+// changedFiles returns the files that were added, changed or removed between the
+// snapshots, sorted.
+func changedFiles(prev, next map[string]time.Time) []string {
+	var changed []string
+	for p, t := range next {
+		if pt, ok := prev[p]; !ok || !pt.Equal(t) {
+			changed = append(changed, p)
+		}
+	}
+	for p := range prev {
+		if _, ok := next[p]; !ok {
+			changed = append(changed, p)
+		}
+	}
+	slices.Sort(changed)
+	return changed
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// genFile is the file written by via gen.
+const genFile = "via_gen.go"
+
+// Directives that annotate page and component funcs for via gen.
+const (
+	pageDirective      = "//via:page "
+	componentDirective = "//via:component"
+)
+
+// genPage is a page func annotated with //via:page <route>.
+type genPage struct {
+	fn     string
+	name   string
+	route  string
+	params []string
+}
+
+// genComponent is a component func annotated with //via:component, with the signature
+// func(c *via.Context, props P).
+type genComponent struct {
+	fn    string
+	name  string
+	props string
+}
+
+// runGen generates typed helpers for the annotated pages and components of a package.
+func runGen(args []string) error {
+	fset := flag.NewFlagSet("gen", flag.ExitOnError)
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), "usage: via gen [dir]")
+	}
+	_ = fset.Parse(args)
+	dir := "."
+	if fset.NArg() > 0 {
+		dir = fset.Arg(0)
+	}
+	src, err := generate(dir)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, genFile), src, 0o644)
+}
+
+// generate returns the source of the helpers for the annotated funcs in dir:
+//
+//   - for pages, a route constant, a link builder taking the path params and
+//     RegisterPages, which registers all pages
+//   - for components, a constructor taking the props
+func generate(dir string) ([]byte, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	var pkg string
+	var pages []genPage
+	var comps []genComponent
+	for _, name := range files {
+		if strings.HasSuffix(name, "_test.go") || filepath.Base(name) == genFile {
+			continue
+		}
+		f, err := parser.ParseFile(fset, name, nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		pkg = f.Name.Name
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv != nil || fn.Doc == nil {
+				continue
+			}
+			for _, c := range fn.Doc.List {
+				pos := fset.Position(c.Pos())
+				switch {
+				case strings.HasPrefix(c.Text, pageDirective):
+					route := strings.TrimSpace(strings.TrimPrefix(c.Text, pageDirective))
+					if !strings.HasPrefix(route, "/") {
+						return nil, fmt.Errorf("%s: invalid route %q", pos, route)
+					}
+					pages = append(pages, genPage{fn: fn.Name.Name, name: genName(fn.Name.Name, "Page"),
+						route: route, params: pathParams(route)})
+				case c.Text == componentDirective:
+					params := fn.Type.Params.List
+					if len(params) != 2 || len(params[1].Names) > 1 || len(params[0].Names) > 1 {
+						return nil, fmt.Errorf("%s: component %s must have the signature func(c *via.Context, props P)", pos, fn.Name.Name)
+					}
+					var props bytes.Buffer
+					_ = format.Node(&props, fset, params[1].Type)
+					comps = append(comps, genComponent{fn: fn.Name.Name, name: genName(fn.Name.Name, "Component"),
+						props: props.String()})
+				}
+			}
+		}
+	}
+	if len(pages) == 0 && len(comps) == 0 {
+		return nil, fmt.Errorf("no funcs annotated with %s or %s in %s", strings.TrimSpace(pageDirective), componentDirective, dir)
+	}
+	sort.Slice(pages, func(i, j int) bool { return pages[i].name < pages[j].name })
+	sort.Slice(comps, func(i, j int) bool { return comps[i].name < comps[j].name })
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by via gen. DO NOT EDIT.\n\npackage %s\n\n", pkg)
+	b.WriteString("import (\n")
+	if hasParams(pages) {
+		b.WriteString("\t\"net/url\"\n")
+		if hasWildcards(pages) {
+			b.WriteString("\t\"strings\"\n")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\t\"github.com/go-via/via\"\n")
+	if len(comps) > 0 {
+		b.WriteString("\t\"github.com/go-via/via/h\"\n")
+	}
+	b.WriteString(")\n")
+	if len(pages) > 0 {
+		b.WriteString("\n// Routes of the pages.\nconst (\n")
+		for _, p := range pages {
+			fmt.Fprintf(&b, "\t%sRoute = %q\n", p.name, p.route)
+		}
+		b.WriteString(")\n")
+		for _, p := range pages {
+			writeLinkBuilder(&b, p)
+		}
+		if hasWildcards(pages) {
+			b.WriteString(escapeWildcardFunc)
+		}
+		b.WriteString("\n// RegisterPages registers the annotated pages.\nfunc RegisterPages(v *via.V) {\n")
+		for _, p := range pages {
+			fmt.Fprintf(&b, "\tv.Page(%sRoute, %s)\n", p.name, p.fn)
+		}
+		b.WriteString("}\n")
+	}
+	for _, c := range comps {
+		fmt.Fprintf(&b, "\n// New%[1]s registers the %[2]s component with the given props in c and returns its view.\n", c.name, c.fn)
+		fmt.Fprintf(&b, "func New%s(c *via.Context, props %s) func() h.H {\n", c.name, c.props)
+		fmt.Fprintf(&b, "\treturn c.Component(func(c *via.Context) { %s(c, props) })\n}\n", c.fn)
+	}
+	return format.Source(b.Bytes())
+}
+
+// writeLinkBuilder writes the func that builds links to the page, with a string
+// argument per path param.
+func writeLinkBuilder(b *bytes.Buffer, p genPage) {
+	args := make([]string, len(p.params))
+	for i, param := range p.params {
+		args[i] = genIdent(strings.TrimSuffix(param, "..."))
+	}
+	if len(args) > 0 {
+		fmt.Fprintf(b, "\n// %[1]sURL returns the path of the %[2]s page with the given path params.\nfunc %[1]sURL(%[3]s string) string {\n",
+			p.name, p.fn, strings.Join(args, ", "))
+	} else {
+		fmt.Fprintf(b, "\n// %[1]sURL returns the path of the %[2]s page.\nfunc %[1]sURL() string {\n", p.name, p.fn)
+	}
+	parts := []string{}
+	rest := p.route
+	for i, param := range p.params {
+		before, after, _ := strings.Cut(rest, "{"+param+"}")
+		if before != "" {
+			parts = append(parts, fmt.Sprintf("%q", before))
+		}
+		if strings.HasSuffix(param, "...") { // wildcards match several segments
+			parts = append(parts, fmt.Sprintf("escapeWildcard(%s)", args[i]))
+		} else {
+			parts = append(parts, fmt.Sprintf("url.PathEscape(%s)", args[i]))
+		}
+		rest = after
+	}
+	rest = strings.ReplaceAll(rest, "{$}", "")
+	if rest != "" || len(parts) == 0 {
+		parts = append(parts, fmt.Sprintf("%q", rest))
+	}
+	fmt.Fprintf(b, "\treturn %s\n}\n", strings.Join(parts, " + "))
+}
+
+var pathParamRe = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*(?:\.\.\.)?)\}`)
+
+// pathParams returns the names of the path params of a route, e.g. "id" and "path..."
+// for "/files/{id}/{path...}".
+func pathParams(route string) []string {
+	var params []string
+	for _, m := range pathParamRe.FindAllStringSubmatch(route, -1) {
+		params = append(params, m[1])
+	}
+	return params
+}
+
+// escapeWildcardFunc is the generated func that escapes the values of wildcards, like
+// via.V.URL does.
+const escapeWildcardFunc = `
+// escapeWildcard escapes each segment of the value of a wildcard path param. Leading
+// slashes are dropped, so the path does not turn into a link to another host.
+func escapeWildcard(val string) string {
+	segments := strings.Split(strings.TrimLeft(val, "/"), "/")
+	for i, seg := range segments {
+		segments[i] = url.PathEscape(seg)
+	}
+	return strings.Join(segments, "/")
+}
+`
+
+func hasWildcards(pages []genPage) bool {
+	for _, p := range pages {
+		for _, param := range p.params {
+			if strings.HasSuffix(param, "...") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func hasParams(pages []genPage) bool {
+	for _, p := range pages {
+		if len(p.params) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// genName returns the exported name of the helpers of a func, without the given suffix,
+// e.g. "User" for userPage.
+func genName(fn, suffix string) string {
+	if name := strings.TrimSuffix(fn, suffix); name != "" {
+		fn = name
+	}
+	r := []rune(fn)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+// genIdent returns a Go identifier for a path param that does not clash with keywords.
+func genIdent(param string) string {
+	if token.IsKeyword(param) || param == "url" {
+		return param + "Param"
+	}
+	return param
+}
@@ -0,0 +1,63 @@
+// Command via creates, runs and inspects Via apps.
+//
+// Usage:
+//
+//	via new [-tailwind] [-auth] <module>   create an app in a new directory
+//	via dev [dir]                         run the app, restarting it on file changes
+//	via routes [dir]                      list the pages and handlers of the app
+//	via gen [dir]                         generate typed helpers for annotated pages and components
+//
+// via gen reads funcs annotated with //via:page <route> or //via:component and writes
+// via_gen.go with route constants, link builders and component constructors:
+//
+//	//via:page /users/{id}
+//	func userPage(c *via.Context) { ... }
+//
+//	//via:component
+//	func avatar(c *via.Context, props AvatarProps) { ... }
+//
+// generates UserRoute, UserURL(id string), RegisterPages(v) and
+// NewAvatar(c *via.Context, props AvatarProps) func() h.H.
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+const usage = `via creates, runs and inspects Via apps.
+
+Usage:
+
+	via new [-tailwind] [-auth] <module>   create an app in a new directory
+	via dev [dir]                         run the app, restarting it on file changes
+	via routes [dir]                      list the pages and handlers of the app
+	via gen [dir]                         generate typed helpers for annotated pages and components
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	var err error
+	switch cmd, args := os.Args[1], os.Args[2:]; cmd {
+	case "new":
+		err = runNew(args)
+	case "dev":
+		err = runDev(args)
+	case "routes":
+		err = runRoutes(args, os.Stdout)
+	case "gen":
+		err = runGen(args)
+	case "help", "-h", "-help", "--help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "via: unknown command %q\n\n%s", cmd, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "via: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScaffold(t *testing.T) {
+	testcases := []struct {
+		desc    string
+		project project
+		files   []string
+		absent  []string
+	}{
+		{"plain", project{Module: "example.com/shop", Name: "shop"},
+			[]string{"go.mod", "main.go", "pages.go", "Makefile", ".gitignore", "README.md"},
+			[]string{"auth.go", "tools/tailwind/main.go"}},
+		{"tailwind and auth", project{Module: "shop", Name: "shop", Tailwind: true, Auth: true},
+			[]string{"auth.go", "tools/tailwind/main.go"}, nil},
+	}
+	for _, testcase := range testcases {
+		t.Run(testcase.desc, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "shop")
+			assert.NoError(t, scaffold(dir, testcase.project))
+			for _, f := range testcase.files {
+				assert.FileExists(t, filepath.Join(dir, f))
+			}
+			for _, f := range testcase.absent {
+				assert.NoFileExists(t, filepath.Join(dir, f))
+			}
+			mod, _ := os.ReadFile(filepath.Join(dir, "go.mod"))
+			assert.True(t, strings.HasPrefix(string(mod), "module "+testcase.project.Module+"\n"))
+
+			// the routes of the scaffolded app
+			routes, err := findRoutes(dir)
+			assert.NoError(t, err)
+			assert.NotEmpty(t, routes)
+			assert.Equal(t, "/", routes[0].pattern)
+
+			assert.Error(t, scaffold(dir, testcase.project), "existing directories are not overwritten")
+
+			// the scaffolded app compiles against this checkout of via
+			if testing.Short() {
+				return
+			}
+			root, err := filepath.Abs(filepath.Join("..", ".."))
+			assert.NoError(t, err)
+			work := filepath.Join(dir, "go.work")
+			for _, args := range [][]string{{"work", "init", ".", root}, {"vet", "./..."}} {
+				cmd := exec.Command("go", args...)
+				cmd.Dir = dir
+				cmd.Env = append(os.Environ(), "GOWORK="+work, "GOFLAGS=")
+				out, err := cmd.CombinedOutput()
+				assert.NoError(t, err, "go %s: %s", strings.Join(args, " "), out)
+			}
+		})
+	}
+}
+
+func TestFindRoutes(t *testing.T) {
+	dir := t.TempDir()
+	src := `package main
+
+func routes(v *via.V) {
+	v.Page("/", home)
+	v.HandleFunc("GET /health", health)
+	v.PageForm("/signup", signup, submit)
+	admin := v.Group("/admin/", auth)
+	admin.Page("/", dashboard)
+	users := admin.Group("/users", nil)
+	users.Page("/{id}", user)
+	site := v.Host("example.com")
+	site.Page("/about", about)
+	v.Page(dynamic, other)
+}
+`
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "routes.go"), []byte(src), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "routes_test.go"), []byte(`package main; func f() { v.Page("/test", nil) }`), 0o644))
+
+	routes, err := findRoutes(dir)
+	assert.NoError(t, err)
+	var got []string
+	for _, r := range routes {
+		got = append(got, r.kind+" "+r.pattern)
+	}
+	assert.Equal(t, []string{"page /", "page /admin", "page /admin/users/{id}", "form /signup", "handler GET /health", "page example.com/about"}, got)
+}
+
+func TestChangedFiles(t *testing.T) {
+	now := time.Now()
+	prev := map[string]time.Time{"a.go": now, "b.go": now, "c.go": now}
+	next := map[string]time.Time{"a.go": now, "b.go": now.Add(time.Second), "d.go": now}
+	assert.Equal(t, []string{"b.go", "c.go", "d.go"}, changedFiles(prev, next))
+	assert.Empty(t, changedFiles(prev, prev))
+}
+
+func TestGenerate(t *testing.T) {
+	dir := t.TempDir()
+	src := `package app
+
+//via:page /users/{id}
+func userPage(c *via.Context) {}
+
+//via:page /files/{type}/{path...}
+func filesPage(c *via.Context) {}
+
+//via:component
+func avatar(c *via.Context, props AvatarProps) {}
+`
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "app.go"), []byte(src), 0o644))
+	out, err := generate(dir)
+	assert.NoError(t, err)
+	gen := string(out)
+	assert.Contains(t, gen, "package app\n")
+	assert.Contains(t, gen, `UserRoute  = "/users/{id}"`)
+	assert.Contains(t, gen, "func UserURL(id string) string {\n\treturn \"/users/\" + url.PathEscape(id)\n}")
+	assert.Contains(t, gen, "func FilesURL(typeParam, path string) string {\n\treturn \"/files/\" + url.PathEscape(typeParam) + \"/\" + escapeWildcard(path)\n}")
+	assert.Contains(t, gen, "func escapeWildcard(val string) string {")
+	assert.Contains(t, gen, "\t\"net/url\"\n\t\"strings\"\n")
+	assert.Contains(t, gen, "\tv.Page(UserRoute, userPage)\n")
+	assert.Contains(t, gen, "func NewAvatar(c *via.Context, props AvatarProps) func() h.H {\n\treturn c.Component(func(c *via.Context) { avatar(c, props) })\n}")
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "bad.go"), []byte("package app\n\n//via:component\nfunc bad(c *via.Context) {}\n"), 0o644))
+	_, err = generate(dir)
+	assert.ErrorContains(t, err, "component bad must have the signature")
+}
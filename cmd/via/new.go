@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+//go:embed templates
+var templates embed.FS
+
+// project holds the values the templates of a new app are rendered with.
+type project struct {
+	Module   string
+	Name     string
+	Tailwind bool
+	Auth     bool
+}
+
+// scaffoldFile is a file of a new app rendered from a template.
+type scaffoldFile struct {
+	template string
+	path     string
+	when     func(p project) bool
+}
+
+var scaffoldFiles = []scaffoldFile{
+	{template: "go.mod.tmpl", path: "go.mod"},
+	{template: "main.go.tmpl", path: "main.go"},
+	{template: "pages.go.tmpl", path: "pages.go"},
+	{template: "auth.go.tmpl", path: "auth.go", when: func(p project) bool { return p.Auth }},
+	{template: "tailwind.go.tmpl", path: filepath.Join("tools", "tailwind", "main.go"), when: func(p project) bool { return p.Tailwind }},
+	{template: "Makefile.tmpl", path: "Makefile"},
+	{template: "gitignore.tmpl", path: ".gitignore"},
+	{template: "README.md.tmpl", path: "README.md"},
+}
+
+// runNew creates an app in a new directory named after the last element of the module
+// path.
+func runNew(args []string) error {
+	fset := flag.NewFlagSet("new", flag.ExitOnError)
+	tailwind := fset.Bool("tailwind", false, "style the app with Tailwind CSS")
+	auth := fset.Bool("auth", false, "add a login and pages that require a session")
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), "usage: via new [-tailwind] [-auth] <module>")
+		fset.PrintDefaults()
+	}
+	_ = fset.Parse(args)
+	if fset.NArg() != 1 {
+		fset.Usage()
+		os.Exit(2)
+	}
+	module := fset.Arg(0)
+	p := project{Module: module, Name: path.Base(module), Tailwind: *tailwind, Auth: *auth}
+	if err := scaffold(p.Name, p); err != nil {
+		return err
+	}
+	fmt.Printf("Created %s. Run the app with:\n\n\tcd %s\n\tgo mod tidy\n\tvia dev\n", p.Name, p.Name)
+	return nil
+}
+
+// scaffold writes the files of a new app to dir, which must not exist.
+func scaffold(dir string, p project) error {
+	if strings.ContainsAny(p.Name, `/\ "`) || p.Name == "." || p.Name == ".." {
+		return fmt.Errorf("invalid app name %q", p.Name)
+	}
+	if _, err := os.Stat(dir); err == nil {
+		return fmt.Errorf("%s already exists", dir)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	tmpl, err := template.ParseFS(templates, "templates/*.tmpl")
+	if err != nil {
+		return err
+	}
+	for _, f := range scaffoldFiles {
+		if f.when != nil && !f.when(p) {
+			continue
+		}
+		var b bytes.Buffer
+		if err := tmpl.ExecuteTemplate(&b, f.template, p); err != nil {
+			return fmt.Errorf("render %s: %w", f.path, err)
+		}
+		content := b.Bytes()
+		if strings.HasSuffix(f.path, ".go") {
+			if content, err = format.Source(content); err != nil {
+				return fmt.Errorf("format %s: %w", f.path, err)
+			}
+		}
+		dst := filepath.Join(dir, f.path)
+		if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(dst, content, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// route is a page or handler registration found in the source of an app.
+type route struct {
+	kind    string
+	pattern string
+	pos     token.Position
+}
+
+// routeKinds maps the methods of via.V and via.Group that register routes to the kind
+// of the route.
+var routeKinds = map[string]string{
+	"Page":       "page",
+	"PageForm":   "form",
+	"HandleFunc": "handler",
+	"API":        "api",
+	"Feed":       "feed",
+	"Webhook":    "webhook",
+}
+
+// runRoutes lists the routes registered in the Go files of the app with string literals.
+func runRoutes(args []string, w io.Writer) error {
+	fset := flag.NewFlagSet("routes", flag.ExitOnError)
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), "usage: via routes [dir]")
+	}
+	_ = fset.Parse(args)
+	dir := "."
+	if fset.NArg() > 0 {
+		dir = fset.Arg(0)
+	}
+	routes, err := findRoutes(dir)
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, r := range routes {
+		fmt.Fprintf(tw, "%s\t%s\t%s:%d\n", r.kind, r.pattern, r.pos.Filename, r.pos.Line)
+	}
+	return tw.Flush()
+}
+
+// findRoutes parses the non-test Go files of dir and returns the routes they register,
+// sorted by pattern. Prefixes of groups and hosts assigned to variables are resolved
+// within each file.
+func findRoutes(dir string) ([]route, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	var routes []route
+	for _, name := range files {
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		src, err := os.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		f, err := parser.ParseFile(fset, name, src, 0)
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, fileRoutes(fset, f)...)
+	}
+	sort.SliceStable(routes, func(i, j int) bool { return routes[i].pattern < routes[j].pattern })
+	return routes, nil
+}
+
+// group is a via.Group assigned to a variable.
+type group struct {
+	host, prefix string
+}
+
+func fileRoutes(fset *token.FileSet, f *ast.File) []route {
+	groups := make(map[string]group)
+	var routes []route
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			// admin := v.Group("/admin", auth) or site := v.Host("example.com")
+			if len(n.Lhs) != 1 || len(n.Rhs) != 1 {
+				return true
+			}
+			id, ok := n.Lhs[0].(*ast.Ident)
+			call, isCall := n.Rhs[0].(*ast.CallExpr)
+			if !ok || !isCall {
+				return true
+			}
+			method, recv, arg, ok := routeCall(call)
+			if !ok {
+				return true
+			}
+			parent := groups[recv]
+			switch method {
+			case "Group":
+				groups[id.Name] = group{host: parent.host, prefix: parent.prefix + strings.TrimSuffix(arg, "/")}
+			case "Host":
+				groups[id.Name] = group{host: arg}
+			}
+		case *ast.CallExpr:
+			method, recv, arg, ok := routeCall(n)
+			kind, isRoute := routeKinds[method]
+			if !ok || !isRoute {
+				return true
+			}
+			g := groups[recv]
+			pattern := arg
+			if method == "Page" || method == "PageForm" {
+				pattern = g.host + pageRoute(g.prefix, arg)
+			}
+			routes = append(routes, route{kind: kind, pattern: pattern, pos: fset.Position(n.Pos())})
+		}
+		return true
+	})
+	return routes
+}
+
+// routeCall returns the method, receiver variable and string literal first argument of
+// a method call like v.Page("/", home).
+func routeCall(call *ast.CallExpr) (method, recv, arg string, ok bool) {
+	sel, isSel := call.Fun.(*ast.SelectorExpr)
+	if !isSel || len(call.Args) == 0 {
+		return "", "", "", false
+	}
+	lit, isLit := call.Args[0].(*ast.BasicLit)
+	if !isLit || lit.Kind != token.STRING {
+		return "", "", "", false
+	}
+	arg, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return "", "", "", false
+	}
+	if id, isIdent := sel.X.(*ast.Ident); isIdent {
+		recv = id.Name
+	}
+	return sel.Sel.Name, recv, arg, true
+}
+
+// pageRoute returns the route of a page in a group with the given prefix, like
+// via.Group.Page.
+func pageRoute(prefix, route string) string {
+	if route == "/" || route == "" {
+		if prefix == "" {
+			return "/"
+		}
+		return prefix
+	}
+	return prefix + route
+}
@@ -0,0 +1,246 @@
+package via
+
+import (
+	"maps"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// collectionSignal is a signal holding a collection whose changes are sent to the
+// browser item by item instead of resending the whole collection.
+type collectionSignal interface {
+	// takePatch returns the changes since the last call as a JSON merge patch, where
+	// removed items are nil, and whether there are any changes.
+	takePatch() (map[string]any, bool)
+	// inject replaces the collection with the value sent by the browser.
+	inject(val any)
+	// snapshot returns the whole collection as it is sent to the browser.
+	snapshot() map[string]any
+}
+
+// ListSignal is a reactive list of values. In the browser the list is an object
+// keyed by index, e.g. $list[0], so that Append, SetIndex and Remove only send the
+// affected items with the next Sync or SyncSignals.
+type ListSignal struct {
+	id      string
+	mu      sync.Mutex
+	items   []any
+	dirty   map[int]bool
+	sentLen int
+}
+
+// ListSignal creates a reactive list signal initialized with the given items.
+//
+// Example:
+//
+//	messages := c.ListSignal()
+//	send := c.Action(func() {
+//		messages.Append(msg.String())
+//		c.SyncSignals()
+//	})
+func (c *Context) ListSignal(items ...any) *ListSignal {
+	s := &ListSignal{id: c.app.genID(), dirty: make(map[int]bool)}
+	s.Append(items...)
+	c.storeSignal(s.id, s)
+	return s
+}
+
+// ID returns the signal ID
+func (s *ListSignal) ID() string {
+	return s.id
+}
+
+// Len returns the number of items in the list.
+func (s *ListSignal) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.items)
+}
+
+// Items returns a copy of the items in the list.
+func (s *ListSignal) Items() []any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.items)
+}
+
+// Get returns the item at index i or nil if i is out of range.
+func (s *ListSignal) Get(i int) any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i < 0 || i >= len(s.items) {
+		return nil
+	}
+	return s.items[i]
+}
+
+// Append adds items to the end of the list.
+func (s *ListSignal) Append(items ...any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, item := range items {
+		s.dirty[len(s.items)] = true
+		s.items = append(s.items, item)
+	}
+}
+
+// SetIndex replaces the item at index i. Out of range indices are ignored.
+func (s *ListSignal) SetIndex(i int, item any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i < 0 || i >= len(s.items) {
+		return
+	}
+	s.items[i] = item
+	s.dirty[i] = true
+}
+
+// Remove deletes the item at index i, shifting the following items down.
+// Out of range indices are ignored.
+func (s *ListSignal) Remove(i int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i < 0 || i >= len(s.items) {
+		return
+	}
+	s.items = slices.Delete(s.items, i, i+1)
+	for j := i; j < len(s.items); j++ {
+		s.dirty[j] = true
+	}
+}
+
+func (s *ListSignal) snapshot() map[string]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := make(map[string]any, len(s.items))
+	for i, item := range s.items {
+		p[strconv.Itoa(i)] = item
+	}
+	return p
+}
+
+func (s *ListSignal) takePatch() (map[string]any, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := make(map[string]any)
+	for i := range s.dirty {
+		if i < len(s.items) {
+			p[strconv.Itoa(i)] = s.items[i]
+		}
+	}
+	for i := len(s.items); i < s.sentLen; i++ {
+		p[strconv.Itoa(i)] = nil
+	}
+	s.sentLen = len(s.items)
+	clear(s.dirty)
+	return p, len(p) != 0
+}
+
+func (s *ListSignal) inject(val any) {
+	m, ok := val.(map[string]any)
+	if !ok {
+		return
+	}
+	idxs := make([]int, 0, len(m))
+	for k := range m {
+		if i, err := strconv.Atoi(k); err == nil {
+			idxs = append(idxs, i)
+		}
+	}
+	sort.Ints(idxs)
+	items := make([]any, 0, len(idxs))
+	for _, i := range idxs {
+		items = append(items, m[strconv.Itoa(i)])
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items = items
+	s.sentLen = len(items)
+	clear(s.dirty)
+}
+
+// MapSignal is a reactive map of values. In the browser the map is an object, e.g.
+// $scores.alice, and Set and Delete only send the affected keys with the next Sync
+// or SyncSignals.
+type MapSignal struct {
+	id    string
+	mu    sync.Mutex
+	items map[string]any
+	dirty map[string]bool
+}
+
+// MapSignal creates a reactive map signal initialized with a copy of the given items.
+func (c *Context) MapSignal(items map[string]any) *MapSignal {
+	s := &MapSignal{id: c.app.genID(), items: make(map[string]any), dirty: make(map[string]bool)}
+	for k, v := range items {
+		s.Set(k, v)
+	}
+	c.storeSignal(s.id, s)
+	return s
+}
+
+// ID returns the signal ID
+func (s *MapSignal) ID() string {
+	return s.id
+}
+
+// Get returns the value for key or nil if the key is not set.
+func (s *MapSignal) Get(key string) any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.items[key]
+}
+
+// Keys returns the sorted keys of the map.
+func (s *MapSignal) Keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Sorted(maps.Keys(s.items))
+}
+
+// Set sets the value for key.
+func (s *MapSignal) Set(key string, val any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items[key] = val
+	s.dirty[key] = true
+}
+
+// Delete removes key from the map.
+func (s *MapSignal) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.items, key)
+	s.dirty[key] = true
+}
+
+func (s *MapSignal) snapshot() map[string]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return maps.Clone(s.items)
+}
+
+func (s *MapSignal) takePatch() (map[string]any, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := make(map[string]any, len(s.dirty))
+	for k := range s.dirty {
+		p[k] = s.items[k] // nil for deleted keys
+	}
+	clear(s.dirty)
+	return p, len(p) != 0
+}
+
+func (s *MapSignal) inject(val any) {
+	m, ok := val.(map[string]any)
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items = maps.Clone(m)
+	clear(s.dirty)
+}
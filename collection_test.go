@@ -0,0 +1,46 @@
+package via
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListSignalPatches(t *testing.T) {
+	s := &ListSignal{id: "list", dirty: make(map[int]bool)}
+	s.Append("a", "b", "c")
+	p, ok := s.takePatch()
+	assert.True(t, ok)
+	assert.Equal(t, map[string]any{"0": "a", "1": "b", "2": "c"}, p)
+
+	_, ok = s.takePatch()
+	assert.False(t, ok)
+
+	s.SetIndex(1, "B")
+	p, _ = s.takePatch()
+	assert.Equal(t, map[string]any{"1": "B"}, p)
+
+	s.Remove(0)
+	p, _ = s.takePatch()
+	assert.Equal(t, map[string]any{"0": "B", "1": "c", "2": nil}, p)
+	assert.Equal(t, []any{"B", "c"}, s.Items())
+}
+
+func TestListSignalInject(t *testing.T) {
+	s := &ListSignal{id: "list", dirty: make(map[int]bool)}
+	s.inject(map[string]any{"1": "b", "0": "a", "10": "k"})
+	assert.Equal(t, []any{"a", "b", "k"}, s.Items())
+}
+
+func TestMapSignalPatches(t *testing.T) {
+	s := &MapSignal{id: "map", items: make(map[string]any), dirty: make(map[string]bool)}
+	s.Set("alice", 1)
+	s.Set("bob", 2)
+	p, _ := s.takePatch()
+	assert.Equal(t, map[string]any{"alice": 1, "bob": 2}, p)
+
+	s.Delete("alice")
+	p, _ = s.takePatch()
+	assert.Equal(t, map[string]any{"alice": nil}, p)
+	assert.Equal(t, []string{"bob"}, s.Keys())
+}
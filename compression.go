@@ -0,0 +1,100 @@
+package via
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/starfederation/datastar-go/datastar"
+)
+
+// Compression selects how page responses and the SSE stream are compressed.
+type Compression int
+
+const (
+	compressionUndefined Compression = iota
+	// CompressionAuto uses Brotli or gzip, preferring Brotli if the client accepts both.
+	CompressionAuto
+	// CompressionBrotli uses Brotli only.
+	CompressionBrotli
+	// CompressionGzip uses gzip only.
+	CompressionGzip
+	// CompressionOff disables compression.
+	CompressionOff
+)
+
+// brotliLevel trades compression ratio for speed on large view patches.
+const brotliLevel = 5
+
+// sseOptions returns the datastar options that apply the configured compression to an
+// SSE stream.
+func (v *V) sseOptions() []datastar.SSEOption {
+	switch v.cfg.Compression {
+	case CompressionOff:
+		return nil
+	case CompressionBrotli:
+		return []datastar.SSEOption{datastar.WithCompression(datastar.WithBrotli(datastar.WithBrotliLevel(brotliLevel)))}
+	case CompressionGzip:
+		return []datastar.SSEOption{datastar.WithCompression(datastar.WithGzip())}
+	default:
+		return []datastar.SSEOption{datastar.WithCompression(
+			datastar.WithServerPriority(),
+			datastar.WithBrotli(datastar.WithBrotliLevel(brotliLevel)),
+			datastar.WithGzip(),
+		)}
+	}
+}
+
+// compressResponse wraps w with the configured compression if the client accepts it.
+// The returned close func must be called after the body is written.
+func (v *V) compressResponse(w http.ResponseWriter, r *http.Request) (io.Writer, func() error) {
+	w.Header().Add("Vary", "Accept-Encoding")
+	switch v.acceptedEncoding(r) {
+	case "br":
+		w.Header().Set("Content-Encoding", "br")
+		bw := brotli.NewWriterLevel(w, brotliLevel)
+		return bw, bw.Close
+	case "gzip":
+		w.Header().Set("Content-Encoding", "gzip")
+		gw := gzip.NewWriter(w)
+		return gw, gw.Close
+	}
+	return w, func() error { return nil }
+}
+
+// acceptedEncoding returns the configured content encoding the client accepts, "br" or
+// "gzip", or an empty string if the response must not be compressed.
+func (v *V) acceptedEncoding(r *http.Request) string {
+	accepted := r.Header.Get("Accept-Encoding")
+	acceptsBr := acceptsEncoding(accepted, "br")
+	acceptsGzip := acceptsEncoding(accepted, "gzip")
+
+	switch v.cfg.Compression {
+	case CompressionOff:
+		acceptsBr, acceptsGzip = false, false
+	case CompressionBrotli:
+		acceptsGzip = false
+	case CompressionGzip:
+		acceptsBr = false
+	}
+
+	switch {
+	case acceptsBr:
+		return "br"
+	case acceptsGzip:
+		return "gzip"
+	}
+	return ""
+}
+
+func acceptsEncoding(header, encoding string) bool {
+	for part := range strings.SplitSeq(header, ",") {
+		token, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if token == encoding && strings.ReplaceAll(strings.TrimSpace(params), " ", "") != "q=0" {
+			return true
+		}
+	}
+	return false
+}
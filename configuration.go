@@ -1,5 +1,11 @@
 package via
 
+import (
+	"crypto/tls"
+	"net/http"
+	"time"
+)
+
 type LogLevel int
 
 const (
@@ -11,16 +17,109 @@ const (
 )
 
 // Plugin is a func that can mutate the given *via.V app runtime. It is useful to integrate popular JS/CSS UI libraries or tools.
+// Plugins can hook into the lifecycle of pages and actions with V.BeforePage, V.AfterPageRender,
+// V.BeforeAction, V.AfterAction and V.OnSSEConnect.
 type Plugin func(v *V)
 
 // Options defines configuration options for the via application
 type Options struct {
 	// The development mode flag. If true, enables server and browser auto-reload on `.go` file changes.
+	// Enabled by default if the VIA_DEV environment variable is set, as 'via dev' does.
 	DevMode bool
 
+	// Turns DevMode off, e.g. when it was enabled by VIA_DEV or an earlier Config call.
+	NoDevMode bool
+
 	// The http server address. e.g. ':3000'
 	ServerAddress string
 
+	// Additional addresses served by Start alongside ServerAddress, sharing the same
+	// routes and contexts. e.g. plain HTTP on localhost for a reverse proxy and HTTPS
+	// on the LAN for testing on mobile devices.
+	Addresses []Address
+
+	// CIDRs or IP addresses of reverse proxies whose X-Forwarded-For,
+	// X-Forwarded-Proto and X-Forwarded-Host headers are trusted.
+	// e.g. []string{"127.0.0.1", "10.0.0.0/8"}
+	TrustedProxies []string
+
+	// A custom http server used by Start, e.g. to set timeouts, a TLS config or
+	// the enabled protocols. HTTP/2 is served over TLS by default; set
+	// Protocols.SetUnencryptedHTTP2 to serve it without TLS behind a proxy.
+	// Addr defaults to ServerAddress and Handler to the Via router.
+	// Note that WriteTimeout also ends long-lived SSE streams.
+	HTTPServer *http.Server
+
+	// Automatic TLS with certificates from Let's Encrypt, so Via can serve HTTPS
+	// on :443 without a reverse proxy. The domains must resolve to this host.
+	AutoTLS *AutoTLS
+
+	// Binds each context to properties of the client that loaded the page and rejects
+	// SSE, action and upload requests from clients that do not match, mitigating the
+	// use of leaked context IDs. Disabled by default.
+	// Options: BindClientIP, BindUserAgent, or both combined.
+	ClientBinding ClientBinding
+
+	// Resolves the tenant of a request, e.g. from the host or a header, for apps that
+	// serve several tenants. Contexts are bound to the tenant of the page request:
+	// SSE, action and upload requests of another tenant are rejected. See
+	// Context.Tenant and PerTenant. Disabled if nil.
+	TenantResolver func(r *http.Request) string
+
+	// Security headers set on every response. Defaults apply to empty fields.
+	SecurityHeaders SecurityHeaders
+
+	// Overrides of SecurityHeaders for request paths with the given prefixes. The
+	// longest matching prefix applies. e.g. {"/embed/": {FrameOptions: via.OmitHeader}}
+	RouteSecurityHeaders map[string]SecurityHeaders
+
+	// Cross-origin resource sharing of the routes registered with API.
+	// Disabled if nil.
+	CORS *CORS
+
+	// The Content-Security-Policy of pages, e.g. via.NewCSP(). Disabled if nil.
+	CSP *CSP
+
+	// The Datastar client script loaded by pages. Defaults to the embedded bundle.
+	Datastar DatastarBundle
+
+	// Themes of the app, selectable per user with Context.SetTheme. Without a
+	// preference, the theme follows the prefers-color-scheme of the browser.
+	Themes []Theme
+
+	// Tuning of the SSE stream that carries patches to the browser.
+	SSE SSEOptions
+
+	// Size limits of uploads, see Context.Upload.
+	Upload UploadOptions
+
+	// The maximum time an action may run. When it is exceeded, the context returned
+	// by c.Ctx is canceled, the timeout is logged and reported to OnError, and the
+	// _viaTimeout signal is set to the action ID in the browser, e.g. to show a notice
+	// with data-show="$_viaTimeout". The actions of a context run one at a time, so
+	// actions must return soon after c.Ctx is canceled: one that ignores it keeps
+	// running in the background and holds back the next actions of the context until it
+	// returns. Disabled if 0. See also Context.ActionWithTimeout.
+	ActionTimeout time.Duration
+
+	// Called with errors that occur outside of the app's control flow, such as action
+	// panics, render failures and failed SSE writes, e.g. to report them to an error
+	// tracker or to show an error message with c.Sync. phase is one of the Phase
+	// constants. The context is nil for errors outside a context. Errors are logged
+	// regardless.
+	OnError func(c *Context, phase string, err error)
+
+	// Generates the IDs of contexts, actions, signals, components, uploads and jobs,
+	// e.g. via.SequentialIDs() for reproducible IDs in tests. IDs must be unique and
+	// consist of letters and digits. Context IDs authorize requests to the context, so
+	// production generators must be unguessable. Defaults to random IDs.
+	IDGenerator func() string
+
+	// Receives an AuditEntry for each action run, e.g. via.AuditLog(file) or a func that
+	// stores entries in a database, so the actions of users are traceable. Name actions
+	// with Context.ActionNamed and identify users with Context.SetUser. Disabled if nil.
+	Audit func(e AuditEntry)
+
 	// Level of the logs to write to stdout.
 	// Options: Error, Warn, Info, Debug.
 	LogLvl LogLevel
@@ -30,4 +129,43 @@ type Options struct {
 
 	// Plugins to extend the capabilities of the `Via` application.
 	Plugins []Plugin
+
+	// Compression of page responses and the SSE stream.
+	// Options: Auto (default), Brotli, Gzip, Off.
+	Compression Compression
+
+	// Selects requests that are served a static snapshot of the page: the fully rendered
+	// view without a live connection, so no context is kept and no SSE stream is
+	// opened. e.g. via.IsBot for search engine crawlers and link unfurlers.
+	// Disabled if nil.
+	StaticSnapshot func(r *http.Request) bool
+
+	// Formatting of the HTML of pages and element patches.
+	// Options: Auto (default, indented in DevMode), Pretty, Compact.
+	HTMLFormat HTMLFormat
+
+	// If true, element patches are applied inside document.startViewTransition
+	// on browsers that support the View Transitions API, animating the morph
+	// between view states.
+	ViewTransitions bool
+
+	// If true, pages remain usable for clients with JS disabled or without an SSE
+	// stream: until the stream connects, buttons with OnClick post the page's bound
+	// inputs to the server, which runs the action and answers with the re-rendered
+	// page. Set it before registering pages.
+	NoJSFallback bool
+}
+
+// Address is an additional address served by the Via application. HTTPS is served if
+// CertFile and KeyFile are set or TLSConfig holds certificates.
+type Address struct {
+	// The address to listen on. e.g. '192.168.1.10:3443'
+	Addr string
+
+	// Paths of the TLS certificate and matching private key files.
+	CertFile string
+	KeyFile  string
+
+	// An optional TLS config.
+	TLSConfig *tls.Config
 }
@@ -1,16 +1,22 @@
 package via
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
 	"maps"
-	"reflect"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-via/via/h"
+	"github.com/go-via/via/internal/bufpool"
+	"github.com/starfederation/datastar-go/datastar"
 )
 
 // Context is the living bridge between Go and the browser.
@@ -22,13 +28,53 @@ type Context struct {
 	app               *V
 	view              func() h.H
 	routeParams       map[string]string
+	queryParams       map[string]string
+	clientIP          string
+	requestID         atomic.Value
+	baseURL           string
+	requestURI        string
+	fingerprint       string
+	tenant            string
+	nonce             string
+	theme             atomic.Value
+	title             string
+	cookies           []*http.Cookie
+	redirect          string
+	aborted           bool
+	headTags          []h.H
 	componentRegistry map[string]*Context
+	regions           map[string]func() h.H
 	parentPageCtx     *Context
+	eventHandlers     map[string][]eventHandler
+	viewers           []*Context
+	viewing           *Context
+	actionNames       map[string]string
+	user              string
+	actionOrder       []string
+	signalOrder       []string
+	recording         []RecordedAction
 	patchChan         chan patch
 	actionRegistry    map[string]func()
+	actionTimeouts    map[string]time.Duration
+	uploadRegistry    map[string]func([]UploadedFile)
 	signals           *sync.Map
 	mu                sync.RWMutex
 	ctxDisposedChan   chan struct{}
+	stopRoutinesOnce  sync.Once
+	ctxMu             sync.Mutex
+	lifeCtx           context.Context
+	cancelLifeCtx     context.CancelFunc
+	actionMu          sync.Mutex
+	actionRun         *actionRun
+	pendingUpdates    []func()
+	beforeRender      []func()
+	afterRender       []func(html []byte, dur time.Duration) []byte
+	metrics           pageMetrics
+	formID            string
+	formSubmit        func(form url.Values)
+	clientErrors      atomic.Int32
+	gestures          atomic.Bool
+	headSent          atomic.Bool
 }
 
 // View defines the UI rendered by this context.
@@ -42,6 +88,54 @@ func (c *Context) View(f func() h.H) {
 	c.view = func() h.H { return h.Div(h.ID(c.id), f()) }
 }
 
+// BeforeRender registers a func that runs before each render of the view, e.g. to load
+// per-render data such as permissions. Hooks of a component run when the component is
+// synced on its own; renders of the parent view include the component without them.
+func (c *Context) BeforeRender(f func()) {
+	c.beforeRender = append(c.beforeRender, f)
+}
+
+// AfterRender registers a func that runs after each render of the view with the
+// rendered HTML and the time the render took, e.g. to collect metrics, check the output
+// or rewrite it. f returns the HTML to send, html itself to leave it unchanged; the
+// root element, which carries the ID of the context, must be kept. Funcs run in
+// registration order, each with the HTML returned by the previous one. See
+// BeforeRender for components.
+func (c *Context) AfterRender(f func(html []byte, dur time.Duration) []byte) {
+	c.afterRender = append(c.afterRender, f)
+}
+
+// renderView renders the view, running the render hooks.
+func (c *Context) renderView() (string, error) {
+	for _, f := range c.beforeRender {
+		f()
+	}
+	start := time.Now()
+	buf := bufpool.Get()
+	defer bufpool.Put(buf)
+	if err := c.view().Render(buf); err != nil {
+		return "", err
+	}
+	dur := time.Since(start)
+	if c.isComponent() {
+		c.parentPageCtx.metrics.render(dur)
+	} else {
+		c.metrics.render(dur)
+	}
+	html := c.app.formatHTML(buf.String())
+	if c.viewing != nil {
+		html = c.hideOwner(html)
+	}
+	if len(c.afterRender) > 0 {
+		b := []byte(html)
+		for _, f := range c.afterRender {
+			b = f(b, dur)
+		}
+		html = string(b)
+	}
+	return html, nil
+}
+
 // Component registers a subcontext that has self contained data, actions and signals.
 // It returns the component's view as a DOM node fn that can be placed in the view
 // of the parent. Components can be added to components.
@@ -63,7 +157,7 @@ func (c *Context) View(f func() h.H) {
 //		})
 //	})
 func (c *Context) Component(initCtx func(c *Context)) func() h.H {
-	id := c.id + "/_component/" + genRandID()
+	id := c.id + "/_component/" + c.app.genID()
 	compCtx := newContext(id, c.route, c.app)
 	if c.isComponent() {
 		compCtx.parentPageCtx = c.parentPageCtx
@@ -75,10 +169,126 @@ func (c *Context) Component(initCtx func(c *Context)) func() h.H {
 	return compCtx.view
 }
 
+// LazyComponent registers a component like Component, but initializes it only once its
+// placeholder scrolls into view, then patches the rendered component into the page. This
+// keeps heavy components, e.g. ones that query slow services, from delaying the first
+// response of the page. The placeholder children are shown until the component loads.
+//
+// Example:
+//
+//	report := c.LazyComponent(reportComp, h.P(h.Text("Loading report…")))
+func (c *Context) LazyComponent(initCtx func(c *Context), placeholder ...h.H) func() h.H {
+	id := c.id + "/_component/" + c.app.genID()
+	compCtx := newContext(id, c.route, c.app)
+	page := c
+	if c.isComponent() {
+		page = c.parentPageCtx
+	}
+	compCtx.parentPageCtx = page
+	var loaded atomic.Bool
+	var once sync.Once
+	load := c.Action(func() {
+		once.Do(func() {
+			initCtx(compCtx)
+			c.mu.Lock()
+			c.componentRegistry[id] = compCtx
+			c.mu.Unlock()
+			loaded.Store(true)
+			compCtx.Sync()
+		})
+	})
+	return func() h.H {
+		if loaded.Load() {
+			return compCtx.view()
+		}
+		return h.Div(append([]h.H{h.ID(id), h.Attr("aria-busy", "true"), load.OnIntersect(0)}, placeholder...)...)
+	}
+}
+
+// Region registers a part of the view that is rendered inside an element with the given
+// ID. It returns the part as a DOM node fn to place in the view, like Component. SyncID
+// re-renders and patches a region on its own, so a small change, such as a counter in a
+// large table, does not render the whole view. IDs must be unique in the page.
+//
+// Example:
+//
+//	total := c.Region("total", func() h.H { return h.Textf("%d", sum) })
+//	c.View(func() h.H { return h.Div(rows(), total()) })
+//	// in an action
+//	c.SyncID("total")
+func (c *Context) Region(id string, f func() h.H) func() h.H {
+	if f == nil {
+		panic("nil regionfn")
+	}
+	page := c
+	if c.isComponent() {
+		page = c.parentPageCtx
+	}
+	region := func() h.H { return h.Div(h.ID(id), f()) }
+	page.mu.Lock()
+	defer page.mu.Unlock()
+	if page.regions == nil {
+		page.regions = make(map[string]func() h.H)
+	}
+	page.regions[id] = region
+	return region
+}
+
 func (c *Context) isComponent() bool {
 	return c.parentPageCtx != nil
 }
 
+// eventHandler is a handler registered with On by the owner context.
+type eventHandler struct {
+	owner *Context
+	fn    func(payload any)
+}
+
+// On registers a handler for events emitted with Emit by the page or any of its
+// components, so components can communicate with the page and with each other without
+// sharing state.
+//
+// Example:
+//
+//	// in a component
+//	selectRow := c.Action(func() { c.Emit("row-selected", rowID) })
+//
+//	// in the page
+//	c.On("row-selected", func(payload any) {
+//		selected = payload.(string)
+//		c.Sync()
+//	})
+func (c *Context) On(event string, handler func(payload any)) {
+	page := c
+	if c.isComponent() {
+		page = c.parentPageCtx
+	}
+	page.mu.Lock()
+	defer page.mu.Unlock()
+	if page.eventHandlers == nil {
+		page.eventHandlers = make(map[string][]eventHandler)
+	}
+	page.eventHandlers[event] = append(page.eventHandlers[event], eventHandler{owner: c, fn: handler})
+}
+
+// Emit runs the handlers registered with On for the event by the page of this context
+// and its components, except handlers of this context, in the order they were
+// registered.
+func (c *Context) Emit(event string, payload any) {
+	page := c
+	if c.isComponent() {
+		page = c.parentPageCtx
+	}
+	page.mu.RLock()
+	handlers := slices.Clone(page.eventHandlers[event])
+	page.mu.RUnlock()
+	for _, handler := range handlers {
+		if handler.owner != c {
+			handler.fn(payload)
+		}
+	}
+}
+
 // Action registers an event handler and returns a trigger to that event that
 // that can be added to the view fn as any other via.h element.
 //
@@ -97,21 +307,57 @@ func (c *Context) isComponent() bool {
 //		 )
 //	})
 func (c *Context) Action(f func()) *actionTrigger {
-	id := genRandID()
+	id := c.app.genID()
 	if f == nil {
 		c.app.logErr(c, "failed to bind action '%s' to context: nil func", id)
 		return nil
 	}
 
+	page := c
 	if c.isComponent() {
-		c.parentPageCtx.actionRegistry[id] = f
-	} else {
-		c.actionRegistry[id] = f
+		page = c.parentPageCtx
+	}
+	page.mu.Lock()
+	page.actionRegistry[id] = f
+	page.actionOrder = append(page.actionOrder, id)
+	page.mu.Unlock()
+	return &actionTrigger{id: id, noJS: c.app.cfg.NoJSFallback, page: page}
+}
+
+// ActionWithTimeout registers an action like Action that may run for at most the given
+// duration, overriding Options.ActionTimeout. A duration of 0 or less disables the
+// timeout of the action.
+func (c *Context) ActionWithTimeout(d time.Duration, f func()) *actionTrigger {
+	a := c.Action(f)
+	if a == nil {
+		return nil
+	}
+	if d <= 0 {
+		d = -1
+	}
+	page := c
+	if c.isComponent() {
+		page = c.parentPageCtx
+	}
+	page.mu.Lock()
+	page.actionTimeouts[a.id] = d
+	page.mu.Unlock()
+	return a
+}
+
+// getActionTimeout returns the timeout of the action, or 0 or less if it has none.
+func (c *Context) getActionTimeout(id string) time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if d, ok := c.actionTimeouts[id]; ok {
+		return d
 	}
-	return &actionTrigger{id}
+	return c.app.cfg.ActionTimeout
 }
 
 func (c *Context) getActionFn(id string) (func(), error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	if f, ok := c.actionRegistry[id]; ok {
 		return f, nil
 	}
@@ -151,7 +397,7 @@ func (c *Context) OnInterval(duration time.Duration, handler func()) *OnInterval
 // If any signal value is updated by the server, the update is automatically sent to the
 // browser when using Sync() or SyncSignsls().
 func (c *Context) Signal(v any) *signal {
-	sigID := genRandID()
+	sigID := c.app.genID()
 	if v == nil {
 		c.app.logErr(c, "failed to bind signal: nil signal value")
 		return &signal{
@@ -160,29 +406,29 @@ func (c *Context) Signal(v any) *signal {
 			err: fmt.Errorf("context '%s' failed to bind signal '%s': nil signal value", c.id, sigID),
 		}
 	}
-	switch reflect.TypeOf(v).Kind() {
-	case reflect.Slice, reflect.Struct:
-		if j, err := json.Marshal(v); err == nil {
-			v = string(j)
-		}
-	}
 	sig := &signal{
 		id:      sigID,
 		val:     v,
 		changed: true,
+		noJS:    c.app.cfg.NoJSFallback,
 	}
 
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	if c.isComponent() { // components register signals on parent page
-		c.parentPageCtx.signals.Store(sigID, sig)
-	} else {
-		c.signals.Store(sigID, sig)
-	}
+	c.storeSignal(sigID, sig)
 	return sig
 
 }
 
+func (c *Context) storeSignal(sigID string, sig any) {
+	page := c
+	if c.isComponent() { // components register signals on parent page
+		page = c.parentPageCtx
+	}
+	page.mu.Lock()
+	defer page.mu.Unlock()
+	page.signals.Store(sigID, sig)
+	page.signalOrder = append(page.signalOrder, sigID)
+}
+
 func (c *Context) injectSignals(sigs map[string]any) {
 	if sigs == nil {
 		c.app.logErr(c, "signal injection failed: nil signals")
@@ -201,9 +447,12 @@ func (c *Context) injectSignals(sigs map[string]any) {
 			continue
 		}
 		item, _ := c.signals.Load(sigID)
-		if sig, ok := item.(*signal); ok {
-			sig.val = val
+		switch sig := item.(type) {
+		case *signal:
+			sig.val, sig.err = coerceSignalValue(sig.val, val)
 			sig.changed = false
+		case collectionSignal:
+			sig.inject(val)
 		}
 	}
 }
@@ -222,15 +471,27 @@ func (c *Context) getPatchChan() chan patch {
 func (c *Context) prepareSignalsForPatch() map[string]any {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	updatedSigs := make(map[string]any)
+	var updatedSigs map[string]any // allocated on the first change
+	set := func(sigID string, val any) {
+		if updatedSigs == nil {
+			updatedSigs = make(map[string]any)
+		}
+		updatedSigs[sigID] = val
+	}
 	c.signals.Range(func(sigID, value any) bool {
+		if sig, ok := value.(collectionSignal); ok {
+			if p, changed := sig.takePatch(); changed {
+				set(sigID.(string), p)
+			}
+			return true
+		}
 		if sig, ok := value.(*signal); ok {
 			if sig.err != nil {
 				c.app.logWarn(c, "signal '%s' is out of sync: %v", sig.id, sig.err)
 				return true
 			}
 			if sig.changed {
-				updatedSigs[sigID.(string)] = fmt.Sprintf("%v", sig.val)
+				set(sigID.(string), sig.jsonValue())
 			}
 		}
 		return true
@@ -246,23 +507,54 @@ func (c *Context) sendPatch(p patch) {
 	case patchChan <- p:
 	default: // closed or buffer full - drop patch without blocking
 	}
+
+	// viewers of the page, see ViewerURL, receive its patches too
+	page := c
+	if c.isComponent() {
+		page = c.parentPageCtx
+	}
+	page.mu.RLock()
+	viewers := page.viewers
+	page.mu.RUnlock()
+	for _, viewer := range viewers {
+		vp := p
+		vp.content = viewer.hideOwner(p.content)
+		viewer.sendPatch(vp)
+	}
+}
+
+// sendPatchWait queues a patch that must not be dropped like sendPatch does when the
+// buffer is full: it waits for room until the context is disposed.
+func (c *Context) sendPatchWait(p patch) {
+	page := c
+	if c.isComponent() {
+		page = c.parentPageCtx
+	}
+	page.ctxMu.Lock()
+	done := page.lifeContext().Done()
+	page.ctxMu.Unlock()
+	select {
+	case page.patchChan <- p:
+	case <-done:
+	}
 }
 
 // Sync pushes the current view state and signal changes to the browser immediately
 // over the live SSE event stream.
 func (c *Context) Sync() {
-	elemsPatch := bytes.NewBuffer(make([]byte, 0))
-	if err := c.view().Render(elemsPatch); err != nil {
+	html, err := c.renderView()
+	if err != nil {
 		c.app.logErr(c, "sync view failed: %v", err)
+		c.app.reportErr(c, PhaseRender, err)
 		return
 	}
-	c.sendPatch(patch{patchTypeElements, elemsPatch.String()})
+	c.sendPatch(patch{typ: patchTypeElements, content: html})
 
 	updatedSigs := c.prepareSignalsForPatch()
 
 	if len(updatedSigs) != 0 {
 		outgoingSigs, _ := json.Marshal(updatedSigs)
-		c.sendPatch(patch{patchTypeSignals, string(outgoingSigs)})
+		c.sendPatch(patch{typ: patchTypeSignals, content: string(outgoingSigs), signals: len(updatedSigs)})
 	}
 }
 
@@ -283,19 +575,104 @@ func (c *Context) Sync() {
 //
 // Then, the merge will only occur if the ID of one of the top level elements in the patch
 // matches 'my-element'.
+//
+// A PatchMode and a PatchTarget among the elements change how and where the elements
+// are applied, e.g. to append entries to a log without re-sending the log:
+//
+//	c.SyncElements(h.Li(h.Text(entry)), via.PatchTarget("#log"), via.PatchAppend)
 func (c *Context) SyncElements(elem ...h.H) {
-	b := bytes.NewBuffer(nil)
+	b := bufpool.Get()
+	defer bufpool.Put(b)
+	var mode PatchMode
+	var target patchTarget
 	for idx, el := range elem {
-		if el == nil {
+		switch opt := el.(type) {
+		case nil:
 			c.app.logWarn(c, "sync elements failed: element at idx=%d is nil", idx)
 			continue
+		case PatchMode:
+			mode = opt
+			continue
+		case patchTarget:
+			target = opt
+			continue
 		}
 		if err := el.Render(b); err != nil {
 			c.app.logWarn(c, "sync elements failed: element at idx=%d has invalid html", idx)
 			continue
 		}
 	}
-	c.sendPatch(patch{patchTypeElements, b.String()})
+	if mode.needsTarget() && target == "" {
+		c.app.logWarn(c, "sync elements failed: patch mode '%s' requires a PatchTarget", mode)
+		return
+	}
+	c.sendPatch(patch{
+		typ:      patchTypeElements,
+		content:  c.app.formatHTML(b.String()),
+		selector: string(target),
+		mode:     datastar.ElementPatchMode(mode),
+	})
+}
+
+// AppendElements pushes an immediate html patch over the live SSE stream to the
+// browser that appends the given elements as children of the element with the
+// given ID, leaving its existing children untouched. It is SyncElements with
+// PatchTarget("#"+parentID) and PatchAppend.
+func (c *Context) AppendElements(parentID string, elem ...h.H) {
+	c.SyncElements(append(slices.Clip(elem), PatchTarget("#"+parentID), PatchAppend)...)
+}
+
+// SyncID renders the region with the given ID, see Region, and pushes it to the browser
+// as an element patch, without rendering the rest of the view.
+func (c *Context) SyncID(id string) {
+	page := c
+	if c.isComponent() {
+		page = c.parentPageCtx
+	}
+	page.mu.RLock()
+	region, ok := page.regions[id]
+	page.mu.RUnlock()
+	if !ok {
+		c.app.logWarn(c, "sync id failed: no region with id '%s'", id)
+		return
+	}
+	c.SyncElements(region())
+}
+
+// SyncSignal pushes the current value of the given signal, e.g. a *signal, ListSignal
+// or MapSignal, to the browser immediately, leaving changes of other signals pending.
+func (c *Context) SyncSignal(sig interface{ ID() string }) {
+	page := c
+	if c.isComponent() {
+		page = c.parentPageCtx
+	}
+	page.mu.Lock()
+	item, ok := page.signals.Load(sig.ID())
+	var val any
+	switch s := item.(type) {
+	case *signal:
+		if s.err != nil {
+			page.mu.Unlock()
+			c.app.logWarn(c, "signal '%s' is out of sync: %v", s.id, s.err)
+			return
+		}
+		val = s.jsonValue()
+		s.changed = false
+	case collectionSignal:
+		p, changed := s.takePatch()
+		if !changed { // the browser holds the current collection
+			page.mu.Unlock()
+			return
+		}
+		val = p
+	}
+	page.mu.Unlock()
+	if !ok {
+		c.app.logWarn(c, "sync signal failed: unknown signal '%s'", sig.ID())
+		return
+	}
+	outgoingSignals, _ := json.Marshal(map[string]any{sig.ID(): val})
+	c.sendPatch(patch{typ: patchTypeSignals, content: string(outgoingSignals), signals: 1})
 }
 
 // SyncSignals pushes the current signal changes to the browser immediately
@@ -304,24 +681,102 @@ func (c *Context) SyncSignals() {
 	updatedSigs := c.prepareSignalsForPatch()
 	if len(updatedSigs) != 0 {
 		outgoingSignals, _ := json.Marshal(updatedSigs)
-		c.sendPatch(patch{patchTypeSignals, string(outgoingSignals)})
+		c.sendPatch(patch{typ: patchTypeSignals, content: string(outgoingSignals), signals: len(updatedSigs)})
 	}
 }
 
+// reconcile sends the server values of the given signals, which the browser changed
+// optimistically, see Optimistic.
+func (c *Context) reconcile(ids []string) {
+	c.mu.Lock()
+	for _, id := range ids {
+		if item, ok := c.signals.Load(id); ok {
+			if sig, ok := item.(*signal); ok {
+				sig.changed = true
+			}
+		}
+	}
+	c.mu.Unlock()
+	c.SyncSignals()
+}
+
 func (c *Context) ExecScript(s string) {
 	if s == "" {
 		c.app.logWarn(c, "exec script failed: empty script")
 		return
 	}
-	c.sendPatch(patch{patchTypeScript, s})
+	c.sendPatch(patch{typ: patchTypeScript, content: s})
+}
+
+// Debug logs the message with the given key-value pairs at debug level. In DevMode the
+// line is mirrored to the browser console, tagged with the context ID, so the server
+// side of a page can be followed while clicking around. Mirrored lines are queued like
+// patches, see SSEOptions.PatchBuffer.
+//
+// Example:
+//
+//	c.Debug("loaded orders", "count", len(orders), "page", page)
+func (c *Context) Debug(msg string, kv ...any) {
+	var line strings.Builder
+	line.WriteString(msg)
+	fields := make(map[string]any, len(kv)/2+1)
+	for i := 0; i < len(kv); i += 2 {
+		key, val := "!BADKEY", kv[i]
+		if i+1 < len(kv) {
+			key, val = fmt.Sprint(kv[i]), kv[i+1]
+		}
+		fmt.Fprintf(&line, " %s=%v", key, val)
+		if _, err := json.Marshal(val); err != nil {
+			val = fmt.Sprint(val)
+		}
+		fields[key] = val
+	}
+	c.app.logDebug(c, "%s", line.String())
+	if !c.app.cfg.DevMode {
+		return
+	}
+	tag, _ := json.Marshal("[via] ctx=" + c.id)
+	jsMsg, _ := json.Marshal(msg)
+	script := fmt.Sprintf("console.debug(%s,%s", tag, jsMsg)
+	if len(fields) > 0 {
+		jsFields, _ := json.Marshal(fields)
+		script += "," + string(jsFields)
+	}
+	c.sendPatch(patch{typ: patchTypeScript, content: script + ")"})
+}
+
+// Redirect navigates the browser to the given URL. While the page loads, e.g. in the
+// init of a Group, the page request is answered with a 302 Found redirect instead of
+// the page; afterwards the browser is navigated with a script.
+func (c *Context) Redirect(url string) {
+	if c.isComponent() {
+		c = c.parentPageCtx
+	}
+	c.mu.Lock()
+	c.redirect = url
+	c.mu.Unlock()
+	c.ExecScript(fmt.Sprintf("window.location.assign(%q)", url))
+}
+
+// Cookie returns the value of the cookie with the given name sent with the page
+// request, or an empty string if not found.
+func (c *Context) Cookie(name string) string {
+	if c.isComponent() {
+		c = c.parentPageCtx
+	}
+	for _, cookie := range c.cookies {
+		if cookie.Name == name {
+			return cookie.Value
+		}
+	}
+	return ""
 }
 
 // stopAllRoutines stops all go routines tied to this Context preventing goroutine leaks.
+// The channel the routines wait on is closed, so every routine stops, including ones
+// started later.
 func (c *Context) stopAllRoutines() {
-	select {
-	case c.ctxDisposedChan <- struct{}{}:
-	default:
-	}
+	c.stopRoutinesOnce.Do(func() { close(c.ctxDisposedChan) })
 }
 
 func (c *Context) injectRouteParams(params map[string]string) {
@@ -336,6 +791,200 @@ func (c *Context) injectRouteParams(params map[string]string) {
 
 }
 
+func (c *Context) injectQueryParams(query url.Values) {
+	if query == nil {
+		return
+	}
+	m := make(map[string]string)
+	for k := range query {
+		m[k] = query.Get(k)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.queryParams = m
+}
+
+// GetQueryParam retrieves the first value from the page request URL query for the given
+// parameter name or an empty string if not found.
+func (c *Context) GetQueryParam(param string) string {
+	if c.isComponent() {
+		return c.parentPageCtx.GetQueryParam(param)
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if p, ok := c.queryParams[param]; ok {
+		return p
+	}
+	return ""
+}
+
+// ClientIP returns the IP address of the client that requested the page. Behind
+// Options.TrustedProxies, it is taken from the X-Forwarded-For header.
+func (c *Context) ClientIP() string {
+	if c.isComponent() {
+		return c.parentPageCtx.clientIP
+	}
+	return c.clientIP
+}
+
+// BaseURL returns the scheme and host the client used to request the page, e.g.
+// 'https://example.com', for building absolute URLs. Behind Options.TrustedProxies,
+// the X-Forwarded-Proto and X-Forwarded-Host headers are honored.
+func (c *Context) BaseURL() string {
+	if c.isComponent() {
+		return c.parentPageCtx.baseURL
+	}
+	return c.baseURL
+}
+
+// ShareURL returns the absolute URL of the page as it was requested, including the query,
+// e.g. to share it or show it as a QR code with ui.QRCode. Opening it loads the page in a
+// new context.
+func (c *Context) ShareURL() string {
+	if c.isComponent() {
+		return c.parentPageCtx.ShareURL()
+	}
+	return c.baseURL + c.requestURI
+}
+
+// Nonce returns the Content-Security-Policy script nonce of the page, or an empty string
+// if Options.CSP is not set. Set it on inline scripts of the view to allow them.
+//
+// Example:
+//
+//	h.Script(h.Attr("nonce", c.Nonce()), h.Raw(js))
+func (c *Context) Nonce() string {
+	if c.isComponent() {
+		return c.parentPageCtx.Nonce()
+	}
+	return c.nonce
+}
+
+// Ctx returns a context.Context for calls made on behalf of this context, e.g. database
+// queries. Within an action, it is the context of the action request: it is canceled
+// when the browser aborts the request, e.g. because the user left the page, or the
+// action times out. Elsewhere, it is canceled when the page is closed. Both are
+// canceled when the app shuts down.
+func (c *Context) Ctx() context.Context {
+	if c.isComponent() {
+		return c.parentPageCtx.Ctx()
+	}
+	c.ctxMu.Lock()
+	defer c.ctxMu.Unlock()
+	if c.actionRun != nil {
+		return c.actionRun.ctx
+	}
+	return c.lifeContext()
+}
+
+// lifeContext returns the context that is canceled when this context is disposed.
+// c.ctxMu must be held.
+func (c *Context) lifeContext() context.Context {
+	if c.lifeCtx == nil {
+		c.lifeCtx, c.cancelLifeCtx = context.WithCancel(c.app.baseCtx)
+	}
+	return c.lifeCtx
+}
+
+// actionRun is the state of the running action of a context, see beginAction.
+type actionRun struct {
+	ctx       context.Context
+	requestID string
+}
+
+// beginAction waits until the previous action of the context has returned, then sets
+// the context and request ID returned by Ctx and RequestID while the action runs. The
+// context has the given timeout if it is greater than 0. The returned func ends the
+// action, cancels its context and lets the next action begin.
+func (c *Context) beginAction(reqCtx context.Context, timeout time.Duration, requestID string) (context.Context, func()) {
+	c.actionMu.Lock()
+	c.ctxMu.Lock()
+	defer c.ctxMu.Unlock()
+	ctx, cancel := context.WithCancel(reqCtx)
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(reqCtx, timeout)
+	}
+	stop := context.AfterFunc(c.lifeContext(), cancel)
+	c.actionRun = &actionRun{ctx: ctx, requestID: requestID}
+	return ctx, func() {
+		stop()
+		cancel()
+		c.ctxMu.Lock()
+		c.actionRun = nil
+		c.ctxMu.Unlock()
+		c.endActions()
+	}
+}
+
+// runUpdate runs f one at a time with the actions of the page of this context, e.g. an
+// update from another goroutine. If an action is running, f is queued and runs after
+// it, so an action that triggers an update of its own page does not deadlock.
+func (c *Context) runUpdate(f func()) {
+	page := c
+	if c.isComponent() {
+		page = c.parentPageCtx
+	}
+	page.ctxMu.Lock()
+	if !page.actionMu.TryLock() {
+		page.pendingUpdates = append(page.pendingUpdates, f)
+		page.ctxMu.Unlock()
+		return
+	}
+	page.ctxMu.Unlock()
+	f()
+	page.endActions()
+}
+
+// endActions runs the updates queued by runUpdate, then lets the next action begin.
+// The action lock is released under ctxMu, so runUpdate never queues an update that
+// no one runs.
+func (c *Context) endActions() {
+	for {
+		c.ctxMu.Lock()
+		if len(c.pendingUpdates) == 0 {
+			c.actionMu.Unlock()
+			c.ctxMu.Unlock()
+			return
+		}
+		f := c.pendingUpdates[0]
+		c.pendingUpdates = c.pendingUpdates[1:]
+		c.ctxMu.Unlock()
+		f()
+	}
+}
+
+// dispose stops the routines of this context and cancels the context returned by Ctx.
+func (c *Context) dispose() {
+	c.stopAllRoutines()
+	c.ctxMu.Lock()
+	defer c.ctxMu.Unlock()
+	if c.cancelLifeCtx != nil {
+		c.cancelLifeCtx()
+	}
+}
+
+// RequestID returns the ID of the running action request of this context, or else the
+// latest page, SSE or action request, taken from the X-Request-ID request header or
+// generated by Via. The ID is also sent in the X-Request-ID response header and
+// included in Via's log lines, so application logs can be correlated with them.
+func (c *Context) RequestID() string {
+	if c.isComponent() {
+		return c.parentPageCtx.RequestID()
+	}
+	c.ctxMu.Lock()
+	run := c.actionRun
+	c.ctxMu.Unlock()
+	if run != nil {
+		return run.requestID
+	}
+	id, _ := c.requestID.Load().(string)
+	return id
+}
+
+func (c *Context) setRequestID(id string) {
+	c.requestID.Store(id)
+}
+
 // GetPathParam retrieves the value from the page request URL for the given parameter name
 // or an empty string if not found.
 //
@@ -352,6 +1001,9 @@ func (c *Context) injectRouteParams(params map[string]string) {
 //			})
 //	})
 func (c *Context) GetPathParam(param string) string {
+	if c.isComponent() {
+		return c.parentPageCtx.GetPathParam(param)
+	}
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 	if p, ok := c.routeParams[param]; ok {
@@ -369,11 +1021,14 @@ func newContext(id string, route string, v *V) *Context {
 		id:                id,
 		route:             route,
 		routeParams:       make(map[string]string),
+		queryParams:       make(map[string]string),
 		app:               v,
 		componentRegistry: make(map[string]*Context),
 		actionRegistry:    make(map[string]func()),
+		actionTimeouts:    make(map[string]time.Duration),
+		uploadRegistry:    make(map[string]func([]UploadedFile)),
 		signals:           new(sync.Map),
-		patchChan:         make(chan patch, 1),
-		ctxDisposedChan:   make(chan struct{}, 1),
+		patchChan:         make(chan patch, v.cfg.SSE.patchBufferSize()),
+		ctxDisposedChan:   make(chan struct{}),
 	}
 }
@@ -0,0 +1,119 @@
+package via
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORS configures cross-origin requests to routes registered with API and,
+// optionally, to the action, SSE and upload endpoints of Via. Pages and routes
+// registered with HandleFunc are never shared with other origins.
+type CORS struct {
+	// Origins allowed to make requests, e.g. 'https://example.com', or "*" for any.
+	AllowedOrigins []string
+
+	// Methods allowed in preflight requests. Default: GET, POST, PUT, PATCH, DELETE.
+	AllowedMethods []string
+
+	// Request headers allowed in preflight requests. Default: Content-Type, Authorization.
+	AllowedHeaders []string
+
+	// Response headers exposed to the client.
+	ExposedHeaders []string
+
+	// If true, requests may include credentials such as cookies.
+	AllowCredentials bool
+
+	// How long browsers may cache preflight responses.
+	MaxAge time.Duration
+
+	// If true, CORS also applies to the action, SSE and upload endpoints, e.g. for
+	// pages embedded in another origin.
+	FrameworkEndpoints bool
+}
+
+// frameworkPatterns are the routes of Via covered by CORS.FrameworkEndpoints.
+var frameworkPatterns = []string{"GET /_sse", "GET /_action/{id}", "POST /_upload/{id}"}
+
+// allowedOrigin returns the value of the Access-Control-Allow-Origin header for the
+// given origin, or an empty string if the origin is not allowed.
+func (cors *CORS) allowedOrigin(origin string) string {
+	for _, o := range cors.AllowedOrigins {
+		if o == "*" && !cors.AllowCredentials {
+			return "*"
+		}
+		if o == "*" || strings.EqualFold(o, origin) {
+			return origin
+		}
+	}
+	return ""
+}
+
+// corsApplies reports whether CORS applies to the route that handles the request.
+func (v *V) corsApplies(r *http.Request) bool {
+	_, pattern := v.mux.Handler(r)
+	if v.apiPatterns[pattern] {
+		return true
+	}
+	return v.cfg.CORS.FrameworkEndpoints && slices.Contains(frameworkPatterns, pattern)
+}
+
+// handleCORS sets the CORS headers on the response if the request is a cross-origin
+// request to a route covered by Options.CORS. It reports whether the request was a
+// preflight request, which is answered completely.
+func (v *V) handleCORS(w http.ResponseWriter, r *http.Request) bool {
+	cors := v.cfg.CORS
+	origin := r.Header.Get("Origin")
+	if cors == nil || origin == "" {
+		return false
+	}
+	reqMethod := r.Header.Get("Access-Control-Request-Method")
+	preflight := r.Method == http.MethodOptions && reqMethod != ""
+	routed := r
+	if preflight {
+		routed = r.Clone(r.Context())
+		routed.Method = reqMethod
+	}
+	if !v.corsApplies(routed) {
+		return false
+	}
+	w.Header().Add("Vary", "Origin")
+	allowed := cors.allowedOrigin(origin)
+	if allowed == "" {
+		if preflight {
+			w.WriteHeader(http.StatusForbidden)
+		}
+		return preflight
+	}
+	w.Header().Set("Access-Control-Allow-Origin", allowed)
+	if cors.AllowCredentials {
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+	}
+	if !preflight {
+		if len(cors.ExposedHeaders) > 0 {
+			w.Header().Set("Access-Control-Expose-Headers", strings.Join(cors.ExposedHeaders, ", "))
+		}
+		return false
+	}
+	methods := cors.AllowedMethods
+	if len(methods) == 0 {
+		methods = []string{"GET", "POST", "PUT", "PATCH", "DELETE"}
+	}
+	headers := cors.AllowedHeaders
+	if len(headers) == 0 {
+		headers = []string{"Content-Type", "Authorization"}
+	}
+	if cors.FrameworkEndpoints {
+		headers = append(slices.Clone(headers), "Datastar-Request")
+	}
+	w.Header().Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+	w.Header().Set("Access-Control-Allow-Headers", strings.Join(headers, ", "))
+	if cors.MaxAge > 0 {
+		w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(cors.MaxAge.Seconds())))
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return true
+}
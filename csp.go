@@ -0,0 +1,135 @@
+package via
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/go-via/via/h"
+)
+
+// CSP configures the Content-Security-Policy header of pages. Via generates a nonce for
+// every page request, adds it to the script-src directive and sets it on the scripts it
+// emits, including scripts run with ExecScript. Use Context.Nonce to allow inline
+// scripts of the app.
+//
+// The default policy only allows resources of the own origin. Scripts added with
+// AppendToHead, e.g. by plugins, must be allowed by their URL or host, unless they are
+// built with NonceScript:
+//
+//	via.NewCSP().Allow("script-src", "https://cdn.example.com/lib.js")
+//
+// Note that Datastar evaluates data-* expressions as functions, so script-src includes
+// 'unsafe-eval'.
+type CSP struct {
+	directives map[string][]string
+	reportOnly bool
+}
+
+type cspDirective struct {
+	directive string
+	sources   []string
+}
+
+// cspDefaults are the directives and sources of the default policy in header order.
+var cspDefaults = []cspDirective{
+	{"default-src", []string{"'self'"}},
+	{"script-src", []string{"'self'", "'unsafe-eval'"}},
+	{"style-src", []string{"'self'", "'unsafe-inline'"}},
+	{"img-src", []string{"'self'", "data:"}},
+	{"connect-src", []string{"'self'"}},
+	{"object-src", []string{"'none'"}},
+	{"base-uri", []string{"'self'"}},
+	{"frame-ancestors", []string{"'self'"}},
+}
+
+// NewCSP returns the default policy.
+func NewCSP() *CSP {
+	return &CSP{directives: map[string][]string{}}
+}
+
+// Allow adds sources to a directive of the policy. Sources of directives that are not
+// part of the default policy are added as new directives.
+func (p *CSP) Allow(directive string, sources ...string) *CSP {
+	p.directives[directive] = append(p.directives[directive], sources...)
+	return p
+}
+
+// ReportOnly sends the policy in the Content-Security-Policy-Report-Only header, so
+// violations are reported by the browser without being blocked.
+func (p *CSP) ReportOnly() *CSP {
+	p.reportOnly = true
+	return p
+}
+
+func (p *CSP) headerName() string {
+	if p.reportOnly {
+		return "Content-Security-Policy-Report-Only"
+	}
+	return "Content-Security-Policy"
+}
+
+// header returns the policy with the given script nonce.
+func (p *CSP) header(nonce string) string {
+	var parts []string
+	for _, d := range cspDefaults {
+		sources := append(slices.Clone(d.sources), p.directives[d.directive]...)
+		if d.directive == "script-src" {
+			sources = append(sources, "'nonce-"+nonce+"'")
+		}
+		parts = append(parts, d.directive+" "+strings.Join(sources, " "))
+	}
+	var extra []string
+	for directive := range p.directives {
+		if !slices.ContainsFunc(cspDefaults, func(d cspDirective) bool { return d.directive == directive }) {
+			extra = append(extra, directive)
+		}
+	}
+	slices.Sort(extra)
+	for _, directive := range extra {
+		parts = append(parts, strings.TrimSpace(directive+" "+strings.Join(p.directives[directive], " ")))
+	}
+	return strings.Join(parts, "; ")
+}
+
+func genNonce() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+// NonceScript returns a script element for AppendToHead and AppendToFoot that carries
+// the nonce of each page, so it runs under the policy of Options.CSP without allowing its
+// source, e.g. the CDN scripts of plugins.
+//
+// Example:
+//
+//	v.AppendToHead(via.NonceScript(h.Attr("defer"), h.Src("https://cdn.example.com/lib.js")))
+func NonceScript(children ...h.H) h.H {
+	return nonceScript(children)
+}
+
+// nonceScript is a script element that gets the nonce of the page, see withNonce.
+type nonceScript []h.H
+
+// Render renders the script without a nonce.
+func (s nonceScript) Render(w io.Writer) error {
+	return h.Script(s...).Render(w)
+}
+
+// withNonce returns the nodes with the script elements of NonceScript carrying nonce.
+func withNonce(nodes []h.H, nonce string) []h.H {
+	if nonce == "" {
+		return nodes
+	}
+	result := make([]h.H, len(nodes))
+	for i, node := range nodes {
+		if s, ok := node.(nonceScript); ok {
+			node = h.Script(append([]h.H{h.Attr("nonce", nonce)}, s...)...)
+		}
+		result[i] = node
+	}
+	return result
+}
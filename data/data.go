@@ -0,0 +1,63 @@
+// Package data defines a common interface for paginated, sorted and filtered data
+// sources, with adapters for slices and database/sql, so data-heavy components such as
+// ui.InfiniteList can load pages from any source.
+//
+// Example:
+//
+//	users := data.FromSlice(allUsers, func(u User, filter string) bool {
+//		return strings.Contains(u.Name, filter)
+//	}, map[string]func(a, b User) int{
+//		"name": func(a, b User) int { return strings.Compare(a.Name, b.Name) },
+//	})
+//	res, err := users.Fetch(ctx, data.Query{Page: 0, PerPage: 20, Sort: "name"})
+package data
+
+import "context"
+
+// Query selects a page of items.
+type Query struct {
+	// The page number, starting at 0.
+	Page int
+
+	// The number of items per page. Values less than 1 mean one item.
+	PerPage int
+
+	// The key to sort by, as supported by the provider. Empty for the natural order.
+	Sort string
+
+	// If true, items are sorted in descending order.
+	Desc bool
+
+	// A filter string, e.g. typed into a search input. Empty for all items.
+	Filter string
+}
+
+func (q Query) offset() int {
+	return max(q.Page, 0) * q.perPage()
+}
+
+func (q Query) perPage() int {
+	return max(q.PerPage, 1)
+}
+
+// Result is a page of items.
+type Result[T any] struct {
+	// The items of the page.
+	Items []T
+
+	// The number of items that match the filter across all pages.
+	Total int
+}
+
+// Provider is a source of paginated items.
+type Provider[T any] interface {
+	Fetch(ctx context.Context, q Query) (Result[T], error)
+}
+
+// ProviderFunc adapts a func to a Provider.
+type ProviderFunc[T any] func(ctx context.Context, q Query) (Result[T], error)
+
+// Fetch calls f.
+func (f ProviderFunc[T]) Fetch(ctx context.Context, q Query) (Result[T], error) {
+	return f(ctx, q)
+}
@@ -0,0 +1,84 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromSlice(t *testing.T) {
+	words := FromSlice([]string{"pear", "apple", "fig", "plum"},
+		func(w, filter string) bool { return strings.Contains(w, filter) },
+		map[string]func(a, b string) int{"alpha": strings.Compare})
+
+	testcases := []struct {
+		desc     string
+		query    Query
+		expected Result[string]
+	}{
+		{"first page", Query{PerPage: 3}, Result[string]{Items: []string{"pear", "apple", "fig"}, Total: 4}},
+		{"last page", Query{Page: 1, PerPage: 3}, Result[string]{Items: []string{"plum"}, Total: 4}},
+		{"past the end", Query{Page: 5, PerPage: 3}, Result[string]{Items: []string{}, Total: 4}},
+		{"sorted", Query{PerPage: 2, Sort: "alpha"}, Result[string]{Items: []string{"apple", "fig"}, Total: 4}},
+		{"sorted descending", Query{PerPage: 2, Sort: "alpha", Desc: true}, Result[string]{Items: []string{"plum", "pear"}, Total: 4}},
+		{"filtered", Query{PerPage: 10, Filter: "p"}, Result[string]{Items: []string{"pear", "apple", "plum"}, Total: 3}},
+	}
+	for _, testcase := range testcases {
+		t.Run(testcase.desc, func(t *testing.T) {
+			res, err := words.Fetch(context.Background(), testcase.query)
+			assert.NoError(t, err)
+			assert.Equal(t, testcase.expected, res)
+		})
+	}
+
+	_, err := words.Fetch(context.Background(), Query{Sort: "length"})
+	assert.Error(t, err)
+}
+
+func TestSQL(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	base := "(SELECT name FROM users WHERE active = ?) AS data_q WHERE name LIKE ? ESCAPE '!'"
+	mock.ExpectQuery("SELECT COUNT(*) FROM "+base).
+		WithArgs(true, "%an%").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectQuery("SELECT * FROM "+base+" ORDER BY name DESC LIMIT ? OFFSET ?").
+		WithArgs(true, "%an%", 2, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("Ann"))
+	// wildcards in filters match literally
+	mock.ExpectQuery("SELECT COUNT(*) FROM "+base).
+		WithArgs(true, "%100!% a!_b!!%").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery("SELECT * FROM "+base+" LIMIT ? OFFSET ?").
+		WithArgs(true, "%100!% a!_b!!%", 1, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}))
+
+	users := &SQL[string]{
+		DB:           db,
+		Query:        "SELECT name FROM users WHERE active = ?",
+		Args:         []any{true},
+		SortColumns:  map[string]string{"name": "name"},
+		FilterClause: "name LIKE ?",
+		Scan: func(rows *sql.Rows) (string, error) {
+			var name string
+			err := rows.Scan(&name)
+			return name, err
+		},
+	}
+	res, err := users.Fetch(context.Background(), Query{Page: 1, PerPage: 2, Sort: "name", Desc: true, Filter: "an"})
+	assert.NoError(t, err)
+	assert.Equal(t, Result[string]{Items: []string{"Ann"}, Total: 3}, res)
+	res, err = users.Fetch(context.Background(), Query{Filter: "100% a_b!"})
+	assert.NoError(t, err)
+	assert.Equal(t, Result[string]{}, res)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	_, err = users.Fetch(context.Background(), Query{Sort: "name; DROP TABLE users"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,41 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// FromSlice returns a Provider of the given items. match reports whether an item matches
+// a non-empty filter; if nil, filters are ignored. sorts maps the sort keys of queries to
+// compare funcs; queries with other sort keys fail.
+func FromSlice[T any](items []T, match func(item T, filter string) bool, sorts map[string]func(a, b T) int) Provider[T] {
+	return ProviderFunc[T](func(_ context.Context, q Query) (Result[T], error) {
+		res := items
+		if q.Filter != "" && match != nil {
+			res = nil
+			for _, item := range items {
+				if match(item, q.Filter) {
+					res = append(res, item)
+				}
+			}
+		}
+		if q.Sort != "" {
+			cmp, ok := sorts[q.Sort]
+			if !ok {
+				return Result[T]{}, fmt.Errorf("data: unsupported sort key '%s'", q.Sort)
+			}
+			res = slices.Clone(res)
+			slices.SortStableFunc(res, cmp)
+			if q.Desc {
+				slices.Reverse(res)
+			}
+		} else if q.Desc {
+			res = slices.Clone(res)
+			slices.Reverse(res)
+		}
+		start := min(q.offset(), len(res))
+		end := min(start+q.perPage(), len(res))
+		return Result[T]{Items: slices.Clone(res[start:end]), Total: len(res)}, nil
+	})
+}
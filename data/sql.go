@@ -0,0 +1,91 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// likeEscape is the escape character of the LIKE patterns of filters. Unlike a
+// backslash, it needs no escaping in the string literals of any SQL dialect.
+const likeEscape = "!"
+
+// likeEscaper escapes the wildcards of LIKE patterns and the escape character.
+var likeEscaper = strings.NewReplacer(likeEscape, likeEscape+likeEscape, "%", likeEscape+"%", "_", likeEscape+"_")
+
+// SQL is a Provider of the rows of a database/sql query.
+type SQL[T any] struct {
+	// The database to query.
+	DB *sql.DB
+
+	// The base query selecting all items, without ORDER BY, LIMIT or OFFSET clauses,
+	// e.g. "SELECT id, name FROM users".
+	Query string
+
+	// Arguments of the base query.
+	Args []any
+
+	// Maps the sort keys of queries to SQL expressions to order by, e.g.
+	// {"name": "name"}. Only these keys are accepted, so sort keys can not inject SQL.
+	SortColumns map[string]string
+
+	// A condition applied to the rows of the base query for non-empty filters, with a ?
+	// placeholder for the pattern of each LIKE, e.g. "name LIKE ? OR email LIKE ?".
+	// Each placeholder is bound to the filter wrapped in '%' wildcards, with the
+	// wildcards in the filter escaped by '!', and followed by ESCAPE '!', so filters
+	// match literally. If empty, filters are ignored.
+	FilterClause string
+
+	// Scan reads an item from the current row.
+	Scan func(rows *sql.Rows) (T, error)
+}
+
+// Fetch runs the query for the page and counts the matching rows.
+func (p *SQL[T]) Fetch(ctx context.Context, q Query) (Result[T], error) {
+	var order string
+	if q.Sort != "" {
+		col, ok := p.SortColumns[q.Sort]
+		if !ok {
+			return Result[T]{}, fmt.Errorf("data: unsupported sort key '%s'", q.Sort)
+		}
+		order = " ORDER BY " + col
+		if q.Desc {
+			order += " DESC"
+		}
+	}
+
+	from := "(" + p.Query + ") AS data_q"
+	args := p.Args
+	if q.Filter != "" && p.FilterClause != "" {
+		from += " WHERE " + strings.ReplaceAll(p.FilterClause, "?", "? ESCAPE '"+likeEscape+"'")
+		pattern := "%" + likeEscaper.Replace(q.Filter) + "%"
+		for range strings.Count(p.FilterClause, "?") {
+			args = append(args[:len(args):len(args)], pattern)
+		}
+	}
+
+	var res Result[T]
+	if err := p.DB.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+from, args...).Scan(&res.Total); err != nil {
+		return res, fmt.Errorf("data: count rows: %w", err)
+	}
+
+	query := "SELECT * FROM " + from + order
+	query += " LIMIT ? OFFSET ?"
+	rows, err := p.DB.QueryContext(ctx, query, append(args[:len(args):len(args)], q.perPage(), q.offset())...)
+	if err != nil {
+		return res, fmt.Errorf("data: query rows: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		item, err := p.Scan(rows)
+		if err != nil {
+			return res, fmt.Errorf("data: scan row: %w", err)
+		}
+		res.Items = append(res.Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return res, fmt.Errorf("data: read rows: %w", err)
+	}
+	return res, nil
+}
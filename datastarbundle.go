@@ -0,0 +1,83 @@
+package via
+
+import (
+	"encoding/json"
+
+	"github.com/go-via/via/h"
+)
+
+// DatastarVersion is the version of the Datastar bundle embedded in Via.
+const DatastarVersion = "1.0.0-RC.6"
+
+// datastarPath is the route the Datastar bundle is served at.
+const datastarPath = "/_datastar.js"
+
+// DatastarBundle configures the Datastar client script loaded by pages. By default the
+// bundle embedded in Via is served at a content-hashed URL that is cached indefinitely,
+// and at /_datastar.js.
+type DatastarBundle struct {
+	// A bundle served instead of the embedded one, e.g. a newer
+	// Datastar release. It must be compatible with the SSE events of the embedded version.
+	Script []byte
+
+	// A bundle served instead of Script in DevMode, e.g. a non-minified debug build.
+	DebugScript []byte
+
+	// An external URL pages load the bundle from instead of Via, e.g. a CDN.
+	// The URL must be allowed by Options.CSP if set.
+	URL string
+
+	// The subresource integrity hash of the bundle at URL, e.g. 'sha384-...'.
+	Integrity string
+
+	// JavaScript modules run on every page after Datastar is loaded, e.g. to register
+	// custom attribute or action plugins. They import Datastar as 'datastar':
+	//
+	//	import { attribute } from 'datastar'
+	Extensions []string
+}
+
+// script returns the bundle served by Via.
+func (b DatastarBundle) script(devMode bool) []byte {
+	if devMode && b.DebugScript != nil {
+		return b.DebugScript
+	}
+	if b.Script != nil {
+		return b.Script
+	}
+	return datastarJS
+}
+
+// datastarSrc returns the URL pages load the Datastar bundle from, by default the
+// content-hashed URL of the served bundle.
+func (v *V) datastarSrc() string {
+	if v.cfg.Datastar.URL != "" {
+		return v.cfg.Datastar.URL
+	}
+	return v.loadAsset(&v.datastarAsset).url()
+}
+
+// updateDatastarAsset rebuilds the served Datastar bundle from the config.
+func (v *V) updateDatastarAsset() {
+	a := newAsset("datastar.js", "application/javascript", v.cfg.Datastar.script(v.cfg.DevMode))
+	v.setAsset(&v.datastarAsset, a)
+}
+
+// importMap returns the import map that resolves 'datastar' to src for extensions, or
+// nil if there are none.
+func (b DatastarBundle) importMap(src, nonce string) h.H {
+	if len(b.Extensions) == 0 {
+		return nil
+	}
+	m, _ := json.Marshal(map[string]map[string]string{"imports": {"datastar": src}})
+	return h.Script(h.Type("importmap"), h.If(nonce != "", h.Attr("nonce", nonce)), h.Raw(string(m)))
+}
+
+// extensions returns the module scripts of the extensions.
+func (b DatastarBundle) extensions(nonce string) []h.H {
+	var scripts []h.H
+	for _, ext := range b.Extensions {
+		scripts = append(scripts, h.Script(h.Type("module"), h.If(nonce != "", h.Attr("nonce", nonce)), h.Raw(ext)))
+	}
+	return scripts
+}
@@ -0,0 +1,113 @@
+package via
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"net"
+	"net/http"
+	"net/netip"
+	"time"
+
+	"github.com/go-via/via/desktop"
+)
+
+// desktopTokenParam is the query param of the URL opened in the window of a desktop
+// app that carries the token of the app, see Desktop.
+const desktopTokenParam = "via-desktop-token"
+
+// desktopCookie is the cookie that carries the token of a desktop app.
+const desktopCookie = "via_desktop"
+
+// Desktop runs the Via app as a local desktop tool: it serves the app on a loopback
+// address and shows it in a window, see desktop.Options. Desktop blocks until the
+// window is closed, then shuts the app down. Call it instead of Start, after the pages
+// are registered.
+//
+// Other local processes and web pages can reach the loopback address too, so the app
+// only answers requests that carry a random token: the window opens a URL with the
+// token, which is exchanged for a cookie. Requests whose Host is not a loopback address
+// are rejected as well, which defeats DNS rebinding.
+//
+// Example:
+//
+//	if err := via.Desktop(v, desktop.Options{Title: "Notes", Size: desktop.Size{Width: 800, Height: 600}}); err != nil {
+//		log.Fatal(err)
+//	}
+func Desktop(v *V, o desktop.Options) error {
+	if o.Title == "" {
+		o.Title = v.cfg.DocumentTitle
+	} else {
+		v.cfg.DocumentTitle = o.Title
+	}
+	if o.Size.Width <= 0 || o.Size.Height <= 0 {
+		o.Size = desktop.DefaultSize
+	}
+	if o.Window == nil {
+		o.Window = desktop.Browser
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return err
+	}
+	v.desktopToken = rand.Text()
+	v.httpServer() // so Shutdown stops the server even if it did not start serving yet
+	served := make(chan error, 1)
+	go func() { served <- v.ServeListener(l) }()
+
+	winErr := o.Window("http://"+l.Addr().String()+"/?"+desktopTokenParam+"="+v.desktopToken, o)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return errors.Join(winErr, v.Shutdown(ctx), <-served)
+}
+
+// checkDesktop reports whether the request is from the window of the desktop app. It
+// answers other requests with 403 Forbidden, and the URL opened in the window with a
+// redirect that sets the token cookie.
+func (v *V) checkDesktop(w http.ResponseWriter, r *http.Request) bool {
+	if !isLoopbackHost(r.Host) {
+		v.logWarn(nil, "desktop request with host %q rejected", r.Host)
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return false
+	}
+	if ck, err := r.Cookie(desktopCookie); err == nil && v.isDesktopToken(ck.Value) {
+		return true
+	}
+	q := r.URL.Query()
+	if !v.isDesktopToken(q.Get(desktopTokenParam)) {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return false
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     desktopCookie,
+		Value:    v.desktopToken,
+		Path:     "/",
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+	})
+	// drop the token from the URL, so it does not show up in the history
+	q.Del(desktopTokenParam)
+	u := *r.URL
+	u.RawQuery = q.Encode()
+	http.Redirect(w, r, u.RequestURI(), http.StatusSeeOther)
+	return false
+}
+
+// isDesktopToken reports whether token is the token of the desktop app.
+func (v *V) isDesktopToken(token string) bool {
+	return subtle.ConstantTimeCompare([]byte(token), []byte(v.desktopToken)) == 1
+}
+
+// isLoopbackHost reports whether the Host header of a request names a loopback
+// address.
+func isLoopbackHost(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if host == "localhost" {
+		return true
+	}
+	addr, err := netip.ParseAddr(host)
+	return err == nil && addr.IsLoopback()
+}
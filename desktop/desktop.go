@@ -0,0 +1,123 @@
+// Package desktop configures Via apps that run as local desktop tools, see via.Desktop.
+package desktop
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+)
+
+// ErrNoBrowser is returned by Browser if no Chromium-based browser is installed.
+var ErrNoBrowser = errors.New("desktop: no Chromium-based browser found")
+
+// Options configures the window of a desktop app.
+type Options struct {
+	// Title is the title of the window. Defaults to the DocumentTitle of the app.
+	Title string
+
+	// Size is the initial size of the window. Defaults to 1024x768.
+	Size Size
+
+	// Window opens the window that shows the app at url and blocks until it is closed.
+	// Defaults to Browser. Set it to embed a native webview instead, e.g. with
+	// github.com/webview/webview_go:
+	//
+	//	Window: func(url string, o desktop.Options) error {
+	//		w := webview.New(false)
+	//		defer w.Destroy()
+	//		w.SetTitle(o.Title)
+	//		w.SetSize(o.Size.Width, o.Size.Height, webview.HintNone)
+	//		w.Navigate(url)
+	//		w.Run()
+	//		return nil
+	//	}
+	Window func(url string, o Options) error
+}
+
+// Size is the size of a window in pixels.
+type Size struct {
+	Width, Height int
+}
+
+// DefaultSize is the size of windows whose Options have no Size.
+var DefaultSize = Size{Width: 1024, Height: 768}
+
+// Browser shows url in a window of a Chromium-based browser in app mode, i.e. without
+// tabs and address bar, and blocks until the window is closed. The browser runs with
+// a fresh profile, so it does not share cookies or extensions with the user's browser.
+func Browser(url string, o Options) error {
+	bin := findBrowser()
+	if bin == "" {
+		return ErrNoBrowser
+	}
+	profile, err := os.MkdirTemp("", "via-desktop-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(profile)
+	cmd := exec.Command(bin, browserArgs(url, profile, o)...)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("desktop: %s: %w", filepath.Base(bin), err)
+	}
+	return nil
+}
+
+// browserArgs returns the command line args of a browser window in app mode.
+func browserArgs(url, profile string, o Options) []string {
+	size := o.Size
+	if size.Width <= 0 || size.Height <= 0 {
+		size = DefaultSize
+	}
+	return []string{
+		"--app=" + url,
+		fmt.Sprintf("--window-size=%d,%d", size.Width, size.Height),
+		"--user-data-dir=" + profile,
+		"--no-first-run",
+		"--no-default-browser-check",
+		"--disable-sync",
+	}
+}
+
+// findBrowser returns the path of an installed Chromium-based browser, or "" if there
+// is none.
+func findBrowser() string {
+	for _, name := range browserCandidates() {
+		if filepath.IsAbs(name) {
+			if _, err := os.Stat(name); err == nil {
+				return name
+			}
+		} else if p, err := exec.LookPath(name); err == nil {
+			return p
+		}
+	}
+	return ""
+}
+
+// browserCandidates returns the names or paths of the browsers Browser tries, in order.
+func browserCandidates() []string {
+	switch runtime.GOOS {
+	case "darwin":
+		return []string{
+			"/Applications/Google Chrome.app/Contents/MacOS/Google Chrome",
+			"/Applications/Chromium.app/Contents/MacOS/Chromium",
+			"/Applications/Microsoft Edge.app/Contents/MacOS/Microsoft Edge",
+			"/Applications/Brave Browser.app/Contents/MacOS/Brave Browser",
+		}
+	case "windows":
+		var paths []string
+		for _, dir := range []string{os.Getenv("LocalAppData"), os.Getenv("ProgramFiles"), os.Getenv("ProgramFiles(x86)")} {
+			if dir == "" {
+				continue
+			}
+			paths = append(paths,
+				filepath.Join(dir, `Google\Chrome\Application\chrome.exe`),
+				filepath.Join(dir, `Microsoft\Edge\Application\msedge.exe`),
+				filepath.Join(dir, `Chromium\Application\chrome.exe`))
+		}
+		return paths
+	}
+	return []string{"google-chrome", "google-chrome-stable", "chromium", "chromium-browser", "microsoft-edge", "brave-browser"}
+}
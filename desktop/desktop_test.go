@@ -0,0 +1,26 @@
+package desktop
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBrowserArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		size Size
+		want string
+	}{
+		{"default size", Size{}, "--window-size=1024,768"},
+		{"size", Size{Width: 800, Height: 600}, "--window-size=800,600"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := browserArgs("http://127.0.0.1:1234/", "/tmp/profile", Options{Size: tt.size})
+			assert.Contains(t, args, "--app=http://127.0.0.1:1234/")
+			assert.Contains(t, args, "--user-data-dir=/tmp/profile")
+			assert.Contains(t, args, tt.want)
+		})
+	}
+}
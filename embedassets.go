@@ -0,0 +1,167 @@
+package via
+
+import (
+	"fmt"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// embeddedAssetsDir is the directory of the assets tree embedded with EmbedAssets.
+const embeddedAssetsDir = "assets"
+
+// assetPollInterval is the interval in which EmbedAssets checks the assets directory
+// for changes in DevMode.
+var assetPollInterval = 500 * time.Millisecond
+
+// EmbedAssets serves the files of the assets/ directory of fsys, or of fsys itself if
+// it has no such directory, under content-hashed URLs that browsers cache indefinitely.
+// V.Asset and Context.Asset resolve the names of the files, relative to the assets
+// directory, to their URLs.
+//
+// In DevMode the assets are read from the assets/ directory of the working directory
+// instead, if it exists, and changes are picked up while the app runs. Changed
+// stylesheets are swapped in open pages without a reload. Call EmbedAssets after Config.
+//
+// Example:
+//
+//	//go:embed assets
+//	var assets embed.FS
+//
+//	v.EmbedAssets(assets)
+//	v.AppendToHead(h.Link(h.Rel("stylesheet"), h.Href(v.Asset("app.css"))))
+func (v *V) EmbedAssets(fsys fs.FS) {
+	if sub, err := fs.Sub(fsys, embeddedAssetsDir); err == nil {
+		if _, err := fs.Stat(sub, "."); err == nil {
+			fsys = sub
+		}
+	}
+	dev := v.cfg.DevMode
+	if dev {
+		if info, err := os.Stat(embeddedAssetsDir); err == nil && info.IsDir() {
+			fsys = os.DirFS(embeddedAssetsDir)
+		} else {
+			dev = false
+		}
+	}
+	if _, err := v.loadEmbeddedAssets(fsys); err != nil {
+		v.logErr(nil, "embed assets failed: %v", err)
+		return
+	}
+	if dev {
+		go v.watchEmbeddedAssets(fsys)
+	}
+}
+
+// loadEmbeddedAssets reads the files of fsys and serves the new or changed ones. It
+// returns the previous assets of the changed files.
+func (v *V) loadEmbeddedAssets(fsys fs.FS) (map[string]*asset, error) {
+	changed := make(map[string]*asset)
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		body, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		a := newAsset(name, assetContentType(name, body), body)
+		v.assetsMu.Lock()
+		defer v.assetsMu.Unlock()
+		prev := v.embeddedAssets[name]
+		if prev != nil && prev.hash == a.hash {
+			return nil
+		}
+		if v.embeddedAssets == nil {
+			v.embeddedAssets = make(map[string]*asset)
+		}
+		if prev != nil {
+			delete(v.assets, prev.hashedName)
+		}
+		v.embeddedAssets[name] = a
+		v.assets[a.hashedName] = a
+		changed[name] = prev
+		return nil
+	})
+	return changed, err
+}
+
+// assetContentType returns the content type of an asset by its extension or content.
+func assetContentType(name string, body []byte) string {
+	if ct := mime.TypeByExtension(path.Ext(name)); ct != "" {
+		return ct
+	}
+	return http.DetectContentType(body)
+}
+
+// Asset returns the URL of the asset with the given name, e.g. "app.css" or
+// "img/logo.svg", embedded with EmbedAssets. The URL contains a hash of the content,
+// so browsers can cache the asset indefinitely. Unknown assets are logged and resolve
+// to their unhashed URL.
+//
+// Example:
+//
+//	h.Link(h.Rel("stylesheet"), h.Href(v.Asset("app.css")))
+func (v *V) Asset(name string) string {
+	name = strings.TrimPrefix(name, "/")
+	v.assetsMu.RLock()
+	defer v.assetsMu.RUnlock()
+	if a, ok := v.embeddedAssets[name]; ok {
+		return a.url()
+	}
+	v.logWarn(nil, "asset '%s' not found", name)
+	return assetsPath + name
+}
+
+// Asset returns the URL of an asset embedded with V.EmbedAssets, see V.Asset.
+func (c *Context) Asset(name string) string {
+	return c.app.Asset(name)
+}
+
+// getEmbeddedAsset returns the embedded asset with the given unhashed name.
+func (v *V) getEmbeddedAsset(name string) (*asset, bool) {
+	v.assetsMu.RLock()
+	defer v.assetsMu.RUnlock()
+	a, ok := v.embeddedAssets[name]
+	return a, ok
+}
+
+// watchEmbeddedAssets reloads changed assets until the app shuts down and swaps changed
+// stylesheets in the open pages.
+func (v *V) watchEmbeddedAssets(fsys fs.FS) {
+	ticker := time.NewTicker(assetPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-v.shutdownChan:
+			return
+		case <-ticker.C:
+		}
+		changed, err := v.loadEmbeddedAssets(fsys)
+		if err != nil {
+			v.logWarn(nil, "reload assets failed: %v", err)
+			continue
+		}
+		for name, prev := range changed {
+			v.logDebug(nil, "asset '%s' changed", name)
+			if prev != nil && path.Ext(name) == ".css" {
+				v.swapStylesheet(prev.url(), v.Asset(name))
+			}
+		}
+	}
+}
+
+// swapStylesheet points the stylesheet links with the old URL in the open pages to the
+// new URL.
+func (v *V) swapStylesheet(oldURL, newURL string) {
+	script := fmt.Sprintf(`document.querySelectorAll('link[rel=stylesheet][href=%q]').forEach((l) => l.href = %q)`, oldURL, newURL)
+	v.contextRegistryMutex.RLock()
+	defer v.contextRegistryMutex.RUnlock()
+	for _, c := range v.contextRegistry {
+		c.ExecScript(script)
+	}
+}
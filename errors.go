@@ -0,0 +1,70 @@
+package via
+
+import (
+	"errors"
+	"fmt"
+)
+
+// actionTimeoutSignal is the browser signal set to the ID of an action that timed out.
+// Its name starts with an underscore so Datastar does not send it back to the server.
+const actionTimeoutSignal = "_viaTimeout"
+
+// ErrActionTimeout is reported to Options.OnError for actions that exceed their timeout.
+var ErrActionTimeout = errors.New("action timed out")
+
+// Phases in which errors are reported to Options.OnError.
+const (
+	PhaseAction   = "action"
+	PhaseUpload   = "upload"
+	PhaseRender   = "render"
+	PhaseSSE      = "sse"
+	PhaseUpdate   = "update"
+	PhaseAsync    = "async"
+	PhaseJob      = "job"
+	PhaseSchedule = "schedule"
+	PhaseAPI      = "api"
+	PhaseClient   = "client"
+)
+
+// reportErr passes an error to Options.OnError. c is nil for errors outside a context.
+// Panics of the handler are logged.
+func (v *V) reportErr(c *Context, phase string, err error) {
+	if v.cfg.OnError == nil || err == nil {
+		return
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			v.logErr(c, "error handler failed: %v", r)
+		}
+	}()
+	v.cfg.OnError(c, phase, err)
+}
+
+// panicErr converts a recovered panic value to an error.
+func panicErr(r any) error {
+	if err, ok := r.(error); ok {
+		return fmt.Errorf("panic: %w", err)
+	}
+	return fmt.Errorf("panic: %v", r)
+}
+
+// ReportError logs err and passes it to Options.OnError, for failures the page handles
+// itself, e.g. a failed query shown as an empty list. The phase is PhaseAction within an
+// action and PhaseRender elsewhere.
+func (c *Context) ReportError(err error) {
+	if err == nil {
+		return
+	}
+	page := c
+	if c.isComponent() {
+		page = c.parentPageCtx
+	}
+	page.ctxMu.Lock()
+	phase := PhaseRender
+	if page.actionRun != nil {
+		phase = PhaseAction
+	}
+	page.ctxMu.Unlock()
+	c.app.logErr(c, "%v", err)
+	c.app.reportErr(c, phase, err)
+}
@@ -0,0 +1,118 @@
+package via
+
+import (
+	"context"
+	"sync"
+)
+
+// Events is an in-process event bus of the app. Contexts subscribe to topics to be
+// notified of events published by other contexts or by background work, e.g. to show a
+// new bid to all users viewing an auction. Events are not persisted.
+type Events struct {
+	app    *V
+	mu     sync.RWMutex
+	nextID uint64
+	subs   map[string]map[uint64]func(payload any)
+}
+
+// Events returns the event bus of the app.
+func (v *V) Events() *Events {
+	v.eventsOnce.Do(func() {
+		v.events = &Events{app: v, subs: make(map[string]map[uint64]func(any))}
+	})
+	return v.events
+}
+
+func (e *Events) subscribe(topic string, f func(payload any)) func() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.nextID++
+	id := e.nextID
+	if e.subs[topic] == nil {
+		e.subs[topic] = make(map[uint64]func(any))
+	}
+	e.subs[topic][id] = f
+	return func() {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		delete(e.subs[topic], id)
+		if len(e.subs[topic]) == 0 {
+			delete(e.subs, topic)
+		}
+	}
+}
+
+func (e *Events) publish(topic string, payload any) {
+	e.mu.RLock()
+	fns := make([]func(any), 0, len(e.subs[topic]))
+	for _, f := range e.subs[topic] {
+		fns = append(fns, f)
+	}
+	e.mu.RUnlock()
+	for _, f := range fns {
+		f(payload)
+	}
+}
+
+// Topic is a named topic of the event bus with payloads of type T.
+//
+// Example:
+//
+//	var bids = via.NewTopic[Bid]("bids")
+//
+//	v.Page("/auction", func(c *via.Context) {
+//		highest := c.Signal(0)
+//		bids.Subscribe(c, func(b Bid) {
+//			highest.SetValue(b.Amount)
+//		})
+//		bid := c.Action(func() {
+//			bids.Publish(v.Events(), Bid{Amount: 100})
+//		})
+//		// ...
+//	})
+type Topic[T any] struct {
+	name string
+}
+
+// NewTopic returns the topic with the given name. Topics with the same name share
+// subscribers, so their payload types must match.
+func NewTopic[T any](name string) Topic[T] {
+	return Topic[T]{name: name}
+}
+
+// Name returns the name of the topic.
+func (t Topic[T]) Name() string {
+	return t.name
+}
+
+// Publish delivers the payload to the subscribers of the topic. Subscribers run in the
+// calling goroutine, one after another.
+func (t Topic[T]) Publish(e *Events, payload T) {
+	e.publish(t.name, payload)
+}
+
+// Subscribe runs f with the payload of each event published on the topic, then syncs
+// the context to the browser. f runs one at a time with the actions of the context; an
+// event published while one of them runs, e.g. by the action itself, is delivered after
+// it returns. Panics of f are logged. The subscription ends when the
+// context is disposed, e.g. when the page is closed.
+func (t Topic[T]) Subscribe(c *Context, f func(payload T)) {
+	page := c
+	if c.isComponent() {
+		page = c.parentPageCtx
+	}
+	e := c.app.Events()
+	unsubscribe := e.subscribe(t.name, func(payload any) {
+		e.app.applyUpdate(c, func(*Context) { f(payload.(T)) })
+	})
+	page.ctxMu.Lock()
+	life := page.lifeContext()
+	page.ctxMu.Unlock()
+	context.AfterFunc(life, unsubscribe)
+}
+
+// SubscribeFunc runs f with the payload of each event published on the topic, outside
+// of any context, e.g. to record events. It returns a func that ends the subscription.
+func (t Topic[T]) SubscribeFunc(e *Events, f func(payload T)) (unsubscribe func()) {
+	return e.subscribe(t.name, func(payload any) { f(payload.(T)) })
+}
@@ -0,0 +1,97 @@
+package via
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// staticRenderKey marks requests whose pages are rendered without a live connection.
+type staticRenderKey struct{}
+
+// isStaticRender reports whether the page of the request is rendered as static HTML: no
+// context is kept and the page does not open the SSE stream.
+func (v *V) isStaticRender(r *http.Request) bool {
+	if r.Context().Value(staticRenderKey{}) != nil {
+		return true
+	}
+	return v.cfg.StaticSnapshot != nil && v.cfg.StaticSnapshot(r)
+}
+
+// exportWriter captures the response of an exported page.
+type exportWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *exportWriter) Header() http.Header { return w.header }
+
+func (w *exportWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *exportWriter) Write(b []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.body.Write(b)
+}
+
+// Export renders the pages at the given paths to static HTML files in dir, together with
+// the framework assets they load, e.g. to publish docs or marketing pages built from the
+// same components as the app. Pages are rendered once without a live connection, so
+// actions and Sync have no effect; signals and data-* expressions still work in the
+// browser. A path such as '/about' is written to about/index.html.
+//
+// Example:
+//
+//	if err := v.Export("public", "/", "/about", "/posts/hello"); err != nil {
+//		log.Fatal(err)
+//	}
+func (v *V) Export(dir string, paths ...string) error {
+	for _, path := range paths {
+		r, err := http.NewRequestWithContext(context.WithValue(context.Background(), staticRenderKey{}, true), "GET", path, nil)
+		if err != nil {
+			return fmt.Errorf("export %s: %w", path, err)
+		}
+		r.RemoteAddr = "127.0.0.1:0"
+		w := &exportWriter{header: make(http.Header)}
+		v.ServeHTTP(w, r)
+		if w.status != http.StatusOK {
+			return fmt.Errorf("export %s: status %d", path, w.status)
+		}
+		if err := writeExportFile(dir, exportFileName(r.URL.Path), w.body.Bytes()); err != nil {
+			return fmt.Errorf("export %s: %w", path, err)
+		}
+	}
+
+	v.assetsMu.RLock()
+	defer v.assetsMu.RUnlock()
+	for name, a := range v.assets {
+		if err := writeExportFile(dir, filepath.Join(assetsPath, name), a.bodies[""]); err != nil {
+			return fmt.Errorf("export asset %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// exportFileName returns the file an exported page is written to.
+func exportFileName(path string) string {
+	if strings.HasSuffix(path, ".html") {
+		return path
+	}
+	return filepath.Join(path, "index.html")
+}
+
+func writeExportFile(dir, name string, b []byte) error {
+	p := filepath.Join(dir, filepath.FromSlash(filepath.Clean("/"+name)))
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(p, b, 0o644)
+}
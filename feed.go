@@ -0,0 +1,105 @@
+package via
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-via/via/feed"
+)
+
+// defaultFeedTTL is how long a rendered feed is cached by default.
+const defaultFeedTTL = 5 * time.Minute
+
+// maxFeedHosts is the maximum number of base URLs a feed without Meta.Link is cached
+// for. The least recently used is evicted first.
+const maxFeedHosts = 16
+
+type cachedFeed struct {
+	body     []byte
+	etag     string
+	expires  time.Time
+	lastUsed time.Time
+}
+
+// Feed serves an RSS or Atom feed of the items returned by f at the given route. The
+// rendered feed is cached for meta.TTL and served with an ETag, so f is not called on
+// every request. The title defaults to Options.DocumentTitle and the link to the base
+// URL of the request. Set meta.Link to the public URL of the site if the app is served
+// behind a proxy or on several hosts: without it the feed is rendered per base URL and
+// the renderings of at most 16 base URLs are cached.
+//
+// Example:
+//
+//	v.Feed("/blog.xml", func() []feed.Item {
+//		var items []feed.Item
+//		for _, p := range posts {
+//			items = append(items, feed.Item{Title: p.Title, Link: "/posts/" + p.Slug, Published: p.Date})
+//		}
+//		return items
+//	}, feed.Meta{Title: "Blog", Format: feed.Atom})
+func (v *V) Feed(route string, f func() []feed.Item, meta ...feed.Meta) {
+	var m feed.Meta
+	if len(meta) > 0 {
+		m = meta[0]
+	}
+	ttl := m.TTL
+	if ttl <= 0 {
+		ttl = defaultFeedTTL
+	}
+	var mu sync.Mutex
+	cache := make(map[string]cachedFeed) // by base URL
+
+	v.HandleFunc("GET "+route, func(w http.ResponseWriter, r *http.Request) {
+		base := strings.TrimSuffix(m.Link, "/")
+		if base == "" {
+			base = v.baseURL(r)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		cf, ok := cache[base]
+		if !ok || time.Now().After(cf.expires) {
+			fm := m
+			if fm.Title == "" {
+				fm.Title = v.cfg.DocumentTitle
+			}
+			if fm.Link == "" {
+				fm.Link = base
+			}
+			fm.Self = base + r.URL.Path
+			body, err := feed.Render(fm, f())
+			if err != nil {
+				v.logErr(nil, "feed %s failed: %v", route, err)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+			sum := sha256.Sum256(body)
+			cf = cachedFeed{body: body, etag: `"` + hex.EncodeToString(sum[:8]) + `"`, expires: time.Now().Add(ttl)}
+			if _, ok := cache[base]; !ok && len(cache) >= maxFeedHosts {
+				evictFeed(cache)
+			}
+		}
+		cf.lastUsed = time.Now()
+		cache[base] = cf
+		w.Header().Set("Content-Type", m.Format.ContentType())
+		w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(ttl.Seconds())))
+		w.Header().Set("ETag", cf.etag)
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(cf.body))
+	})
+}
+
+// evictFeed removes the least recently used rendering from a feed cache.
+func evictFeed(cache map[string]cachedFeed) {
+	var oldest string
+	for base, cf := range cache {
+		if oldest == "" || cf.lastUsed.Before(cache[oldest].lastUsed) {
+			oldest = base
+		}
+	}
+	delete(cache, oldest)
+}
@@ -0,0 +1,256 @@
+// Package feed renders RSS 2.0 and Atom feeds, see via.V.Feed.
+package feed
+
+import (
+	"encoding/xml"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Format is the format of a feed.
+type Format int
+
+const (
+	// RSS renders an RSS 2.0 feed.
+	RSS Format = iota
+	// Atom renders an Atom 1.0 feed.
+	Atom
+)
+
+// ContentType returns the media type of the format.
+func (f Format) ContentType() string {
+	if f == Atom {
+		return "application/atom+xml; charset=utf-8"
+	}
+	return "application/rss+xml; charset=utf-8"
+}
+
+// Meta describes the feed.
+type Meta struct {
+	// The title of the feed.
+	Title string
+
+	// A short description of the feed.
+	Description string
+
+	// The URL of the site the feed belongs to, e.g. 'https://example.com'. Item links
+	// that are paths are resolved against it.
+	Link string
+
+	// The URL of the feed itself.
+	Self string
+
+	// The author of the feed and, by default, its items.
+	Author string
+
+	// The format of the feed. Default: RSS.
+	Format Format
+
+	// How long the rendered feed is cached and may be cached by clients.
+	// Default: 5 minutes.
+	TTL time.Duration
+}
+
+// Item is an entry of the feed.
+type Item struct {
+	// A unique, permanent ID of the item. Default: the link.
+	ID string
+
+	Title string
+
+	// The URL of the item or a path resolved against Meta.Link.
+	Link string
+
+	// A summary of the item.
+	Description string
+
+	// The full HTML content of the item. Optional.
+	Content string
+
+	// The author of the item. Default: Meta.Author.
+	Author string
+
+	Published time.Time
+
+	// The time the item was last updated. Default: Published.
+	Updated time.Time
+}
+
+// Render returns the feed with the given items in the format of meta.
+func Render(meta Meta, items []Item) ([]byte, error) {
+	items = slices.Clone(items)
+	base := strings.TrimSuffix(meta.Link, "/")
+	for i := range items {
+		if strings.HasPrefix(items[i].Link, "/") {
+			items[i].Link = base + items[i].Link
+		}
+		if items[i].ID == "" {
+			items[i].ID = items[i].Link
+		}
+		if items[i].Author == "" {
+			items[i].Author = meta.Author
+		}
+		if items[i].Updated.IsZero() {
+			items[i].Updated = items[i].Published
+		}
+	}
+
+	var v any
+	if meta.Format == Atom {
+		v = atomFeed(meta, items)
+	} else {
+		v = rssFeed(meta, items)
+	}
+	out, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), out...), nil
+}
+
+type rss struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Self          *atomLink `xml:"atom:link,omitempty"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title,omitempty"`
+	Link        string  `xml:"link,omitempty"`
+	GUID        rssGUID `xml:"guid"`
+	Description string  `xml:"description,omitempty"`
+	Author      string  `xml:"author,omitempty"`
+	PubDate     string  `xml:"pubDate,omitempty"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+func rssFeed(meta Meta, items []Item) rss {
+	ch := rssChannel{Title: meta.Title, Link: meta.Link, Description: meta.Description}
+	if meta.Self != "" {
+		ch.Self = &atomLink{Href: meta.Self, Rel: "self", Type: "application/rss+xml"}
+	}
+	if updated := lastUpdated(items); !updated.IsZero() {
+		ch.LastBuildDate = updated.Format(time.RFC1123Z)
+	}
+	for _, it := range items {
+		item := rssItem{
+			Title:       it.Title,
+			Link:        it.Link,
+			GUID:        rssGUID{IsPermaLink: it.ID == it.Link, Value: it.ID},
+			Description: it.Description,
+			Author:      it.Author,
+		}
+		if it.Content != "" && item.Description == "" {
+			item.Description = it.Content
+		}
+		if !it.Published.IsZero() {
+			item.PubDate = it.Published.Format(time.RFC1123Z)
+		}
+		ch.Items = append(ch.Items, item)
+	}
+	return rss{Version: "2.0", AtomNS: "http://www.w3.org/2005/Atom", Channel: ch}
+}
+
+type atom struct {
+	XMLName xml.Name    `xml:"feed"`
+	NS      string      `xml:"xmlns,attr"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Author  *atomAuthor `xml:"author,omitempty"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomText struct {
+	Type  string `xml:"type,attr,omitempty"`
+	Value string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	Title     string      `xml:"title"`
+	ID        string      `xml:"id"`
+	Link      *atomLink   `xml:"link,omitempty"`
+	Updated   string      `xml:"updated"`
+	Published string      `xml:"published,omitempty"`
+	Author    *atomAuthor `xml:"author,omitempty"`
+	Summary   *atomText   `xml:"summary,omitempty"`
+	Content   *atomText   `xml:"content,omitempty"`
+}
+
+func atomFeed(meta Meta, items []Item) atom {
+	updated := lastUpdated(items)
+	if updated.IsZero() {
+		updated = time.Now()
+	}
+	id := meta.Self
+	if id == "" {
+		id = meta.Link
+	}
+	f := atom{NS: "http://www.w3.org/2005/Atom", Title: meta.Title, ID: id, Updated: updated.UTC().Format(time.RFC3339)}
+	if meta.Link != "" {
+		f.Links = append(f.Links, atomLink{Href: meta.Link})
+	}
+	if meta.Self != "" {
+		f.Links = append(f.Links, atomLink{Href: meta.Self, Rel: "self"})
+	}
+	if meta.Author != "" {
+		f.Author = &atomAuthor{Name: meta.Author}
+	}
+	for _, it := range items {
+		e := atomEntry{Title: it.Title, ID: it.ID, Updated: it.Updated.UTC().Format(time.RFC3339)}
+		if it.Link != "" {
+			e.Link = &atomLink{Href: it.Link}
+		}
+		if !it.Published.IsZero() {
+			e.Published = it.Published.UTC().Format(time.RFC3339)
+		}
+		if it.Author != "" {
+			e.Author = &atomAuthor{Name: it.Author}
+		}
+		if it.Description != "" {
+			e.Summary = &atomText{Value: it.Description}
+		}
+		if it.Content != "" {
+			e.Content = &atomText{Type: "html", Value: it.Content}
+		}
+		f.Entries = append(f.Entries, e)
+	}
+	return f
+}
+
+// lastUpdated returns the latest update time of the items.
+func lastUpdated(items []Item) time.Time {
+	var t time.Time
+	for _, it := range items {
+		if it.Updated.After(t) {
+			t = it.Updated
+		}
+	}
+	return t
+}
@@ -0,0 +1,166 @@
+package via
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/go-via/via/h"
+	"github.com/starfederation/datastar-go/datastar"
+	g "maragu.dev/gomponents"
+)
+
+// SwipeDirection is the direction of a swipe gesture, see OnSwipe.
+type SwipeDirection string
+
+// Directions of swipe gestures.
+const (
+	SwipeLeft  SwipeDirection = "left"
+	SwipeRight SwipeDirection = "right"
+	SwipeUp    SwipeDirection = "up"
+	SwipeDown  SwipeDirection = "down"
+)
+
+// gestureAttr marks elements with gesture triggers, so the browser does not scroll or
+// zoom the page while they are touched.
+const gestureAttr = "via-gesture"
+
+// gestureHead returns the head elements that recognize swipes, long presses and pinches
+// from pointer events and dispatch them as via-swipe-<direction>, via-longpress and
+// via-pinch events on the element the gesture started on. A swipe moves a single
+// pointer at least 50px within a second, a long press holds it still for 500ms and a
+// pinch moves two pointers.
+func gestureHead() []h.H {
+	return []h.H{
+		h.StyleEl(h.Raw(fmt.Sprintf("[%s]{touch-action:none;user-select:none;-webkit-user-select:none}", gestureAttr))),
+		h.Meta(h.Data("init", `(() => {
+		const pts = new Map();
+		let start = null, pinch = null, timer = 0;
+		const fire = (el, name, detail) => el.dispatchEvent(new CustomEvent('via-' + name, {bubbles: true, detail}));
+		const dist = () => { const [a, b] = [...pts.values()]; return Math.hypot(a.x - b.x, a.y - b.y); };
+		document.addEventListener('pointerdown', (evt) => {
+			pts.set(evt.pointerId, {x: evt.clientX, y: evt.clientY});
+			clearTimeout(timer);
+			if (pts.size === 1) {
+				start = {x: evt.clientX, y: evt.clientY, t: Date.now(), el: evt.target};
+				timer = setTimeout(() => { fire(start.el, 'longpress'); start = null; }, 500);
+			} else if (pts.size === 2 && start) {
+				pinch = {d: dist(), el: start.el};
+				start = null;
+			}
+		});
+		document.addEventListener('pointermove', (evt) => {
+			if (!pts.has(evt.pointerId)) return;
+			pts.set(evt.pointerId, {x: evt.clientX, y: evt.clientY});
+			if (start && Math.hypot(evt.clientX - start.x, evt.clientY - start.y) > 10) clearTimeout(timer);
+		});
+		const up = (evt) => {
+			if (!pts.has(evt.pointerId)) return;
+			clearTimeout(timer);
+			if (pinch && pts.size === 2) {
+				fire(pinch.el, 'pinch', {scale: pinch.d ? dist() / pinch.d : 1});
+				pinch = null;
+			}
+			pts.delete(evt.pointerId);
+			if (!start || pts.size > 0) return;
+			const dx = evt.clientX - start.x, dy = evt.clientY - start.y;
+			if (evt.type === 'pointerup' && Date.now() - start.t < 1000 && Math.max(Math.abs(dx), Math.abs(dy)) >= 50) {
+				const dir = Math.abs(dx) > Math.abs(dy) ? (dx > 0 ? 'right' : 'left') : (dy > 0 ? 'down' : 'up');
+				fire(start.el, 'swipe-' + dir, {dx, dy});
+			}
+			start = null;
+		};
+		document.addEventListener('pointerup', up);
+		document.addEventListener('pointercancel', up);
+	})()`)),
+	}
+}
+
+// gestureTrigger returns the attributes of an element that triggers the action on a
+// gesture event.
+func (a *actionTrigger) gestureTrigger(event, expr string) h.H {
+	return gestureAttrs{page: a.page, attrs: []h.H{h.Attr(gestureAttr), h.Data("on:"+event, expr)}}
+}
+
+// gestureAttrs are the attributes of a gesture trigger. Rendering them records that the
+// page uses gestures, so triggers that are built but not rendered, e.g. in h.If, do not
+// load the gesture head.
+type gestureAttrs struct {
+	page  *Context
+	attrs []h.H
+}
+
+func (a gestureAttrs) Render(w io.Writer) error {
+	a.page.useGestures()
+	for _, attr := range a.attrs {
+		if err := attr.Render(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (gestureAttrs) Type() g.NodeType {
+	return g.AttributeType
+}
+
+// useGestures records that the view of the page has gesture triggers. Pages include
+// gestureHead only then; if the page was already sent, the head is patched in.
+func (c *Context) useGestures() {
+	if c.gestures.Swap(true) || !c.headSent.Load() {
+		return
+	}
+	html, err := h.Render(h.Group(gestureHead()...))
+	if err != nil {
+		c.app.logErr(c, "render gesture head failed: %v", err)
+		return
+	}
+	c.sendPatch(patch{typ: patchTypeElements, content: html, selector: "head", mode: datastar.ElementPatchModeAppend})
+}
+
+// OnSwipe returns a via.h DOM attribute that triggers when the element is swiped in the
+// given direction with a finger, pen or mouse. Use it once per direction on an element.
+// The element does not scroll the page while it is touched.
+//
+// Example:
+//
+//	h.Div(next.OnSwipe(via.SwipeLeft), prev.OnSwipe(via.SwipeRight))
+func (a *actionTrigger) OnSwipe(dir SwipeDirection, options ...ActionTriggerOption) h.H {
+	opts := applyOptions(options...)
+	return a.gestureTrigger("via-swipe-"+string(dir), buildOnExpr(a.id, &opts))
+}
+
+// OnSwipeDistance returns a via.h DOM attribute that triggers like OnSwipe. Before the
+// action triggers, the distance the pointer moved during the swipe is written to the
+// dx and dy signals, in CSS pixels: positive to the right and down.
+//
+// Example:
+//
+//	dx, dy := c.Signal(0), c.Signal(0)
+//	h.Div(fling.OnSwipeDistance(via.SwipeLeft, dx, dy))
+func (a *actionTrigger) OnSwipeDistance(dir SwipeDirection, dx, dy *signal, options ...ActionTriggerOption) h.H {
+	opts := applyOptions(options...)
+	return a.gestureTrigger("via-swipe-"+string(dir), fmt.Sprintf("$%s=Math.round(evt.detail.dx);$%s=Math.round(evt.detail.dy);%s",
+		dx.ID(), dy.ID(), buildOnExpr(a.id, &opts)))
+}
+
+// OnLongPress returns a via.h DOM attribute that triggers when the element is pressed
+// for 500ms without moving.
+func (a *actionTrigger) OnLongPress(options ...ActionTriggerOption) h.H {
+	opts := applyOptions(options...)
+	return a.gestureTrigger("via-longpress", buildOnExpr(a.id, &opts))
+}
+
+// OnPinch returns a via.h DOM attribute that triggers when two fingers pinch or spread
+// on the element. Before the action triggers, the ratio of the distance between the
+// fingers at the end and at the start of the gesture, rounded to two decimals, is
+// written to the scale signal: less than 1 for a pinch, more than 1 for a spread.
+//
+// Example:
+//
+//	zoom := c.Signal(1.0)
+//	h.Img(h.Src(url), resize.OnPinch(zoom))
+func (a *actionTrigger) OnPinch(scale *signal, options ...ActionTriggerOption) h.H {
+	opts := applyOptions(options...)
+	return a.gestureTrigger("via-pinch", fmt.Sprintf("$%s=Math.round(evt.detail.scale*100)/100;%s",
+		scale.ID(), buildOnExpr(a.id, &opts)))
+}
@@ -0,0 +1,100 @@
+package via
+
+import (
+	"net"
+	"strings"
+)
+
+// Group is a set of pages under a common route prefix that share init funcs, created
+// with V.Group.
+type Group struct {
+	app    *V
+	host   string
+	prefix string
+	inits  []func(c *Context) bool
+}
+
+// Group creates a group of pages under the given route prefix. The init func runs
+// before the init of each page of the group, e.g. to check authentication, set common
+// state or pick a layout. Returning false aborts the page load: the client is
+// redirected if init called c.Redirect and gets a 403 Forbidden response otherwise.
+// init may be nil.
+//
+// Example:
+//
+//	admin := v.Group("/admin", func(c *via.Context) bool {
+//		if !validSession(c.Cookie("session")) {
+//			c.Redirect("/login")
+//			return false
+//		}
+//		return true
+//	})
+//	admin.Page("/", dashboard)      // GET /admin
+//	admin.Page("/users", users)     // GET /admin/users
+func (v *V) Group(prefix string, init func(c *Context) bool) *Group {
+	g := &Group{app: v, prefix: strings.TrimSuffix(prefix, "/")}
+	if init != nil {
+		g.inits = append(g.inits, init)
+	}
+	return g
+}
+
+// Group creates a nested group under the prefix of g. The init funcs of g run before
+// the given init.
+func (g *Group) Group(prefix string, init func(c *Context) bool) *Group {
+	sub := g.app.Group(g.prefix+prefix, nil)
+	sub.host = g.host
+	sub.inits = append(sub.inits, g.inits...)
+	if init != nil {
+		sub.inits = append(sub.inits, init)
+	}
+	return sub
+}
+
+// Page registers a page of the group like V.Page. The route is relative to the prefix
+// of the group; the route "/" registers the prefix itself.
+func (g *Group) Page(route string, initContextFn func(c *Context)) {
+	g.app.page(g.host, g.route(route), func(c *Context) {
+		for _, init := range g.inits {
+			if !init(c) {
+				c.aborted = true
+				return
+			}
+		}
+		initContextFn(c)
+	})
+}
+
+// Host creates a group of pages served only for requests to the given host, so one app
+// can serve distinct sites, e.g. per tenant. Pages registered with V.Page are served on
+// hosts without a page for the route. http.ServeMux matches hosts exactly, without
+// wildcards or the port. The routes of host pages, e.g. in LiveUpdate.Routes, are
+// prefixed with the host.
+//
+// Example:
+//
+//	admin := v.Host("admin.example.com")
+//	admin.Page("/", dashboard)
+//	v.Page("/", home)
+func (v *V) Host(host string) *Group {
+	return &Group{app: v, host: host}
+}
+
+// route returns the full route of a page of the group.
+func (g *Group) route(route string) string {
+	if route == "/" || route == "" {
+		if g.prefix == "" {
+			return "/"
+		}
+		return g.prefix
+	}
+	return g.prefix + route
+}
+
+// hostWithoutPort returns the host of a Host header without the port.
+func hostWithoutPort(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		return h
+	}
+	return host
+}
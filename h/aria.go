@@ -0,0 +1,111 @@
+package h
+
+import (
+	"strconv"
+	"strings"
+
+	gh "maragu.dev/gomponents/html"
+)
+
+// Aria attributes automatically have their name prefixed with "aria-". Values are escaped;
+// characters that are not valid in attribute names are removed from name.
+//
+// Example:
+//
+//	h.Button(h.Aria("haspopup", "menu"))
+func Aria(name, v string) H {
+	return gh.Aria(attrName(name), v)
+}
+
+func AriaLabel(v string) H {
+	return Aria("label", v)
+}
+
+// AriaLabelledBy references the elements with the given IDs that label this element.
+func AriaLabelledBy(ids ...string) H {
+	return Aria("labelledby", strings.Join(ids, " "))
+}
+
+// AriaDescribedBy references the elements with the given IDs that describe this element.
+func AriaDescribedBy(ids ...string) H {
+	return Aria("describedby", strings.Join(ids, " "))
+}
+
+// AriaControls references the elements with the given IDs controlled by this element.
+func AriaControls(ids ...string) H {
+	return Aria("controls", strings.Join(ids, " "))
+}
+
+// AriaCurrent marks the current item in a set, e.g. "page" or "step".
+func AriaCurrent(v string) H {
+	return Aria("current", v)
+}
+
+// AriaLive sets how updates of the element are announced: "polite", "assertive" or "off".
+func AriaLive(v string) H {
+	return Aria("live", v)
+}
+
+func AriaHasPopup(v string) H {
+	return Aria("haspopup", v)
+}
+
+func AriaHidden(v bool) H {
+	return Aria("hidden", strconv.FormatBool(v))
+}
+
+func AriaExpanded(v bool) H {
+	return Aria("expanded", strconv.FormatBool(v))
+}
+
+func AriaPressed(v bool) H {
+	return Aria("pressed", strconv.FormatBool(v))
+}
+
+func AriaSelected(v bool) H {
+	return Aria("selected", strconv.FormatBool(v))
+}
+
+func AriaChecked(v bool) H {
+	return Aria("checked", strconv.FormatBool(v))
+}
+
+func AriaDisabled(v bool) H {
+	return Aria("disabled", strconv.FormatBool(v))
+}
+
+func AriaBusy(v bool) H {
+	return Aria("busy", strconv.FormatBool(v))
+}
+
+func AriaInvalid(v bool) H {
+	return Aria("invalid", strconv.FormatBool(v))
+}
+
+func AriaModal(v bool) H {
+	return Aria("modal", strconv.FormatBool(v))
+}
+
+// DataAttr binds the attribute with the given name to a Datastar expression, so it is
+// updated in the browser whenever the signals in the expression change. The expression
+// is escaped; characters that are not valid in attribute names are removed from name.
+//
+// Example:
+//
+//	h.Button(h.DataAttr("aria-expanded", "String($open)"))
+func DataAttr(name, expr string) H {
+	return Data("attr:"+attrName(name), expr)
+}
+
+// attrName removes the characters from name that are not valid in attribute names
+// rendered by Via: letters, digits, '-', '_', '.' and ':'.
+func attrName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9',
+			r == '-', r == '_', r == '.', r == ':':
+			return r
+		}
+		return -1
+	}, name)
+}
@@ -336,6 +336,10 @@ func Sup(children ...H) H {
 	return gh.Sup(retype(children)...)
 }
 
+func SVG(children ...H) H {
+	return gh.SVG(retype(children)...)
+}
+
 func Table(children ...H) H {
 	return gh.Table(retype(children)...)
 }
@@ -10,6 +10,8 @@
 package h
 
 import (
+	"bytes"
+	"html/template"
 	"io"
 
 	g "maragu.dev/gomponents"
@@ -42,6 +44,31 @@ func Rawf(format string, a ...any) H {
 	return g.Rawf(format, a...)
 }
 
+// HTMLTemplate creates a DOM node that Renders the html/template with the given data,
+// easing the migration of template-based pages. The output is buffered, so an execution
+// error is returned from Render without writing partial HTML. The name of the template
+// to execute can be given, otherwise tmpl itself is executed.
+//
+// Example:
+//
+//	h.Div(h.HTMLTemplate(legacy, user, "profile"))
+func HTMLTemplate(tmpl *template.Template, data any, name ...string) H {
+	return g.NodeFunc(func(w io.Writer) error {
+		var buf bytes.Buffer
+		var err error
+		if len(name) > 0 {
+			err = tmpl.ExecuteTemplate(&buf, name[0], data)
+		} else {
+			err = tmpl.Execute(&buf, data)
+		}
+		if err != nil {
+			return err
+		}
+		_, err = buf.WriteTo(w)
+		return err
+	})
+}
+
 // Attr creates an attribute DOM [Node] with a name and optional value.
 // If only a name is passed, it's a name-only (boolean) attribute (like "required").
 // If a name and value are passed, it's a name-value attribute (like `class="header"`).
@@ -51,6 +78,12 @@ func Attr(name string, value ...string) H {
 	return g.Attr(name, value...)
 }
 
+// Group combines the given nodes into a single node. Attributes in a group are applied
+// to the element the group is added to.
+func Group(children ...H) H {
+	return g.Group(retype(children))
+}
+
 func If(condition bool, n H) H {
 	if condition {
 		return n
@@ -59,14 +92,19 @@ func If(condition bool, n H) H {
 }
 
 // HTML5Props defines properties for HTML5 pages. Title is set always set, Description
-// and Language elements only if the strings are non-empty.
+// and Language elements only if the strings are non-empty. Nonce is set on the Datastar
+// script if non-empty. DatastarSrc is the URL of the Datastar script, '/_datastar.js' if
+// empty, and DatastarIntegrity its subresource integrity hash.
 type HTML5Props struct {
-	Title       string
-	Description string
-	Language    string
-	Head        []H
-	Body        []H
-	HTMLAttrs   []H
+	Title             string
+	Description       string
+	Language          string
+	Nonce             string
+	DatastarSrc       string
+	DatastarIntegrity string
+	Head              []H
+	Body              []H
+	HTMLAttrs         []H
 }
 
 // HTML5 document template.
@@ -79,7 +117,12 @@ func HTML5(p HTML5Props) H {
 		Body:        retype(p.Body),
 		HTMLAttrs:   retype(p.HTMLAttrs),
 	}
-	gp.Head = append(gp.Head, Script(Type("module"), Src("/_datastar.js")))
+	src := p.DatastarSrc
+	if src == "" {
+		src = "/_datastar.js"
+	}
+	gp.Head = append(gp.Head, Script(Type("module"), Src(src), If(p.Nonce != "", Attr("nonce", p.Nonce)),
+		If(p.DatastarIntegrity != "", Group(Attr("integrity", p.DatastarIntegrity), Attr("crossorigin", "anonymous")))))
 	return gc.HTML5(gp)
 }
 
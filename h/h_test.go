@@ -0,0 +1,32 @@
+package h
+
+import (
+	"bytes"
+	"html/template"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTMLTemplate(t *testing.T) {
+	tmpl := template.Must(template.New("t").Parse(`<b>{{.}}</b>{{define "named"}}<i>{{.}}</i>{{end}}`))
+	tests := []struct {
+		name string
+		node H
+		want string
+	}{
+		{"escapes data", P(HTMLTemplate(tmpl, "a&b")), `<p><b>a&amp;b</b></p>`},
+		{"named template", P(HTMLTemplate(tmpl, "x", "named")), `<p><i>x</i></p>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var w bytes.Buffer
+			assert.NoError(t, tt.node.Render(&w))
+			assert.Equal(t, tt.want, w.String())
+		})
+	}
+
+	var w bytes.Buffer
+	assert.Error(t, HTMLTemplate(tmpl, nil, "missing").Render(&w))
+	assert.Empty(t, w.String())
+}
@@ -0,0 +1,64 @@
+// Package icons provides SVG icons as typed h.H functions. Icons are embedded and
+// rendered inline, so they need no extra font or stylesheet requests and take the color
+// of the surrounding text.
+//
+// Example:
+//
+//	h.Button(icons.Trash(h.Class("w-4 h-4")), h.Text("Delete"))
+//
+// The icons are a subset of Lucide (https://lucide.dev), ISC licensed, see
+// lucide/LICENSE.
+package icons
+
+import (
+	"embed"
+	"path"
+	"strings"
+
+	"github.com/go-via/via/h"
+)
+
+//go:embed lucide/*.svg
+var lucideFS embed.FS
+
+// lucide holds the inner markup of the Lucide icons by name.
+var lucide = loadIcons(lucideFS, "lucide")
+
+func loadIcons(fsys embed.FS, dir string) map[string]string {
+	entries, err := fsys.ReadDir(dir)
+	if err != nil {
+		panic(err)
+	}
+	icons := make(map[string]string, len(entries))
+	for _, e := range entries {
+		b, err := fsys.ReadFile(path.Join(dir, e.Name()))
+		if err != nil {
+			panic(err)
+		}
+		svg := string(b)
+		start := strings.Index(svg, ">") + 1
+		end := strings.LastIndex(svg, "</svg>")
+		icons[strings.TrimSuffix(e.Name(), ".svg")] = strings.TrimSpace(svg[start:end])
+	}
+	return icons
+}
+
+// Lucide renders the Lucide icon with the given name, e.g. 'trash', or nil if there is
+// no such icon. The attributes are added to the svg element. Icons are hidden from
+// assistive technology; label the surrounding element instead.
+func Lucide(name string, attrs ...h.H) h.H {
+	inner, ok := lucide[name]
+	if !ok {
+		return nil
+	}
+	children := []h.H{
+		h.Attr("xmlns", "http://www.w3.org/2000/svg"),
+		h.Attr("width", "24"), h.Attr("height", "24"),
+		h.Attr("viewBox", "0 0 24 24"),
+		h.Attr("fill", "none"), h.Attr("stroke", "currentColor"), h.Attr("stroke-width", "2"),
+		h.Attr("stroke-linecap", "round"), h.Attr("stroke-linejoin", "round"),
+		h.Attr("aria-hidden", "true"),
+	}
+	children = append(children, attrs...)
+	return h.SVG(append(children, h.Raw(inner))...)
+}
@@ -0,0 +1,24 @@
+package icons
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/go-via/via/h"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLucide(t *testing.T) {
+	var b bytes.Buffer
+	assert.NoError(t, Trash(h.Class("w-4")).Render(&b))
+	assert.Equal(t, `<svg xmlns="http://www.w3.org/2000/svg" width="24" height="24" viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2" stroke-linecap="round" stroke-linejoin="round" aria-hidden="true" class="w-4">`+
+		`<path d="M3 6h18"/>
+  <path d="M19 6v14c0 1-1 2-2 2H7c-1 0-2-1-2-2V6"/>
+  <path d="M8 6V4c0-1 1-2 2-2h4c1 0 2 1 2 2v2"/></svg>`, b.String())
+
+	assert.Nil(t, Lucide("no-such-icon"))
+	for name, inner := range lucide {
+		assert.NotEmpty(t, inner, name)
+		assert.NotContains(t, inner, "<svg", name)
+	}
+}
@@ -0,0 +1,188 @@
+package icons
+
+import "github.com/go-via/via/h"
+
+// ArrowLeft renders the Lucide icon 'arrow-left'.
+func ArrowLeft(attrs ...h.H) h.H {
+	return Lucide("arrow-left", attrs...)
+}
+
+// ArrowRight renders the Lucide icon 'arrow-right'.
+func ArrowRight(attrs ...h.H) h.H {
+	return Lucide("arrow-right", attrs...)
+}
+
+// Bell renders the Lucide icon 'bell'.
+func Bell(attrs ...h.H) h.H {
+	return Lucide("bell", attrs...)
+}
+
+// Calendar renders the Lucide icon 'calendar'.
+func Calendar(attrs ...h.H) h.H {
+	return Lucide("calendar", attrs...)
+}
+
+// Check renders the Lucide icon 'check'.
+func Check(attrs ...h.H) h.H {
+	return Lucide("check", attrs...)
+}
+
+// ChevronDown renders the Lucide icon 'chevron-down'.
+func ChevronDown(attrs ...h.H) h.H {
+	return Lucide("chevron-down", attrs...)
+}
+
+// ChevronLeft renders the Lucide icon 'chevron-left'.
+func ChevronLeft(attrs ...h.H) h.H {
+	return Lucide("chevron-left", attrs...)
+}
+
+// ChevronRight renders the Lucide icon 'chevron-right'.
+func ChevronRight(attrs ...h.H) h.H {
+	return Lucide("chevron-right", attrs...)
+}
+
+// ChevronUp renders the Lucide icon 'chevron-up'.
+func ChevronUp(attrs ...h.H) h.H {
+	return Lucide("chevron-up", attrs...)
+}
+
+// CircleAlert renders the Lucide icon 'circle-alert'.
+func CircleAlert(attrs ...h.H) h.H {
+	return Lucide("circle-alert", attrs...)
+}
+
+// Copy renders the Lucide icon 'copy'.
+func Copy(attrs ...h.H) h.H {
+	return Lucide("copy", attrs...)
+}
+
+// Download renders the Lucide icon 'download'.
+func Download(attrs ...h.H) h.H {
+	return Lucide("download", attrs...)
+}
+
+// Ellipsis renders the Lucide icon 'ellipsis'.
+func Ellipsis(attrs ...h.H) h.H {
+	return Lucide("ellipsis", attrs...)
+}
+
+// ExternalLink renders the Lucide icon 'external-link'.
+func ExternalLink(attrs ...h.H) h.H {
+	return Lucide("external-link", attrs...)
+}
+
+// Eye renders the Lucide icon 'eye'.
+func Eye(attrs ...h.H) h.H {
+	return Lucide("eye", attrs...)
+}
+
+// Filter renders the Lucide icon 'filter'.
+func Filter(attrs ...h.H) h.H {
+	return Lucide("filter", attrs...)
+}
+
+// GripVertical renders the Lucide icon 'grip-vertical'.
+func GripVertical(attrs ...h.H) h.H {
+	return Lucide("grip-vertical", attrs...)
+}
+
+// Heart renders the Lucide icon 'heart'.
+func Heart(attrs ...h.H) h.H {
+	return Lucide("heart", attrs...)
+}
+
+// House renders the Lucide icon 'house'.
+func House(attrs ...h.H) h.H {
+	return Lucide("house", attrs...)
+}
+
+// Info renders the Lucide icon 'info'.
+func Info(attrs ...h.H) h.H {
+	return Lucide("info", attrs...)
+}
+
+// LoaderCircle renders the Lucide icon 'loader-circle'.
+func LoaderCircle(attrs ...h.H) h.H {
+	return Lucide("loader-circle", attrs...)
+}
+
+// Lock renders the Lucide icon 'lock'.
+func Lock(attrs ...h.H) h.H {
+	return Lucide("lock", attrs...)
+}
+
+// LogOut renders the Lucide icon 'log-out'.
+func LogOut(attrs ...h.H) h.H {
+	return Lucide("log-out", attrs...)
+}
+
+// Mail renders the Lucide icon 'mail'.
+func Mail(attrs ...h.H) h.H {
+	return Lucide("mail", attrs...)
+}
+
+// Menu renders the Lucide icon 'menu'.
+func Menu(attrs ...h.H) h.H {
+	return Lucide("menu", attrs...)
+}
+
+// Minus renders the Lucide icon 'minus'.
+func Minus(attrs ...h.H) h.H {
+	return Lucide("minus", attrs...)
+}
+
+// Moon renders the Lucide icon 'moon'.
+func Moon(attrs ...h.H) h.H {
+	return Lucide("moon", attrs...)
+}
+
+// Pencil renders the Lucide icon 'pencil'.
+func Pencil(attrs ...h.H) h.H {
+	return Lucide("pencil", attrs...)
+}
+
+// Plus renders the Lucide icon 'plus'.
+func Plus(attrs ...h.H) h.H {
+	return Lucide("plus", attrs...)
+}
+
+// RefreshCw renders the Lucide icon 'refresh-cw'.
+func RefreshCw(attrs ...h.H) h.H {
+	return Lucide("refresh-cw", attrs...)
+}
+
+// Search renders the Lucide icon 'search'.
+func Search(attrs ...h.H) h.H {
+	return Lucide("search", attrs...)
+}
+
+// Star renders the Lucide icon 'star'.
+func Star(attrs ...h.H) h.H {
+	return Lucide("star", attrs...)
+}
+
+// Sun renders the Lucide icon 'sun'.
+func Sun(attrs ...h.H) h.H {
+	return Lucide("sun", attrs...)
+}
+
+// Trash renders the Lucide icon 'trash'.
+func Trash(attrs ...h.H) h.H {
+	return Lucide("trash", attrs...)
+}
+
+// Upload renders the Lucide icon 'upload'.
+func Upload(attrs ...h.H) h.H {
+	return Lucide("upload", attrs...)
+}
+
+// User renders the Lucide icon 'user'.
+func User(attrs ...h.H) h.H {
+	return Lucide("user", attrs...)
+}
+
+// X renders the Lucide icon 'x'.
+func X(attrs ...h.H) h.H {
+	return Lucide("x", attrs...)
+}
@@ -0,0 +1,228 @@
+package h
+
+import "strings"
+
+// indentBlockElements are the elements Indent puts on lines of their own. Other elements
+// and text stay inline, as whitespace between them can change the rendered page.
+var indentBlockElements = map[string]bool{
+	"html": true, "head": true, "body": true, "title": true, "meta": true, "link": true,
+	"script": true, "style": true, "noscript": true, "template": true,
+	"div": true, "p": true, "section": true, "article": true, "aside": true, "header": true,
+	"footer": true, "nav": true, "main": true, "form": true, "fieldset": true, "legend": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "hr": true,
+	"ul": true, "ol": true, "li": true, "dl": true, "dt": true, "dd": true, "pre": true,
+	"table": true, "thead": true, "tbody": true, "tfoot": true, "tr": true, "th": true,
+	"td": true, "caption": true, "figure": true, "figcaption": true, "blockquote": true,
+	"details": true, "summary": true, "dialog": true, "select": true, "option": true,
+}
+
+// indentVoidElements are the elements without a closing tag.
+var indentVoidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true,
+	"img": true, "input": true, "link": true, "meta": true, "source": true, "track": true,
+	"wbr": true,
+}
+
+// indentRawElements are the elements whose content is kept verbatim.
+var indentRawElements = map[string]bool{"script": true, "style": true, "pre": true, "textarea": true}
+
+// Indent formats rendered HTML for reading and diffing: block elements are put on lines of
+// their own and indented by nesting depth. Inline content, whitespace-sensitive elements
+// such as pre, and scripts are kept as they are. Indenting formatted HTML again does not
+// change it.
+func Indent(html string) string {
+	var b strings.Builder
+	b.Grow(len(html) + len(html)/4)
+	depth := 0
+	lineStart := true
+	emptyBlock := false // the last token opened a block that has no content yet
+
+	newline := func() {
+		if !lineStart {
+			b.WriteByte('\n')
+			lineStart = true
+		}
+	}
+	write := func(s string) {
+		if lineStart {
+			b.WriteString(strings.Repeat("  ", depth))
+			lineStart = false
+		}
+		b.WriteString(s)
+	}
+
+	for i := 0; i < len(html); {
+		if html[i] != '<' {
+			end := strings.IndexByte(html[i:], '<')
+			if end < 0 {
+				end = len(html) - i
+			}
+			text := html[i : i+end]
+			i += end
+			if lineStart || emptyBlock {
+				text = strings.TrimLeft(text, " \t\r\n")
+			}
+			if i < len(html) && startsBlock(html, i) {
+				text = strings.TrimRight(text, " \t\r\n")
+			}
+			if text != "" {
+				if emptyBlock {
+					newline()
+					emptyBlock = false
+				}
+				write(text)
+			}
+			continue
+		}
+
+		if strings.HasPrefix(html[i:], "<!--") {
+			end := strings.Index(html[i:], "-->")
+			if end < 0 {
+				end = len(html) - i - 3
+			}
+			newline()
+			write(html[i : i+end+3])
+			newline()
+			i += end + 3
+			emptyBlock = false
+			continue
+		}
+
+		end := tagEnd(html, i)
+		tag := html[i:end]
+		i = end
+		closing := strings.HasPrefix(tag, "</")
+		name := tagName(tag)
+		block := indentBlockElements[name] || strings.HasPrefix(tag, "<!")
+
+		switch {
+		case closing && block:
+			depth = max(depth-1, 0)
+			if !emptyBlock {
+				newline()
+			}
+			write(tag)
+			newline()
+			emptyBlock = false
+		case closing:
+			write(tag)
+			emptyBlock = false
+		case indentRawElements[name]:
+			if block {
+				newline()
+			}
+			closeTag := "</" + name
+			contentEnd := strings.Index(strings.ToLower(html[i:]), closeTag)
+			if contentEnd < 0 {
+				contentEnd = len(html) - i
+			}
+			closeEnd := i + contentEnd
+			if closeEnd < len(html) {
+				closeEnd = tagEnd(html, closeEnd)
+			}
+			write(tag + html[i:closeEnd])
+			i = closeEnd
+			if block {
+				newline()
+			}
+			emptyBlock = false
+		case block:
+			newline()
+			if indentVoidElements[name] || strings.HasPrefix(tag, "<!") || strings.HasSuffix(tag, "/>") {
+				write(tag)
+				newline()
+				emptyBlock = false
+				continue
+			}
+			if end := inlineContentEnd(html, i, name); end >= 0 {
+				// Keep blocks with short inline content on one line.
+				closeStart := strings.LastIndex(html[:end], "</")
+				write(tag + strings.TrimSpace(html[i:closeStart]) + html[closeStart:end])
+				i = end
+				newline()
+				emptyBlock = false
+				continue
+			}
+			write(tag)
+			depth++
+			emptyBlock = true
+		default:
+			if emptyBlock {
+				newline()
+				emptyBlock = false
+			}
+			write(tag)
+		}
+	}
+	return b.String()
+}
+
+// startsBlock reports whether the tag or comment at i starts or ends a line.
+func startsBlock(html string, i int) bool {
+	if strings.HasPrefix(html[i:], "<!") {
+		return true
+	}
+	return indentBlockElements[tagName(html[i:tagEnd(html, i)])]
+}
+
+// inlineContentEnd returns the index after the closing tag of the element with the given
+// name whose content starts at i, if the content is inline and fits on one line.
+// Otherwise it returns -1.
+func inlineContentEnd(html string, i int, name string) int {
+	for j := i; j < len(html); {
+		if html[j] != '<' {
+			end := strings.IndexByte(html[j:], '<')
+			if end < 0 {
+				return -1
+			}
+			if strings.ContainsAny(html[j:j+end], "\r\n") {
+				return -1
+			}
+			j += end
+			continue
+		}
+		end := tagEnd(html, j)
+		tag := html[j:end]
+		n := tagName(tag)
+		switch {
+		case strings.HasPrefix(tag, "<!"), indentRawElements[n]:
+			return -1
+		case indentBlockElements[n]:
+			if strings.HasPrefix(tag, "</") && n == name {
+				return end
+			}
+			return -1
+		}
+		j = end
+	}
+	return -1
+}
+
+// tagEnd returns the index after the '>' that ends the tag starting at i, skipping
+// quoted attribute values.
+func tagEnd(html string, i int) int {
+	var quote byte
+	for j := i + 1; j < len(html); j++ {
+		switch c := html[j]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '>':
+			return j + 1
+		}
+	}
+	return len(html)
+}
+
+// tagName returns the lower-case element name of a tag such as '<div class="x">'.
+func tagName(tag string) string {
+	tag = strings.TrimPrefix(strings.TrimPrefix(tag, "<"), "/")
+	end := strings.IndexAny(tag, " \t\r\n/>")
+	if end < 0 {
+		end = len(tag)
+	}
+	return strings.ToLower(tag[:end])
+}
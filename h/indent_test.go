@@ -0,0 +1,32 @@
+package h
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndent(t *testing.T) {
+	testcases := []struct {
+		desc     string
+		given    string
+		expected string
+	}{
+		{"nested blocks", `<div><ul><li>a</li><li>b</li></ul></div>`, "<div>\n  <ul>\n    <li>a</li>\n    <li>b</li>\n  </ul>\n</div>\n"},
+		{"inline content stays on one line", `<p>Hi <b>there</b> you</p>`, "<p>Hi <b>there</b> you</p>\n"},
+		{"empty block", `<div><div></div></div>`, "<div>\n  <div></div>\n</div>\n"},
+		{"void elements", `<div><input value="a>b"><hr></div>`, "<div>\n  <input value=\"a>b\">\n  <hr>\n</div>\n"},
+		{"pre kept verbatim", "<div><pre>a\n  <b>b</b></pre></div>", "<div>\n  <pre>a\n  <b>b</b></pre>\n</div>\n"},
+		{"script kept verbatim", `<head><script>if(a<b){}</script></head>`, "<head>\n  <script>if(a<b){}</script>\n</head>\n"},
+		{"text and blocks", `<div>text<p>p</p>more</div>`, "<div>\n  text\n  <p>p</p>\n  more\n</div>\n"},
+		{"inline only", `<b>a</b> <i>b</i>`, `<b>a</b> <i>b</i>`},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.desc, func(t *testing.T) {
+			out := Indent(testcase.given)
+			assert.Equal(t, testcase.expected, out)
+			assert.Equal(t, out, Indent(out))
+		})
+	}
+}
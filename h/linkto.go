@@ -0,0 +1,16 @@
+package h
+
+// URLBuilder builds the paths of named routes, e.g. *via.Context.
+type URLBuilder interface {
+	URL(name string, params ...any) string
+}
+
+// LinkTo returns an href attribute that links to the route with the given name and path
+// params, see via.V.PageNamed.
+//
+// Example:
+//
+//	h.A(h.LinkTo(c, "user-detail", user.ID), h.Text(user.Name))
+func LinkTo(b URLBuilder, name string, params ...any) H {
+	return Href(b.URL(name, params...))
+}
@@ -0,0 +1,36 @@
+package h
+
+import (
+	"io"
+
+	"github.com/go-via/via/internal/bufpool"
+)
+
+// Render returns the HTML of the node, e.g. to render fragments for emails, tests or
+// caches. A nil node renders as an empty string.
+func Render(node H) (string, error) {
+	if node == nil {
+		return "", nil
+	}
+	b := bufpool.Get()
+	defer bufpool.Put(b)
+	if err := node.Render(b); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// RenderTo writes the HTML of the node to w. The node is rendered to a buffer first, so
+// nothing is written to w if rendering fails.
+func RenderTo(w io.Writer, node H) error {
+	if node == nil {
+		return nil
+	}
+	b := bufpool.Get()
+	defer bufpool.Put(b)
+	if err := node.Render(b); err != nil {
+		return err
+	}
+	_, err := b.WriteTo(w)
+	return err
+}
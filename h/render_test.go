@@ -0,0 +1,22 @@
+package h
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	html, err := Render(Div(Class("x"), Text("<hi>")))
+	assert.NoError(t, err)
+	assert.Equal(t, `<div class="x">&lt;hi&gt;</div>`, html)
+
+	html, err = Render(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, html)
+
+	var w bytes.Buffer
+	assert.NoError(t, RenderTo(&w, P(Text("a&b"))))
+	assert.Equal(t, `<p>a&amp;b</p>`, w.String())
+}
@@ -0,0 +1,108 @@
+package via
+
+import (
+	"reflect"
+	"sync"
+)
+
+// History records snapshots of signal values so changes can be undone and redone.
+// Create it with *Context.History.
+type History struct {
+	c       *Context
+	signals []*signal
+	limit   int
+	mu      sync.Mutex
+	past    [][]any
+	future  [][]any
+}
+
+// History returns an undo history of the given signals that keeps up to limit steps.
+// The current values are the first snapshot. Call Checkpoint after each change that
+// should be undoable, e.g. at the end of an action.
+//
+// Example:
+//
+//	text := c.Signal("")
+//	hist := c.History(50, text)
+//	save := c.Action(func() { hist.Checkpoint() })
+//	undo := c.Action(func() { hist.Undo() })
+//	redo := c.Action(func() { hist.Redo() })
+func (c *Context) History(limit int, signals ...*signal) *History {
+	hist := &History{c: c, signals: signals, limit: max(limit, 1)}
+	hist.past = [][]any{hist.snapshot()}
+	return hist
+}
+
+func (hist *History) snapshot() []any {
+	vals := make([]any, len(hist.signals))
+	for i, s := range hist.signals {
+		vals[i] = s.val
+	}
+	return vals
+}
+
+func (hist *History) restore(vals []any) {
+	for i, s := range hist.signals {
+		s.SetValue(vals[i])
+	}
+	hist.c.Sync()
+}
+
+// Checkpoint records the current signal values as a new step and clears the steps
+// that could be redone. Nothing is recorded if no value changed since the last step.
+func (hist *History) Checkpoint() {
+	hist.mu.Lock()
+	defer hist.mu.Unlock()
+	snap := hist.snapshot()
+	if reflect.DeepEqual(snap, hist.past[len(hist.past)-1]) {
+		return
+	}
+	hist.past = append(hist.past, snap)
+	if over := len(hist.past) - hist.limit - 1; over > 0 {
+		hist.past = append([][]any(nil), hist.past[over:]...)
+	}
+	hist.future = nil
+}
+
+// Undo restores the signal values of the previous step and syncs the view.
+// Returns false if there is no step to undo.
+func (hist *History) Undo() bool {
+	hist.mu.Lock()
+	defer hist.mu.Unlock()
+	if len(hist.past) < 2 {
+		return false
+	}
+	hist.future = append(hist.future, hist.past[len(hist.past)-1])
+	hist.past = hist.past[:len(hist.past)-1]
+	hist.restore(hist.past[len(hist.past)-1])
+	return true
+}
+
+// Redo restores the signal values of the step last undone and syncs the view.
+// Returns false if there is no step to redo.
+func (hist *History) Redo() bool {
+	hist.mu.Lock()
+	defer hist.mu.Unlock()
+	if len(hist.future) == 0 {
+		return false
+	}
+	snap := hist.future[len(hist.future)-1]
+	hist.future = hist.future[:len(hist.future)-1]
+	hist.past = append(hist.past, snap)
+	hist.restore(snap)
+	return true
+}
+
+// CanUndo reports whether there is a step to undo.
+func (hist *History) CanUndo() bool {
+	hist.mu.Lock()
+	defer hist.mu.Unlock()
+	return len(hist.past) > 1
+}
+
+// CanRedo reports whether there is a step to redo.
+func (hist *History) CanRedo() bool {
+	hist.mu.Lock()
+	defer hist.mu.Unlock()
+	return len(hist.future) > 0
+}
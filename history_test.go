@@ -0,0 +1,39 @@
+package via
+
+import (
+	"testing"
+
+	"github.com/go-via/via/h"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistory(t *testing.T) {
+	c := newContext("ctx", "/", New())
+	c.View(func() h.H { return h.Div() })
+	text := c.Signal("a")
+	hist := c.History(2, text)
+	assert.False(t, hist.CanUndo())
+
+	for _, v := range []string{"b", "b", "c", "d"} {
+		text.SetValue(v)
+		hist.Checkpoint()
+	}
+
+	// the limit keeps the last two steps
+	assert.True(t, hist.Undo())
+	assert.Equal(t, "c", text.String())
+	assert.True(t, hist.Undo())
+	assert.Equal(t, "b", text.String())
+	assert.False(t, hist.Undo())
+
+	assert.True(t, hist.Redo())
+	assert.Equal(t, "c", text.String())
+	assert.True(t, hist.CanRedo())
+
+	// a new step clears the redo steps
+	text.SetValue("x")
+	hist.Checkpoint()
+	assert.False(t, hist.Redo())
+	assert.True(t, hist.Undo())
+	assert.Equal(t, "c", text.String())
+}
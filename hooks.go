@@ -0,0 +1,98 @@
+package via
+
+import "time"
+
+// hooks are the lifecycle hooks registered on the app, e.g. by plugins.
+type hooks struct {
+	beforePage      []func(c *Context) bool
+	afterPageRender []func(c *Context, html []byte, dur time.Duration)
+	beforeAction    []func(c *Context, actionID string) bool
+	afterAction     []func(c *Context, actionID string, dur time.Duration, err error)
+	onSSEConnect    []func(c *Context)
+}
+
+// BeforePage registers a func that runs before the init func of each page load, e.g. to
+// check authentication or record a page view. Returning false aborts the page load like
+// the init of a Group: the client is redirected if f called c.Redirect and gets a 403
+// Forbidden response otherwise. Hooks are registered at setup, typically by a Plugin.
+//
+// Example:
+//
+//	func Analytics(v *via.V) {
+//		v.BeforePage(func(c *via.Context) bool {
+//			pageViews.Add(1)
+//			return true
+//		})
+//	}
+func (v *V) BeforePage(f func(c *Context) bool) {
+	v.hooks.beforePage = append(v.hooks.beforePage, f)
+}
+
+// AfterPageRender registers a func that runs after each page load is rendered with the
+// HTML document and the time the render took. The HTML must not be modified.
+func (v *V) AfterPageRender(f func(c *Context, html []byte, dur time.Duration)) {
+	v.hooks.afterPageRender = append(v.hooks.afterPageRender, f)
+}
+
+// BeforeAction registers a func that runs before each action with the action ID, and
+// before each upload with the upload ID. Returning false rejects the action or upload
+// with a 403 Forbidden response.
+func (v *V) BeforeAction(f func(c *Context, actionID string) bool) {
+	v.hooks.beforeAction = append(v.hooks.beforeAction, f)
+}
+
+// AfterAction registers a func that runs after each action or upload with its ID, the
+// time it took and the error of a panic or ErrActionTimeout, if any. For an action
+// that times out, the hook runs when the timeout is reached.
+func (v *V) AfterAction(f func(c *Context, actionID string, dur time.Duration, err error)) {
+	v.hooks.afterAction = append(v.hooks.afterAction, f)
+}
+
+// OnSSEConnect registers a func that runs when the browser of a context opens the SSE
+// stream, which happens after each page load and reconnect.
+func (v *V) OnSSEConnect(f func(c *Context)) {
+	v.hooks.onSSEConnect = append(v.hooks.onSSEConnect, f)
+}
+
+// runBeforePage runs the BeforePage hooks and reports whether the page load continues.
+func (v *V) runBeforePage(c *Context) bool {
+	for _, f := range v.hooks.beforePage {
+		if !f(c) {
+			c.aborted = true
+			return false
+		}
+	}
+	return true
+}
+
+func (v *V) runAfterPageRender(c *Context, html string, dur time.Duration) {
+	if len(v.hooks.afterPageRender) == 0 {
+		return
+	}
+	b := []byte(html)
+	for _, f := range v.hooks.afterPageRender {
+		f(c, b, dur)
+	}
+}
+
+// runBeforeAction runs the BeforeAction hooks and reports whether the action runs.
+func (v *V) runBeforeAction(c *Context, actionID string) bool {
+	for _, f := range v.hooks.beforeAction {
+		if !f(c, actionID) {
+			return false
+		}
+	}
+	return true
+}
+
+func (v *V) runAfterAction(c *Context, actionID string, dur time.Duration, err error) {
+	for _, f := range v.hooks.afterAction {
+		f(c, actionID, dur, err)
+	}
+}
+
+func (v *V) runOnSSEConnect(c *Context) {
+	for _, f := range v.hooks.onSSEConnect {
+		f(c)
+	}
+}
@@ -0,0 +1,30 @@
+package via
+
+import "github.com/go-via/via/h"
+
+// HTMLFormat selects how the HTML of pages and element patches is formatted.
+type HTMLFormat int
+
+const (
+	htmlFormatUndefined HTMLFormat = iota
+	// HTMLFormatAuto indents HTML in DevMode and keeps it compact otherwise.
+	HTMLFormatAuto
+	// HTMLFormatPretty indents HTML, see h.Indent.
+	HTMLFormatPretty
+	// HTMLFormatCompact emits HTML as rendered.
+	HTMLFormatCompact
+)
+
+// formatHTML returns the HTML in the configured format.
+func (v *V) formatHTML(html string) string {
+	switch v.cfg.HTMLFormat {
+	case HTMLFormatPretty:
+	case HTMLFormatCompact:
+		return html
+	default:
+		if !v.cfg.DevMode {
+			return html
+		}
+	}
+	return h.Indent(html)
+}
@@ -0,0 +1,50 @@
+package via
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync/atomic"
+)
+
+// genID returns an ID for actions, signals, components, uploads and jobs, made by
+// Options.IDGenerator if set.
+func (v *V) genID() string {
+	if v.cfg.IDGenerator != nil {
+		return v.cfg.IDGenerator()
+	}
+	return genRandID()
+}
+
+// NewID returns a new ID that is unique within the app, e.g. for the DOM elements of
+// components and plugins. It is made by Options.IDGenerator if set, so IDs in rendered
+// HTML are reproducible in tests.
+func (v *V) NewID() string {
+	return v.genID()
+}
+
+// NewID returns a new ID that is unique within the app, see V.NewID.
+func (c *Context) NewID() string {
+	return c.app.genID()
+}
+
+// genContextID returns the random part of a context ID, made by Options.IDGenerator if
+// set. Context IDs authorize SSE and action requests, so by default they hold 128 bits
+// of entropy to keep them from being guessed.
+func (v *V) genContextID() string {
+	if v.cfg.IDGenerator != nil {
+		return v.cfg.IDGenerator()
+	}
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// SequentialIDs returns an ID generator for Options.IDGenerator that counts up from 1,
+// e.g. '00000001', so the IDs in rendered HTML are reproducible in tests.
+func SequentialIDs() func() string {
+	var n atomic.Uint64
+	return func() string {
+		return fmt.Sprintf("%08d", n.Add(1))
+	}
+}
@@ -0,0 +1,28 @@
+// Package bufpool pools the buffers that views, patches and fragments are rendered to,
+// so frequent renders do not grow a fresh buffer each time.
+package bufpool
+
+import (
+	"bytes"
+	"sync"
+)
+
+// maxPooledBuffer is the capacity above which buffers are not reused, so a single large
+// render does not pin its memory.
+const maxPooledBuffer = 256 << 10
+
+var pool = sync.Pool{New: func() any { return new(bytes.Buffer) }}
+
+// Get returns an empty buffer.
+func Get() *bytes.Buffer {
+	return pool.Get().(*bytes.Buffer)
+}
+
+// Put returns a buffer to the pool. The buffer must not be used afterwards.
+func Put(b *bytes.Buffer) {
+	if b.Cap() > maxPooledBuffer {
+		return
+	}
+	b.Reset()
+	pool.Put(b)
+}
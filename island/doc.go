@@ -0,0 +1,18 @@
+// Package island exports Go funcs to the browser as compute islands of a Via app, see
+// via.V.Islands and via.Context.Compute. It is used in a main package built with
+// GOOS=js GOARCH=wasm:
+//
+//	package main
+//
+//	import "github.com/go-via/via/island"
+//
+//	func main() {
+//		island.Export("slugify", func(args ...any) any { return text.Slugify(args[0].(string)) })
+//		island.Run()
+//	}
+//
+// Args are strings, float64 numbers, bools or nil, as in the signals of the browser.
+// Results are converted like js.ValueOf.
+//
+// Experimental: the API may change.
+package island
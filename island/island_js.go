@@ -0,0 +1,37 @@
+//go:build js && wasm
+
+package island
+
+import "syscall/js"
+
+// Export registers fn as the compute island func with the given name.
+func Export(name string, fn func(args ...any) any) {
+	js.Global().Get("viaIslands").Call("register", name, js.FuncOf(func(this js.Value, jsArgs []js.Value) any {
+		args := make([]any, len(jsArgs))
+		for i, a := range jsArgs {
+			args[i] = goValue(a)
+		}
+		return js.ValueOf(fn(args...))
+	}))
+}
+
+// Run keeps the module running so the exported funcs can be called. It does not return.
+func Run() {
+	select {}
+}
+
+// goValue converts a JS value to a string, float64, bool or nil. Other values are
+// converted to their string form.
+func goValue(v js.Value) any {
+	switch v.Type() {
+	case js.TypeString:
+		return v.String()
+	case js.TypeNumber:
+		return v.Float()
+	case js.TypeBoolean:
+		return v.Bool()
+	case js.TypeNull, js.TypeUndefined:
+		return nil
+	}
+	return v.String()
+}
@@ -0,0 +1,132 @@
+package via
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/go-via/via/h"
+)
+
+// islandNameRe matches the valid names of compute islands.
+var islandNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// islandsLoader instantiates the WASM module of the compute islands in the browser. The
+// module registers its funcs with window.viaIslands.register, see package island.
+const islandsLoader = `const fns = new Map();
+window.viaIslands = {
+	register: (name, fn) => { fns.set(name, fn); },
+	has: (name) => fns.has(name),
+	call: (name, ...args) => fns.get(name)(...args),
+};
+if (typeof WebAssembly === 'object') {
+	const s = document.createElement('script');
+	s.src = %q;
+	s.onload = async () => {
+		try {
+			const go = new Go();
+			const { instance } = await WebAssembly.instantiateStreaming(fetch(%q), go.importObject);
+			go.run(instance);
+		} catch (err) {
+			console.warn('via: compute islands unavailable, using server actions', err);
+		}
+	};
+	document.head.appendChild(s);
+}
+`
+
+// Islands serves a WASM module of compute islands, pure funcs authored in Go that pages
+// run in the browser for instant feedback, see Context.Compute. The module is built
+// from a main package that exports the funcs with package island:
+//
+//	GOOS=js GOARCH=wasm go build -o islands.wasm ./islands
+//
+// wasmExec is the wasm_exec.js support script of the Go version the module is built
+// with, found in $(go env GOROOT)/lib/wasm. Browsers without WebAssembly, or pages whose
+// CSP does not allow 'wasm-unsafe-eval', run the server fallbacks instead.
+//
+// Experimental: the API may change.
+func (v *V) Islands(module, wasmExec []byte) {
+	execAsset := newAsset("wasm_exec.js", "application/javascript", wasmExec)
+	moduleAsset := newAsset("islands.wasm", "application/wasm", module)
+	loader := newAsset("islands.js", "application/javascript",
+		fmt.Appendf(nil, islandsLoader, execAsset.url(), moduleAsset.url()))
+	v.setAsset(nil, execAsset)
+	v.setAsset(nil, moduleAsset)
+	v.setAsset(&v.islandsAsset, loader)
+}
+
+// islandsScript returns the script that loads the compute islands, or nil if there
+// are none.
+func (v *V) islandsScript(nonce string) h.H {
+	loader := v.loadAsset(&v.islandsAsset)
+	if loader == nil {
+		return nil
+	}
+	return h.Script(h.Type("module"), h.If(nonce != "", h.Attr("nonce", nonce)), h.Src(loader.url()))
+}
+
+// compute is a func of the compute islands that updates a signal, see Context.Compute.
+type compute struct {
+	name   string
+	action *actionTrigger
+	target *signal
+	args   []*signal
+}
+
+// Compute binds the compute island func with the given name to a signal: target is set
+// to the result of the func called with the values of the args signals. In the browser
+// the func runs in the WASM module served with V.Islands. fn is the same func run on the
+// server, as an action, when the module is not available. It should be pure, i.e.
+// depend on its args only.
+//
+// Experimental: the API may change.
+//
+// Example:
+//
+//	title, slug := c.Signal(""), c.Signal("")
+//	slugify := c.Compute("slugify", func(args ...any) any { return text.Slugify(fmt.Sprint(args[0])) }, slug, title)
+//	c.View(func() h.H {
+//		return h.Div(h.Input(title.Bind(), slugify.OnInput()), slug.Text())
+//	})
+func (c *Context) Compute(name string, fn func(args ...any) any, target *signal, args ...*signal) *compute {
+	if !islandNameRe.MatchString(name) {
+		panic(fmt.Sprintf("invalid compute island name %q", name))
+	}
+	comp := &compute{name: name, target: target, args: args}
+	comp.action = c.Action(func() {
+		vals := make([]any, len(args))
+		for i, arg := range args {
+			vals[i] = arg.val
+		}
+		target.SetValue(fn(vals...))
+		c.SyncSignal(target)
+	})
+	return comp
+}
+
+// expr returns the Datastar expression that runs the func in the browser, or the
+// server action if the WASM module is not loaded.
+func (comp *compute) expr() string {
+	args := make([]string, len(comp.args))
+	for i, arg := range comp.args {
+		args[i] = "$" + arg.id
+	}
+	return fmt.Sprintf("window.viaIslands?.has('%[1]s') ? ($%[2]s = viaIslands.call('%[1]s', %[3]s)) : %[4]s",
+		comp.name, comp.target.id, strings.Join(args, ", "), actionURL(comp.action.id))
+}
+
+// OnInput runs the func when the value of the element changes while typing.
+func (comp *compute) OnInput() h.H {
+	return comp.OnEvent("input")
+}
+
+// OnChange runs the func when the element commits its value.
+func (comp *compute) OnChange() h.H {
+	return comp.OnEvent("change")
+}
+
+// OnEvent runs the func on the given event of the element.
+func (comp *compute) OnEvent(event string) h.H {
+	return h.Data("on:"+event, comp.expr())
+}
@@ -0,0 +1,251 @@
+package via
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultJobConcurrency is the number of jobs run at the same time.
+	defaultJobConcurrency = 4
+	// jobRetention is how long finished jobs are kept, see Jobs.Remove.
+	jobRetention = time.Hour
+)
+
+// JobStatus is the state of a Job.
+type JobStatus string
+
+const (
+	JobQueued  JobStatus = "queued"
+	JobRunning JobStatus = "running"
+	JobDone    JobStatus = "done"
+	JobFailed  JobStatus = "failed"
+)
+
+// JobWorker runs a job. ctx is canceled when the app shuts down. Report progress
+// with job.SetProgress; the returned value is the result of the job.
+type JobWorker func(ctx context.Context, job *Job) (any, error)
+
+// Job is a unit of background work enqueued with Jobs.Enqueue.
+type Job struct {
+	ID      string
+	Name    string
+	Payload any
+
+	jobs      *Jobs
+	contextID string
+	mu        sync.RWMutex
+	status    JobStatus
+	progress  float64
+	result    any
+	err       error
+	finished  time.Time
+}
+
+// Status returns the state of the job.
+func (j *Job) Status() JobStatus {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return j.status
+}
+
+// Progress returns the progress of the job between 0 and 1.
+func (j *Job) Progress() float64 {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return j.progress
+}
+
+// Result returns the value returned by the worker of a finished job.
+func (j *Job) Result() any {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return j.result
+}
+
+// Err returns the error of a failed job or nil.
+func (j *Job) Err() error {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return j.err
+}
+
+// SetProgress reports the progress of the job between 0 and 1. The context the job
+// was enqueued for is synced to show it.
+func (j *Job) SetProgress(p float64) {
+	j.mu.Lock()
+	j.progress = min(max(p, 0), 1)
+	j.mu.Unlock()
+	j.notify()
+}
+
+func (j *Job) finish(result any, err error) {
+	j.mu.Lock()
+	j.result, j.err = result, err
+	j.finished = time.Now()
+	j.status = JobDone
+	if err != nil {
+		j.status = JobFailed
+	} else {
+		j.progress = 1
+	}
+	j.mu.Unlock()
+	j.notify()
+}
+
+// notify syncs the context the job was enqueued for, if it is still live.
+func (j *Job) notify() {
+	if j.contextID != "" {
+		_ = j.jobs.app.NotifySession(j.contextID, nil)
+	}
+}
+
+// Jobs is an in-memory queue of background jobs, run by the workers registered for
+// their names. Jobs do not survive a restart of the app.
+type Jobs struct {
+	app     *V
+	mu      sync.RWMutex
+	workers map[string]JobWorker
+	jobs    map[string]*Job
+	slots   chan struct{}
+	ctx     context.Context
+	// retention is how long finished jobs are kept
+	retention time.Duration
+}
+
+// Jobs returns the job queue of the app.
+//
+// Example:
+//
+//	v.Jobs().Register("export", func(ctx context.Context, job *via.Job) (any, error) {
+//		for i := range 10 {
+//			job.SetProgress(float64(i) / 10)
+//			// ...
+//		}
+//		return "export.csv", nil
+//	})
+func (v *V) Jobs() *Jobs {
+	v.jobsOnce.Do(func() {
+		v.jobs = &Jobs{
+			app:       v,
+			workers:   make(map[string]JobWorker),
+			jobs:      make(map[string]*Job),
+			slots:     make(chan struct{}, defaultJobConcurrency),
+			ctx:       v.baseCtx,
+			retention: jobRetention,
+		}
+	})
+	return v.jobs
+}
+
+// Register sets the worker that runs jobs with the given name.
+func (q *Jobs) Register(name string, w JobWorker) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.workers[name] = w
+}
+
+// Enqueue queues a job with the given name and payload and returns it.
+// Returns an error if no worker is registered for the name.
+func (q *Jobs) Enqueue(name string, payload any) (*Job, error) {
+	return q.enqueue("", name, payload)
+}
+
+// EnqueueFor queues a job like Enqueue and syncs the given context whenever the job
+// reports progress or finishes, so its view can show the job live, e.g. with
+// ui.JobProgress.
+func (q *Jobs) EnqueueFor(c *Context, name string, payload any) (*Job, error) {
+	if c.isComponent() {
+		c = c.parentPageCtx
+	}
+	return q.enqueue(c.id, name, payload)
+}
+
+// Get returns the job with the given ID.
+func (q *Jobs) Get(id string) (*Job, bool) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	j, ok := q.jobs[id]
+	return j, ok
+}
+
+// Remove forgets a finished job. Finished jobs are kept for an hour so their results
+// can be read later, then removed automatically.
+func (q *Jobs) Remove(id string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if j, ok := q.jobs[id]; ok && (j.Status() == JobDone || j.Status() == JobFailed) {
+		delete(q.jobs, id)
+	}
+}
+
+func (q *Jobs) enqueue(contextID, name string, payload any) (*Job, error) {
+	q.mu.Lock()
+	worker, ok := q.workers[name]
+	if !ok {
+		q.mu.Unlock()
+		return nil, fmt.Errorf("job '%s': no worker registered", name)
+	}
+	q.prune()
+	job := &Job{ID: q.app.genID(), Name: name, Payload: payload, jobs: q, contextID: contextID, status: JobQueued}
+	q.jobs[job.ID] = job
+	q.mu.Unlock()
+
+	go q.run(job, worker)
+	return job, nil
+}
+
+// prune removes the jobs that finished longer than the retention period ago. q.mu must
+// be held.
+func (q *Jobs) prune() {
+	cutoff := time.Now().Add(-q.retention)
+	for id, j := range q.jobs {
+		j.mu.RLock()
+		expired := !j.finished.IsZero() && j.finished.Before(cutoff)
+		j.mu.RUnlock()
+		if expired {
+			delete(q.jobs, id)
+		}
+	}
+}
+
+// run runs the job once a slot is free.
+func (q *Jobs) run(job *Job, worker JobWorker) {
+	select {
+	case q.slots <- struct{}{}:
+	case <-q.ctx.Done():
+		job.finish(nil, q.ctx.Err())
+		return
+	}
+	defer func() { <-q.slots }()
+
+	job.mu.Lock()
+	job.status = JobRunning
+	job.mu.Unlock()
+	job.notify()
+
+	result, err := func() (result any, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = panicErr(r)
+			}
+		}()
+		return worker(q.ctx, job)
+	}()
+	if err != nil {
+		q.app.logErr(nil, "job '%s' (%s) failed: %v", job.Name, job.ID, err)
+		q.app.reportErr(q.app.jobContext(job), PhaseJob, err)
+	}
+	job.finish(result, err)
+}
+
+// jobContext returns the live context the job was enqueued for, or nil.
+func (v *V) jobContext(job *Job) *Context {
+	if job.contextID == "" {
+		return nil
+	}
+	c, _ := v.getCtx(job.contextID)
+	return c
+}
@@ -0,0 +1,465 @@
+// Package loadtest simulates clients of a running Via app to measure its capacity. Each
+// client loads a page, connects its SSE stream and runs a script of actions, while the
+// latencies of page loads, SSE connects and actions are collected into percentiles.
+//
+// Example:
+//
+//	report, err := loadtest.Run(ctx, loadtest.Config{
+//		URL:        "http://localhost:3000/counter",
+//		Clients:    200,
+//		Iterations: 50,
+//		Script: func(s *loadtest.Session) error {
+//			if err := s.Action(0, nil); err != nil { // the first action of the page
+//				return err
+//			}
+//			return s.WaitSignal(func(sigs map[string]any) bool { return len(sigs) > 0 }, time.Second)
+//		},
+//	})
+//	fmt.Println(report)
+package loadtest
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Config configures a load test.
+type Config struct {
+	// The URL of the page loaded by each client.
+	URL string
+
+	// The number of simulated clients. Default: 1.
+	Clients int
+
+	// The time over which clients are started, spreading their page loads. Default: 0,
+	// all clients start at once.
+	RampUp time.Duration
+
+	// The number of times each client runs Script. Default: 1.
+	Iterations int
+
+	// The actions of a client, run after its SSE stream is connected. Returned errors
+	// are counted and end the iterations of the client.
+	Script func(s *Session) error
+
+	// The HTTP client used by the simulated clients, e.g. with a timeout or TLS config.
+	// Each client gets its own cookie jar. Default: http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// Latencies are percentiles of measured durations.
+type Latencies struct {
+	Count int
+	P50   time.Duration
+	P90   time.Duration
+	P99   time.Duration
+	Max   time.Duration
+}
+
+func (l Latencies) String() string {
+	return fmt.Sprintf("n=%d p50=%s p90=%s p99=%s max=%s", l.Count, l.P50, l.P90, l.P99, l.Max)
+}
+
+// Report is the result of a load test.
+type Report struct {
+	Clients  int
+	Duration time.Duration
+	// Page loads, from request to complete response.
+	Page Latencies
+	// SSE connects, from request to the response headers.
+	Connect Latencies
+	// Actions, from request to response.
+	Action Latencies
+	// The errors of failed clients.
+	Errors []error
+}
+
+func (r *Report) String() string {
+	return fmt.Sprintf("clients=%d duration=%s errors=%d\npage    %s\nconnect %s\naction  %s",
+		r.Clients, r.Duration.Round(time.Millisecond), len(r.Errors), r.Page, r.Connect, r.Action)
+}
+
+// Run runs a load test and returns its report once all clients finished or ctx is done.
+// An error is returned for an invalid config only; failures of clients are reported
+// in Report.Errors.
+func Run(ctx context.Context, cfg Config) (*Report, error) {
+	pageURL, err := url.Parse(cfg.URL)
+	if err != nil || pageURL.Host == "" {
+		return nil, fmt.Errorf("invalid URL %q", cfg.URL)
+	}
+	clients := max(cfg.Clients, 1)
+	iterations := max(cfg.Iterations, 1)
+	var rec recorder
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i := range clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if cfg.RampUp > 0 {
+				select {
+				case <-time.After(cfg.RampUp * time.Duration(i) / time.Duration(clients)):
+				case <-ctx.Done():
+					return
+				}
+			}
+			if err := runClient(ctx, cfg, pageURL, iterations, &rec); err != nil {
+				rec.fail(fmt.Errorf("client %d: %w", i, err))
+			}
+		}()
+	}
+	wg.Wait()
+	return rec.report(clients, time.Since(start)), nil
+}
+
+// runClient runs a simulated client.
+func runClient(ctx context.Context, cfg Config, pageURL *url.URL, iterations int, rec *recorder) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	s, err := newSession(ctx, cfg.HTTPClient, pageURL, rec)
+	if err != nil {
+		return err
+	}
+	defer s.close()
+	if cfg.Script == nil {
+		return nil
+	}
+	for range iterations {
+		if err := cfg.Script(s); err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+	}
+	return nil
+}
+
+// Session is a simulated client with a loaded page and a connected SSE stream.
+type Session struct {
+	ctx       context.Context
+	client    *http.Client
+	base      *url.URL
+	contextID string
+	actions   []string
+	rec       *recorder
+
+	mu      sync.Mutex
+	signals map[string]any
+	changed chan struct{} // closed and replaced on each signal patch
+	body    io.Closer
+	err     error
+}
+
+var (
+	contextIDRe = regexp.MustCompile(`'via-ctx':'([^']+)'`)
+	actionRe    = regexp.MustCompile(`/_action/([A-Za-z0-9]+)`)
+)
+
+func newSession(ctx context.Context, client *http.Client, pageURL *url.URL, rec *recorder) (*Session, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	jar, _ := cookiejar.New(nil)
+	c := *client
+	c.Jar = jar
+	s := &Session{
+		ctx:     ctx,
+		client:  &c,
+		base:    pageURL,
+		rec:     rec,
+		signals: make(map[string]any),
+		changed: make(chan struct{}),
+	}
+
+	start := time.Now()
+	page, err := s.get(pageURL.String())
+	if err != nil {
+		return nil, fmt.Errorf("load page: %w", err)
+	}
+	rec.add(&rec.page, time.Since(start))
+	doc := html.UnescapeString(string(page))
+	m := contextIDRe.FindStringSubmatch(doc)
+	if m == nil {
+		return nil, errors.New("load page: no via context in page")
+	}
+	s.contextID = m[1]
+	for _, a := range actionRe.FindAllStringSubmatch(doc, -1) {
+		if !slices.Contains(s.actions, a[1]) {
+			s.actions = append(s.actions, a[1])
+		}
+	}
+
+	if err := s.connect(); err != nil {
+		return nil, fmt.Errorf("connect SSE: %w", err)
+	}
+	return s, nil
+}
+
+// datastarURL returns the URL of the path with the context ID and signals as the
+// datastar query parameter, as sent by Datastar.
+func (s *Session) datastarURL(path string, signals map[string]any) string {
+	sigs := map[string]any{"via-ctx": s.contextID}
+	for id, v := range signals {
+		sigs[id] = v
+	}
+	j, _ := json.Marshal(sigs)
+	u := *s.base
+	u.Path, u.RawQuery = path, url.Values{"datastar": {string(j)}}.Encode()
+	return u.String()
+}
+
+func (s *Session) get(u string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(s.ctx, "GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("GET %s: %s", u, resp.Status)
+	}
+	return body, nil
+}
+
+// connect opens the SSE stream and reads its events in the background.
+func (s *Session) connect() error {
+	start := time.Now()
+	req, err := http.NewRequestWithContext(s.ctx, "GET", s.datastarURL("/_sse", nil), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return fmt.Errorf("GET /_sse: %s", resp.Status)
+	}
+	s.rec.add(&s.rec.connect, time.Since(start))
+	s.body = resp.Body
+	r := bufio.NewReader(resp.Body)
+	go func() {
+		for {
+			if err := s.readEvent(r); err != nil {
+				s.mu.Lock()
+				s.err = err
+				close(s.changed)
+				s.changed = make(chan struct{})
+				s.mu.Unlock()
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// readEvent reads an SSE event and applies signal patches to the signals of the session.
+func (s *Session) readEvent(r *bufio.Reader) error {
+	var event string
+	var data []string
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		switch {
+		case line == "":
+			if event == "" {
+				continue
+			}
+			if event == "datastar-patch-signals" {
+				var patch map[string]any
+				if err := json.Unmarshal([]byte(strings.Join(data, "\n")), &patch); err != nil {
+					return fmt.Errorf("invalid signal patch: %w", err)
+				}
+				s.mu.Lock()
+				mergePatch(s.signals, patch)
+				close(s.changed)
+				s.changed = make(chan struct{})
+				s.mu.Unlock()
+			}
+			return nil
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: signals "):
+			data = append(data, strings.TrimPrefix(line, "data: signals "))
+		}
+	}
+}
+
+// mergePatch applies a JSON merge patch to the signals.
+func mergePatch(dst, patch map[string]any) {
+	for k, v := range patch {
+		if v == nil {
+			delete(dst, k)
+			continue
+		}
+		if p, ok := v.(map[string]any); ok {
+			d, ok := dst[k].(map[string]any)
+			if !ok {
+				d = make(map[string]any)
+				dst[k] = d
+			}
+			mergePatch(d, p)
+			continue
+		}
+		dst[k] = v
+	}
+}
+
+// close closes the SSE stream and the context of the page, like a browser leaving the
+// page.
+func (s *Session) close() {
+	if s.body != nil {
+		s.body.Close()
+	}
+	u := *s.base
+	u.Path, u.RawQuery = "/_session/close", ""
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(s.ctx), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "POST", u.String(), strings.NewReader(s.contextID))
+	if err != nil {
+		return
+	}
+	if resp, err := s.client.Do(req); err == nil {
+		resp.Body.Close()
+	}
+}
+
+// ContextID returns the ID of the context of the page.
+func (s *Session) ContextID() string {
+	return s.contextID
+}
+
+// Actions returns the IDs of the actions triggered by the page, in the order they
+// appear in the page.
+func (s *Session) Actions() []string {
+	return s.actions
+}
+
+// Action triggers the action with the given index in Actions, sending the given
+// signals, like a click in the browser. The latency is recorded when the action
+// responds.
+func (s *Session) Action(idx int, signals map[string]any) error {
+	if idx < 0 || idx >= len(s.actions) {
+		return fmt.Errorf("action %d not found in page with %d actions", idx, len(s.actions))
+	}
+	return s.ActionID(s.actions[idx], signals)
+}
+
+// ActionID triggers the action with the given ID, see Action.
+func (s *Session) ActionID(id string, signals map[string]any) error {
+	start := time.Now()
+	if _, err := s.get(s.datastarURL("/_action/"+id, signals)); err != nil {
+		return fmt.Errorf("action %s: %w", id, err)
+	}
+	s.rec.add(&s.rec.action, time.Since(start))
+	return nil
+}
+
+// Signals returns a copy of the signals sent to the session by the server.
+func (s *Session) Signals() map[string]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sigs := make(map[string]any, len(s.signals))
+	for k, v := range s.signals {
+		sigs[k] = v
+	}
+	return sigs
+}
+
+// WaitSignal waits until the signals sent by the server satisfy the condition, e.g. to
+// assert the result of an action. It fails when the timeout passes first.
+func (s *Session) WaitSignal(cond func(signals map[string]any) bool, timeout time.Duration) error {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		s.mu.Lock()
+		ok, changed, err := cond(s.signals), s.changed, s.err
+		s.mu.Unlock()
+		if ok {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("SSE stream closed: %w", err)
+		}
+		select {
+		case <-changed:
+		case <-timer.C:
+			return fmt.Errorf("signals did not match within %s", timeout)
+		case <-s.ctx.Done():
+			return s.ctx.Err()
+		}
+	}
+}
+
+// recorder collects the latencies and errors of the clients of a load test.
+type recorder struct {
+	mu      sync.Mutex
+	page    []time.Duration
+	connect []time.Duration
+	action  []time.Duration
+	errs    []error
+}
+
+func (r *recorder) add(to *[]time.Duration, d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	*to = append(*to, d)
+}
+
+func (r *recorder) fail(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errs = append(r.errs, err)
+}
+
+func (r *recorder) report(clients int, dur time.Duration) *Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return &Report{
+		Clients:  clients,
+		Duration: dur,
+		Page:     percentiles(r.page),
+		Connect:  percentiles(r.connect),
+		Action:   percentiles(r.action),
+		Errors:   slices.Clone(r.errs),
+	}
+}
+
+// percentiles returns the nearest-rank percentiles of the durations.
+func percentiles(ds []time.Duration) Latencies {
+	if len(ds) == 0 {
+		return Latencies{}
+	}
+	ds = slices.Clone(ds)
+	slices.Sort(ds)
+	rank := func(p int) time.Duration {
+		return ds[max((p*len(ds)+99)/100-1, 0)]
+	}
+	return Latencies{Count: len(ds), P50: rank(50), P90: rank(90), P99: rank(99), Max: ds[len(ds)-1]}
+}
@@ -0,0 +1,67 @@
+package loadtest
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-via/via"
+	"github.com/go-via/via/h"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	v := via.New()
+	v.Config(via.Options{LogLvl: via.LogLevelError, SSE: via.SSEOptions{PatchBuffer: 4}})
+	v.Page("/{$}", func(c *via.Context) {
+		count := c.Signal(0)
+		inc := c.Action(func() {
+			count.SetValue(count.Int() + 1)
+			c.SyncSignals()
+		})
+		c.View(func() h.H { return h.Button(inc.OnClick(), count.Text()) })
+	})
+	// the page has a single signal
+	count := func(sigs map[string]any) float64 {
+		for _, v := range sigs {
+			n, _ := v.(float64)
+			return n
+		}
+		return 0
+	}
+	srv := httptest.NewServer(v)
+	defer srv.Close()
+
+	report, err := Run(context.Background(), Config{
+		URL:        srv.URL + "/",
+		Clients:    5,
+		Iterations: 3,
+		Script: func(s *Session) error {
+			before := count(s.Signals())
+			if err := s.Action(0, nil); err != nil {
+				return err
+			}
+			return s.WaitSignal(func(sigs map[string]any) bool { return count(sigs) == before+1 }, 5*time.Second)
+		},
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, report.Errors)
+	assert.Equal(t, 5, report.Page.Count)
+	assert.Equal(t, 5, report.Connect.Count)
+	assert.Equal(t, 15, report.Action.Count)
+	assert.LessOrEqual(t, report.Action.P50, report.Action.Max)
+
+	_, err = Run(context.Background(), Config{URL: "localhost"})
+	assert.Error(t, err)
+}
+
+func TestPercentiles(t *testing.T) {
+	var ds []time.Duration
+	for i := range 100 {
+		ds = append(ds, time.Duration(100-i)*time.Millisecond)
+	}
+	assert.Equal(t, Latencies{Count: 100, P50: 50 * time.Millisecond, P90: 90 * time.Millisecond,
+		P99: 99 * time.Millisecond, Max: 100 * time.Millisecond}, percentiles(ds))
+	assert.Equal(t, Latencies{}, percentiles(nil))
+}
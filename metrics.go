@@ -0,0 +1,158 @@
+package via
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-via/via/h"
+)
+
+// metricsSignal is the browser signal that holds the summary shown by the DevMode
+// metrics overlay. Its name starts with an underscore so Datastar does not send it back
+// to the server.
+const metricsSignal = "_viaMetrics"
+
+// PageMetrics are telemetry of the patches sent to the browser of a page, e.g. to spot
+// views that grow or actions that take long to reach the browser.
+type PageMetrics struct {
+	ContextID string `json:"contextId"`
+	Route     string `json:"route"`
+
+	// Element patches sent and their size in bytes.
+	ElementPatches   int64 `json:"elementPatches"`
+	ElementBytes     int64 `json:"elementBytes"`
+	LastElementBytes int   `json:"lastElementBytes"`
+	MaxElementBytes  int   `json:"maxElementBytes"`
+
+	// Signal patches sent and the number of signals they carried.
+	SignalPatches int64 `json:"signalPatches"`
+	Signals       int64 `json:"signals"`
+	LastSignals   int   `json:"lastSignals"`
+
+	// Renders of the view, or of parts of it, and the time they took.
+	Renders        int64         `json:"renders"`
+	RenderTime     time.Duration `json:"renderTime"`
+	LastRenderTime time.Duration `json:"lastRenderTime"`
+
+	// The time from the receipt of an action request to the flush of the first patch
+	// that followed it.
+	LastActionLatency time.Duration `json:"lastActionLatency"`
+	MaxActionLatency  time.Duration `json:"maxActionLatency"`
+}
+
+// pageMetrics collects the PageMetrics of a page.
+type pageMetrics struct {
+	mu          sync.Mutex
+	m           PageMetrics
+	actionStart time.Time
+}
+
+func (pm *pageMetrics) render(dur time.Duration) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	pm.m.Renders++
+	pm.m.RenderTime += dur
+	pm.m.LastRenderTime = dur
+}
+
+// actionReceived starts the latency measurement of an action unless one is pending.
+func (pm *pageMetrics) actionReceived(t time.Time) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	if pm.actionStart.IsZero() {
+		pm.actionStart = t
+	}
+}
+
+// flushed records a patch written to the SSE stream at the given time.
+func (pm *pageMetrics) flushed(p patch, t time.Time) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	switch p.typ {
+	case patchTypeElements:
+		pm.m.ElementPatches++
+		pm.m.ElementBytes += int64(len(p.content))
+		pm.m.LastElementBytes = len(p.content)
+		pm.m.MaxElementBytes = max(pm.m.MaxElementBytes, len(p.content))
+	case patchTypeSignals:
+		pm.m.SignalPatches++
+		pm.m.Signals += int64(p.signals)
+		pm.m.LastSignals = p.signals
+	}
+	if !pm.actionStart.IsZero() {
+		pm.m.LastActionLatency = t.Sub(pm.actionStart)
+		pm.m.MaxActionLatency = max(pm.m.MaxActionLatency, pm.m.LastActionLatency)
+		pm.actionStart = time.Time{}
+	}
+}
+
+// Metrics returns the patch telemetry of the page of the context.
+func (c *Context) Metrics() PageMetrics {
+	page := c
+	if c.isComponent() {
+		page = c.parentPageCtx
+	}
+	page.metrics.mu.Lock()
+	defer page.metrics.mu.Unlock()
+	m := page.metrics.m
+	m.ContextID, m.Route = page.id, page.route
+	return m
+}
+
+// Metrics returns the patch telemetry of the live pages, ordered by context ID.
+func (v *V) Metrics() []PageMetrics {
+	v.contextRegistryMutex.RLock()
+	ms := make([]PageMetrics, 0, len(v.contextRegistry))
+	for _, c := range v.contextRegistry {
+		ms = append(ms, c.Metrics())
+	}
+	v.contextRegistryMutex.RUnlock()
+	slices.SortFunc(ms, func(a, b PageMetrics) int { return strings.Compare(a.ContextID, b.ContextID) })
+	return ms
+}
+
+// MetricsHandler returns a handler that serves the Metrics of the live pages as JSON,
+// e.g. to mount on an internal route:
+//
+//	v.HandleFunc("GET /debug/metrics", v.MetricsHandler())
+func (v *V) MetricsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(v.Metrics())
+	}
+}
+
+// metricsOverlay returns the DevMode overlay that shows the metrics of the page.
+func metricsOverlay() h.H {
+	return h.Div(
+		h.Data("show", "$"+metricsSignal+" != ''"),
+		h.Data("text", "$"+metricsSignal),
+		h.Attr("style", "position:fixed;bottom:4px;left:4px;z-index:2147483647;padding:2px 6px;"+
+			"font:11px monospace;background:#000c;color:#fff;border-radius:3px;pointer-events:none"),
+	)
+}
+
+// metricsPatch returns the signal patch that updates the DevMode overlay.
+func (c *Context) metricsPatch() patch {
+	m := c.Metrics()
+	summary := fmt.Sprintf("patch %s · %d signals · render %s", formatBytes(m.LastElementBytes), m.LastSignals,
+		m.LastRenderTime.Round(10*time.Microsecond))
+	if m.LastActionLatency > 0 {
+		summary += fmt.Sprintf(" · action→flush %s", m.LastActionLatency.Round(10*time.Microsecond))
+	}
+	content, _ := json.Marshal(map[string]string{metricsSignal: summary})
+	return patch{typ: patchTypeSignals, content: string(content)}
+}
+
+// formatBytes formats a size in bytes for humans.
+func formatBytes(n int) string {
+	if n < 1024 {
+		return fmt.Sprintf("%d B", n)
+	}
+	return fmt.Sprintf("%.1f KB", float64(n)/1024)
+}
@@ -0,0 +1,127 @@
+package via
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// PageNamed registers a page like Page and names its route, so links to the page are
+// built with URL instead of hard-coded paths. Names are unique per app.
+//
+// Example:
+//
+//	v.PageNamed("user-detail", "/users/{id}", userDetail)
+//	h.A(h.LinkTo(c, "user-detail", user.ID), h.Text(user.Name))
+func (v *V) PageNamed(name, route string, initContextFn func(c *Context)) {
+	v.nameRoute(name, route)
+	v.Page(route, initContextFn)
+}
+
+// PageNamed registers a page of the group like Group.Page and names its route, see
+// V.PageNamed.
+func (g *Group) PageNamed(name, route string, initContextFn func(c *Context)) {
+	g.app.nameRoute(name, g.route(route))
+	g.Page(route, initContextFn)
+}
+
+// nameRoute names a route. It panics if the name is taken.
+func (v *V) nameRoute(name, route string) {
+	if _, ok := v.namedRoutes[name]; ok {
+		panic(fmt.Sprintf("duplicate route name %q", name))
+	}
+	if v.namedRoutes == nil {
+		v.namedRoutes = make(map[string]string)
+	}
+	v.namedRoutes[name] = route
+}
+
+// URL returns the path of the route with the given name, see PageNamed. The params fill
+// the path params of the route in order and are formatted with fmt.Sprint. They are
+// escaped. The values of wildcards like {path...} match several segments, so each of
+// their segments is escaped. URL
+// panics if the name is unknown or the number of params does not match the route, so
+// broken links fail loudly.
+//
+// Example:
+//
+//	v.URL("user-detail", 42) // "/users/42"
+func (v *V) URL(name string, params ...any) string {
+	route, ok := v.namedRoutes[name]
+	if !ok {
+		panic(fmt.Sprintf("unknown route name %q", name))
+	}
+	segments := strings.Split(route, "/")
+	n := 0
+	for i, seg := range segments {
+		if seg == "{$}" {
+			segments[i] = ""
+			continue
+		}
+		if !strings.HasPrefix(seg, "{") || !strings.HasSuffix(seg, "}") {
+			continue
+		}
+		if n >= len(params) {
+			panic(fmt.Sprintf("route %q (%s) needs more than %d params", name, route, len(params)))
+		}
+		val := fmt.Sprint(params[n])
+		if strings.HasSuffix(seg, "...}") {
+			segments[i] = escapeWildcard(val)
+		} else {
+			segments[i] = url.PathEscape(val)
+		}
+		n++
+	}
+	if n != len(params) {
+		panic(fmt.Sprintf("route %q (%s) takes %d params, got %d", name, route, n, len(params)))
+	}
+	return strings.Join(segments, "/")
+}
+
+// URL returns the path of the route with the given name, see V.URL. Pages may link to
+// pages registered after them: while a page is registered, links to names that are
+// not known yet are checked when the app starts.
+func (c *Context) URL(name string, params ...any) string {
+	if _, ok := c.app.namedRoutes[name]; !ok && c.id == "" {
+		// the page is being registered, before the pages it links to
+		c.app.pendingLinks = append(c.app.pendingLinks, pendingLink{name: name, params: len(params)})
+		return ""
+	}
+	return c.app.URL(name, params...)
+}
+
+// pendingLink is a link to a route name that was not known when the page with the link
+// was registered, see Context.URL.
+type pendingLink struct {
+	name   string
+	params int
+}
+
+// checkLinks returns an error if a link made while registering pages names an unknown
+// route or passes the wrong number of params.
+func (v *V) checkLinks() (err error) {
+	for _, link := range v.pendingLinks {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					err = errors.Join(err, fmt.Errorf("broken link: %v", r))
+				}
+			}()
+			v.URL(link.name, make([]any, link.params)...)
+		}()
+	}
+	v.pendingLinks = nil
+	return err
+}
+
+// escapeWildcard escapes each segment of the value of a wildcard path param like
+// {path...}. Leading slashes are dropped, so the path does not turn into a link to
+// another host, e.g. "//evil.example".
+func escapeWildcard(val string) string {
+	segments := strings.Split(strings.TrimLeft(val, "/"), "/")
+	for i, seg := range segments {
+		segments[i] = url.PathEscape(seg)
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,121 @@
+package via
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/go-via/via/h"
+)
+
+// noJSPath is the route that runs actions posted by the no-JS fallback form, see
+// Options.NoJSFallback.
+const noJSPath = "/_via/action"
+
+// noJSFormID is the ID of the form that buttons and bound inputs of a page submit
+// without JS.
+const noJSFormID = "via-nojs"
+
+// Names of the form fields that carry the context and the action.
+const (
+	noJSCtxField    = "via-ctx"
+	noJSActionField = "via-action"
+)
+
+// noJSLiveScript marks the page as live once its SSE stream is connected, so buttons
+// trigger actions instead of posting the fallback form.
+const noJSLiveScript = "window.viaLive = true"
+
+// noJSForm returns the fallback form of a page. It comes first in the body, so its
+// disabled button is the default button of the form and pressing Enter in a bound input
+// does not submit it.
+func noJSForm(c *Context) h.H {
+	return h.Form(h.ID(noJSFormID), h.Attr("method", "post"), h.Attr("action", noJSPath), h.Attr("hidden"),
+		h.Button(h.Type("submit"), h.Attr("disabled")),
+		h.Input(h.Type("hidden"), h.Attr("name", noJSCtxField), h.Value(c.id)),
+	)
+}
+
+// noJSScript returns the head element that, until the page is live, lets clicks on
+// buttons of the fallback form post it instead of triggering actions, and afterwards
+// keeps the form from being posted.
+func noJSScript() h.H {
+	return h.Meta(h.Data("init", `(() => {
+		document.addEventListener('click', (evt) => !window.viaLive && evt.target.closest?.('[form=`+noJSFormID+`]') && evt.stopPropagation(), true);
+		document.addEventListener('submit', (evt) => window.viaLive && evt.target.id === '`+noJSFormID+`' && evt.preventDefault());
+	})()`))
+}
+
+// withNoJSSubmit adds the attributes that make a button submit the fallback form with
+// the action to the trigger attribute, if the fallback is enabled.
+func (a *actionTrigger) withNoJSSubmit(trigger h.H) h.H {
+	if !a.noJS {
+		return trigger
+	}
+	return h.Group(trigger, h.Attr("form", noJSFormID), h.Attr("name", noJSActionField), h.Value(a.id))
+}
+
+// withNoJSField adds the attributes that post the value of a bound input with the
+// fallback form to the bind attribute, if the fallback is enabled.
+func (s *signal) withNoJSField(bind h.H) h.H {
+	if !s.noJS {
+		return bind
+	}
+	return h.Group(bind, h.Attr("form", noJSFormID), h.Attr("name", s.id))
+}
+
+// serveNoJSAction runs an action posted by the fallback form and answers with the page
+// re-rendered, or a redirect if the action called c.Redirect.
+func (v *V) serveNoJSAction(w http.ResponseWriter, r *http.Request) {
+	if err := http.NewCrossOriginProtection().Check(r); err != nil {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxFormSize)
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	c, err := v.getCtx(r.PostForm.Get(noJSCtxField))
+	if err != nil {
+		// the context expired, e.g. after a restart, so reload the page
+		v.logWarn(nil, "no-JS action of unknown context dropped: %v", err)
+		http.Redirect(w, r, refererPath(r), http.StatusSeeOther)
+		return
+	}
+	c.setRequestID(requestID(w, r))
+	if !v.checkClientBinding(c, w, r) {
+		return
+	}
+	sigs := make(map[string]any)
+	for k, vals := range r.PostForm {
+		if _, ok := c.signals.Load(k); ok && len(vals) > 0 {
+			sigs[k] = vals[0]
+		}
+	}
+	c.mu.Lock()
+	c.redirect = ""
+	c.mu.Unlock()
+	if !v.runAction(w, r, c, r.PostForm.Get(noJSActionField), sigs) {
+		return
+	}
+	c.mu.RLock()
+	redirect := c.redirect
+	c.mu.RUnlock()
+	if redirect != "" {
+		http.Redirect(w, r, redirect, http.StatusSeeOther)
+		return
+	}
+	if v.cfg.CSP != nil {
+		w.Header().Set(v.cfg.CSP.headerName(), v.cfg.CSP.header(c.nonce))
+	}
+	v.servePage(w, r, c)
+}
+
+// refererPath returns the path of the page that sent the request, or "/".
+func refererPath(r *http.Request) string {
+	u, err := url.Parse(r.Referer())
+	if err != nil || u.Path == "" {
+		return "/"
+	}
+	return u.RequestURI()
+}
@@ -0,0 +1,77 @@
+package via
+
+import "slices"
+
+// LiveUpdate selects the live contexts to update and how. Contexts of the given page
+// routes and with the given IDs are updated with Apply, then synced.
+type LiveUpdate struct {
+	// Page routes as registered with Page, e.g. "/orders/{id}".
+	Routes []string
+
+	// IDs of contexts, e.g. stored when the context subscribed to an external event.
+	ContextIDs []string
+
+	// Limits the update to contexts of the tenant, see Options.TenantResolver.
+	// Optional; without it, contexts of all tenants are selected.
+	Tenant string
+
+	// Apply updates the state of a context. Optional; without it, views are re-rendered.
+	Apply func(c *Context)
+}
+
+// NotifySession runs f on the live context with the given ID and syncs the changes to
+// the browser. It is safe to call from any goroutine, e.g. when a background task for
+// the context completes: f runs one at a time with the actions of the context, and
+// after the running action, if any. State changed by f persists in the context, so it
+// is also shown if the browser reconnects later.
+// Returns an error if no live context with the ID exists.
+func (v *V) NotifySession(sessionID string, f func(c *Context)) error {
+	c, err := v.getCtx(sessionID)
+	if err != nil {
+		return err
+	}
+	v.applyUpdate(c, f)
+	return nil
+}
+
+// applyLiveUpdate applies the update to the live contexts it selects.
+func (v *V) applyLiveUpdate(update LiveUpdate) {
+	for _, c := range v.liveContexts(update.Routes, update.ContextIDs, update.Tenant) {
+		v.applyUpdate(c, update.Apply)
+	}
+}
+
+// liveContexts returns the registered contexts of the given page routes and with the
+// given IDs, limited to the given tenant if not empty.
+func (v *V) liveContexts(routes []string, ids []string, tenant string) []*Context {
+	v.contextRegistryMutex.RLock()
+	defer v.contextRegistryMutex.RUnlock()
+	var ctxs []*Context
+	for id, c := range v.contextRegistry {
+		if tenant != "" && c.tenant != tenant {
+			continue
+		}
+		if slices.Contains(routes, c.route) || slices.Contains(ids, id) {
+			ctxs = append(ctxs, c)
+		}
+	}
+	return ctxs
+}
+
+// applyUpdate runs the given update on the context and syncs it to the browser, one at
+// a time with the actions of the context, see Context.runUpdate. Panics of the update
+// are logged.
+func (v *V) applyUpdate(c *Context, apply func(c *Context)) {
+	c.runUpdate(func() {
+		defer func() {
+			if r := recover(); r != nil {
+				v.logErr(c, "update failed: %v", r)
+				v.reportErr(c, PhaseUpdate, panicErr(r))
+			}
+		}()
+		if apply != nil {
+			apply(c)
+		}
+		c.Sync()
+	})
+}
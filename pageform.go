@@ -0,0 +1,139 @@
+package via
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/go-via/via/h"
+)
+
+// maxFormSize is the maximum size of the body of form submissions.
+const maxFormSize = 1 << 20
+
+// formPath is the path the live page posts forms to, see Context.Form.
+const formPath = "/_form"
+
+// PageForm registers a page like Page whose form, rendered with Context.Form, works
+// with and without JS. Browsers without JS or SSE post the form to the route: the page
+// is initialized, submitFn runs with the posted values and the page is rendered with
+// the resulting state, or the browser is sent to the URL passed to c.Redirect with a
+// 303 See Other. With JS the form is posted to the live page instead and submitFn runs
+// as an action with the posted values. The values are sent in the request body only, so
+// passwords and the like stay out of URLs and signals. Form posts from other origins
+// are rejected, see http.CrossOriginProtection.
+//
+// Example:
+//
+//	v.PageForm("/signup", func(c *via.Context) {
+//		c.View(func() h.H {
+//			return c.Form(h.Input(h.Attr("name", "email")), h.Button(h.Text("Sign up")),
+//				h.P(h.Text(c.GetQueryParam("error"))))
+//		})
+//	}, func(c *via.Context, form url.Values) {
+//		if err := signup(form.Get("email")); err != nil {
+//			c.Redirect("/signup?error=" + url.QueryEscape(err.Error()))
+//			return
+//		}
+//		c.Redirect("/welcome")
+//	})
+func (v *V) PageForm(route string, initContextFn func(c *Context), submitFn func(c *Context, form url.Values)) {
+	initForm := func(c *Context) {
+		c.formID = c.app.genID()
+		c.formSubmit = func(form url.Values) { submitFn(c, form) }
+		initContextFn(c)
+	}
+	v.page("", route, initForm)
+	csrf := http.NewCrossOriginProtection()
+	v.mux.HandleFunc("POST "+route, func(w http.ResponseWriter, r *http.Request) {
+		v.logDebug(nil, "POST %s client=%s", r.URL.String(), v.clientIP(r))
+		if err := csrf.Check(r); err != nil {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, maxFormSize)
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+		c := v.initPage(w, r, route, route, initForm)
+		if c == nil {
+			return
+		}
+		r.PostForm.Del(noJSCtxField)
+		if err := v.submitForm(c, submitFn, r.PostForm); err != nil {
+			c.stopAllRoutines()
+			c.dispose()
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		if c.redirect != "" {
+			c.stopAllRoutines()
+			c.dispose()
+			http.Redirect(w, r, c.redirect, http.StatusSeeOther)
+			return
+		}
+		v.servePage(w, r, c)
+	})
+}
+
+// submitForm runs the submit func of a form posted without JS and reports a panic.
+func (v *V) submitForm(c *Context, submitFn func(c *Context, form url.Values), form url.Values) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			v.logErr(c, "form submit failed: %v", r)
+			err = panicErr(r)
+			v.reportErr(c, PhaseAction, err)
+		}
+	}()
+	submitFn(c, form)
+	return nil
+}
+
+// Form returns a form that is posted to the page without JS and submitted as an action
+// with JS, see V.PageForm. It panics if the page was not registered with PageForm.
+func (c *Context) Form(children ...h.H) h.H {
+	page := c
+	if c.isComponent() {
+		page = c.parentPageCtx
+	}
+	if page.formSubmit == nil {
+		panic("Form of a page not registered with PageForm")
+	}
+	return h.Form(append([]h.H{
+		h.Attr("method", "post"),
+		h.Data("on:submit__prevent", fmt.Sprintf("@post('%s', {contentType: 'form'})", formPath)),
+		h.Input(h.Type("hidden"), h.Attr("name", noJSCtxField), h.Value(page.id)),
+	}, children...)...)
+}
+
+// serveForm runs the submit func of a form posted by the live page, see Context.Form.
+func (v *V) serveForm(w http.ResponseWriter, r *http.Request) {
+	if err := http.NewCrossOriginProtection().Check(r); err != nil {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxFormSize)
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	c, err := v.getCtx(r.PostForm.Get(noJSCtxField))
+	if err != nil {
+		v.logErr(nil, "form submit failed: %v", err)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	c.setRequestID(requestID(w, r))
+	if !v.checkClientBinding(c, w, r) {
+		return
+	}
+	if c.formSubmit == nil {
+		// viewers of the page have no form to submit
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	form := r.PostForm
+	form.Del(noJSCtxField)
+	v.invokeAction(w, r, c, c.formID, func() { c.formSubmit(form) }, nil)
+}
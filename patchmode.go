@@ -0,0 +1,63 @@
+package via
+
+import (
+	"io"
+
+	"github.com/go-via/via/h"
+	"github.com/starfederation/datastar-go/datastar"
+)
+
+// PatchMode selects how SyncElements applies elements to the DOM. It is passed among
+// the elements and renders nothing.
+//
+// Example:
+//
+//	c.SyncElements(h.Li(h.Text(msg)), via.PatchTarget("#log"), via.PatchAppend)
+type PatchMode string
+
+const (
+	// PatchMorph morphs the elements into the elements with matching IDs, the default.
+	PatchMorph PatchMode = PatchMode(datastar.ElementPatchModeOuter)
+	// PatchInner morphs the elements into the children of the target.
+	PatchInner PatchMode = PatchMode(datastar.ElementPatchModeInner)
+	// PatchReplace replaces the target with the elements, without morphing.
+	PatchReplace PatchMode = PatchMode(datastar.ElementPatchModeReplace)
+	// PatchAppend appends the elements to the children of the target.
+	PatchAppend PatchMode = PatchMode(datastar.ElementPatchModeAppend)
+	// PatchPrepend prepends the elements to the children of the target.
+	PatchPrepend PatchMode = PatchMode(datastar.ElementPatchModePrepend)
+	// PatchBefore inserts the elements before the target.
+	PatchBefore PatchMode = PatchMode(datastar.ElementPatchModeBefore)
+	// PatchAfter inserts the elements after the target.
+	PatchAfter PatchMode = PatchMode(datastar.ElementPatchModeAfter)
+	// PatchRemove removes the target, or the elements with the IDs of the given elements.
+	PatchRemove PatchMode = PatchMode(datastar.ElementPatchModeRemove)
+)
+
+// Render implements h.H. A PatchMode renders nothing.
+func (m PatchMode) Render(io.Writer) error {
+	return nil
+}
+
+// needsTarget reports whether the mode inserts elements relative to a target, which
+// must be set with PatchTarget.
+func (m PatchMode) needsTarget() bool {
+	switch m {
+	case PatchAppend, PatchPrepend, PatchBefore, PatchAfter:
+		return true
+	}
+	return false
+}
+
+type patchTarget string
+
+func (t patchTarget) Render(io.Writer) error {
+	return nil
+}
+
+// PatchTarget sets the CSS selector of the target of SyncElements, e.g. "#log". It is
+// passed among the elements and renders nothing. Without a target, elements patch the
+// elements with matching IDs.
+func PatchTarget(selector string) h.H {
+	return patchTarget(selector)
+}
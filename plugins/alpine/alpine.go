@@ -0,0 +1,102 @@
+// Package alpine integrates Alpine.js with Via for islands: elements whose content is
+// driven by Alpine or other third-party JS, for cases Datastar alone can't cover.
+//
+// Islands are bridged to server signals with DOM events:
+//
+//   - When a bound signal changes, the island element receives a 'via-signal' event with
+//     the detail {name, value}. The current values are also kept in el.viaSignals. Render
+//     the initial state from the signals on the server, as Alpine may start first.
+//   - To set a bound signal, the island dispatches a bubbling 'via-set' event with the
+//     detail {name, value}, e.g. with $dispatch in Alpine.
+//
+// Datastar does not morph the content of islands, so the state of the third-party JS is
+// kept when the view is synced.
+//
+// Example:
+//
+//	v.Config(via.Options{Plugins: []via.Plugin{alpine.Plugin}})
+//
+//	v.Page("/", func(c *via.Context) {
+//		rating := c.Signal(3)
+//		save := c.Action(func() { /* persist rating.Int() */ })
+//
+//		c.View(func() h.H {
+//			return h.Div(
+//				alpine.Island(fmt.Sprintf("{stars: %d}", rating.Int()), alpine.Bind("rating", rating)),
+//				h.Attr("x-on:via-signal", "stars = $event.detail.value"),
+//				save.OnEvent("rating-save"),
+//				h.Template(h.Attr("x-for", "i in 5"),
+//					h.Button(h.Text("★"), h.Attr("x-on:click",
+//						"$dispatch('via-set', {name: 'rating', value: i}); $dispatch('rating-save')")),
+//				),
+//			)
+//		})
+//	})
+package alpine
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-via/via"
+	"github.com/go-via/via/h"
+)
+
+// ScriptURL is the Alpine.js bundle added to the document head by Plugin.
+var ScriptURL = "https://cdn.jsdelivr.net/npm/alpinejs@3.14.1/dist/cdn.min.js"
+
+// Plugin adds Alpine.js to the document head.
+func Plugin(v *via.V) {
+	v.AppendToHead(via.NonceScript(h.Attr("defer"), h.Src(ScriptURL)))
+}
+
+// Signal is a reactive value created with *via.Context.Signal.
+type Signal interface {
+	ID() string
+}
+
+// Binding connects a signal to an island under the given name.
+type Binding struct {
+	name string
+	sig  Signal
+}
+
+// Bind returns a binding of the signal to the given name in an island.
+func Bind(name string, s Signal) Binding {
+	return Binding{name: name, sig: s}
+}
+
+// Island returns the attributes that make the element an Alpine component with the
+// given x-data state, bridged to the bound signals. The element must not have other
+// data-effect or data-on:via-set attributes.
+func Island(state string, bindings ...Binding) h.H {
+	attrs := []h.H{Data(state), h.Data("ignore-morph", "")}
+	if len(bindings) == 0 {
+		return h.Group(attrs...)
+	}
+	var values, events, sets []string
+	for _, b := range bindings {
+		name := strconv.Quote(b.name)
+		values = append(values, fmt.Sprintf("%s:$%s", name, b.sig.ID()))
+		events = append(events, fmt.Sprintf(
+			"el.dispatchEvent(new CustomEvent('via-signal',{detail:{name:%s,value:$%s}}))", name, b.sig.ID()))
+		sets = append(sets, fmt.Sprintf("evt.detail.name===%s&&($%s=evt.detail.value)", name, b.sig.ID()))
+	}
+	return h.Group(append(attrs,
+		h.Data("effect", fmt.Sprintf("el.viaSignals={%s};%s", strings.Join(values, ","), strings.Join(events, ";"))),
+		h.Data("on:via-set", strings.Join(sets, ";")),
+	)...)
+}
+
+// Data returns the x-data attribute that declares an Alpine component with the given
+// state.
+func Data(state string) h.H {
+	return h.Attr("x-data", state)
+}
+
+// Init returns the x-init attribute that runs the given JS when the component is
+// initialized.
+func Init(js string) h.H {
+	return h.Attr("x-init", js)
+}
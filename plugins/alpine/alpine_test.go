@@ -0,0 +1,58 @@
+package alpine
+
+import (
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/go-via/via"
+	"github.com/go-via/via/h"
+	"github.com/stretchr/testify/assert"
+)
+
+type testSignal string
+
+func (s testSignal) ID() string { return string(s) }
+
+func TestIsland(t *testing.T) {
+	testcases := []struct {
+		desc     string
+		state    string
+		bindings []Binding
+		expected string
+	}{
+		{"without bindings", "{open: false}", nil,
+			`<div x-data="{open: false}" data-ignore-morph=""></div>`},
+		{"one binding", "{stars: 3}", []Binding{Bind("rating", testSignal("s1"))},
+			`<div x-data="{stars: 3}" data-ignore-morph=""` +
+				` data-effect="el.viaSignals={&#34;rating&#34;:$s1};el.dispatchEvent(new CustomEvent(&#39;via-signal&#39;,{detail:{name:&#34;rating&#34;,value:$s1}}))"` +
+				` data-on:via-set="evt.detail.name===&#34;rating&#34;&amp;&amp;($s1=evt.detail.value)"></div>`},
+		{"two bindings", "{}", []Binding{Bind("x", testSignal("s1")), Bind("y", testSignal("s2"))},
+			`<div x-data="{}" data-ignore-morph=""` +
+				` data-effect="el.viaSignals={&#34;x&#34;:$s1,&#34;y&#34;:$s2};el.dispatchEvent(new CustomEvent(&#39;via-signal&#39;,{detail:{name:&#34;x&#34;,value:$s1}}));el.dispatchEvent(new CustomEvent(&#39;via-signal&#39;,{detail:{name:&#34;y&#34;,value:$s2}}))"` +
+				` data-on:via-set="evt.detail.name===&#34;x&#34;&amp;&amp;($s1=evt.detail.value);evt.detail.name===&#34;y&#34;&amp;&amp;($s2=evt.detail.value)"></div>`},
+		{"quoted names", "{}", []Binding{Bind(`a"b`, testSignal("s1"))},
+			`<div x-data="{}" data-ignore-morph=""` +
+				` data-effect="el.viaSignals={&#34;a\&#34;b&#34;:$s1};el.dispatchEvent(new CustomEvent(&#39;via-signal&#39;,{detail:{name:&#34;a\&#34;b&#34;,value:$s1}}))"` +
+				` data-on:via-set="evt.detail.name===&#34;a\&#34;b&#34;&amp;&amp;($s1=evt.detail.value)"></div>`},
+	}
+	for _, testcase := range testcases {
+		t.Run(testcase.desc, func(t *testing.T) {
+			html, err := h.Render(h.Div(Island(testcase.state, testcase.bindings...)))
+			assert.NoError(t, err)
+			assert.Equal(t, testcase.expected, html)
+		})
+	}
+}
+
+func TestPlugin(t *testing.T) {
+	v := via.New()
+	v.Config(via.Options{CSP: via.NewCSP(), Plugins: []via.Plugin{Plugin}})
+	v.Page("/", func(c *via.Context) { c.View(func() h.H { return h.Div() }) })
+	w := httptest.NewRecorder()
+	v.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	nonce := regexp.MustCompile(`'nonce-([^']+)'`).FindStringSubmatch(w.Header().Get("Content-Security-Policy"))
+	if assert.Len(t, nonce, 2) {
+		assert.Contains(t, w.Body.String(), `<script nonce="`+nonce[1]+`" defer src="`+ScriptURL+`"></script>`)
+	}
+}
@@ -0,0 +1,84 @@
+// Package bootstrap integrates Bootstrap with Via. Plugin adds the Bootstrap stylesheet
+// to the document head and sets the ui.Theme, so ui components render with Bootstrap
+// classes.
+//
+// Example:
+//
+//	v.Config(via.Options{Plugins: []via.Plugin{
+//		bootstrap.WithOptions(bootstrap.Options{Theme: "flatly", ColorScheme: "dark"}),
+//	}})
+package bootstrap
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/go-via/via"
+	"github.com/go-via/via/h"
+	"github.com/go-via/via/ui"
+)
+
+var (
+	// StyleURL is the Bootstrap stylesheet added to the document head.
+	StyleURL = "https://cdn.jsdelivr.net/npm/bootstrap@5.3.3/dist/css/bootstrap.min.css"
+	// ThemeURL is the template of the Bootswatch stylesheets used for Options.Theme.
+	ThemeURL = "https://cdn.jsdelivr.net/npm/bootswatch@5.3.3/dist/%s/bootstrap.min.css"
+)
+
+// Theme are the classes ui components render with.
+var Theme = ui.Theme{
+	Input:          "form-control",
+	Select:         "form-select",
+	Pagination:     "pagination",
+	PageItem:       "page-item",
+	PageItemActive: "page-item active",
+	PageButton:     "page-link",
+	// The active state is set on the list item.
+	PageButtonActive: "page-link",
+}
+
+// Options configures the plugin.
+type Options struct {
+	// A Bootswatch theme used instead of the default stylesheet, e.g. 'flatly'.
+	Theme string
+
+	// The color mode, 'light' or 'dark'. Default: light.
+	ColorScheme string
+
+	// CSS variables set on the root element, e.g. {"--bs-body-font-family": "serif"}.
+	Variables map[string]string
+}
+
+// Default adds the default Bootstrap stylesheet.
+var Default = WithOptions(Options{})
+
+// WithOptions returns the plugin with the given options.
+func WithOptions(opts Options) via.Plugin {
+	return func(v *via.V) {
+		href := StyleURL
+		if opts.Theme != "" {
+			href = fmt.Sprintf(ThemeURL, opts.Theme)
+		}
+		v.AppendToHead(h.Link(h.Rel("stylesheet"), h.Href(href)), rootStyle(opts.Variables))
+		if opts.ColorScheme != "" {
+			v.AppendToHTMLAttrs(h.Attr("data-bs-theme", opts.ColorScheme))
+		}
+		ui.SetTheme(v, Theme)
+	}
+}
+
+// rootStyle returns a style element that sets the variables on :root, or nil.
+func rootStyle(vars map[string]string) h.H {
+	if len(vars) == 0 {
+		return nil
+	}
+	var b strings.Builder
+	b.WriteString(":root{")
+	for _, k := range slices.Sorted(maps.Keys(vars)) {
+		fmt.Fprintf(&b, "%s:%s;", k, vars[k])
+	}
+	b.WriteString("}")
+	return h.StyleEl(h.Raw(b.String()))
+}
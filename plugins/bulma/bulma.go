@@ -0,0 +1,73 @@
+// Package bulma integrates Bulma with Via. Plugin adds the Bulma stylesheet to the
+// document head and sets the ui.Theme, so ui components render with Bulma classes.
+//
+// Example:
+//
+//	v.Config(via.Options{Plugins: []via.Plugin{
+//		bulma.WithOptions(bulma.Options{
+//			ColorScheme: "dark",
+//			Variables:   map[string]string{"--bulma-primary-h": "260deg"},
+//		}),
+//	}})
+package bulma
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/go-via/via"
+	"github.com/go-via/via/h"
+	"github.com/go-via/via/ui"
+)
+
+// StyleURL is the Bulma stylesheet added to the document head.
+var StyleURL = "https://cdn.jsdelivr.net/npm/bulma@1.0.2/css/bulma.min.css"
+
+// Theme are the classes ui components render with.
+var Theme = ui.Theme{
+	Input:            "input",
+	Progress:         "progress is-primary",
+	Pagination:       "pagination-list",
+	PageButton:       "pagination-link",
+	PageButtonActive: "pagination-link is-current",
+}
+
+// Options configures the plugin.
+type Options struct {
+	// The color scheme, 'light' or 'dark'. Default: the scheme preferred by the browser.
+	ColorScheme string
+
+	// CSS variables set on the root element, e.g. {"--bulma-primary-h": "260deg"} to
+	// change the hue of the primary color.
+	Variables map[string]string
+}
+
+// Default adds the Bulma stylesheet.
+var Default = WithOptions(Options{})
+
+// WithOptions returns the plugin with the given options.
+func WithOptions(opts Options) via.Plugin {
+	return func(v *via.V) {
+		v.AppendToHead(h.Link(h.Rel("stylesheet"), h.Href(StyleURL)), rootStyle(opts.Variables))
+		if opts.ColorScheme != "" {
+			v.AppendToHTMLAttrs(h.Attr("data-theme", opts.ColorScheme))
+		}
+		ui.SetTheme(v, Theme)
+	}
+}
+
+// rootStyle returns a style element that sets the variables on :root, or nil.
+func rootStyle(vars map[string]string) h.H {
+	if len(vars) == 0 {
+		return nil
+	}
+	var b strings.Builder
+	b.WriteString(":root{")
+	for _, k := range slices.Sorted(maps.Keys(vars)) {
+		fmt.Fprintf(&b, "%s:%s;", k, vars[k])
+	}
+	b.WriteString("}")
+	return h.StyleEl(h.Raw(b.String()))
+}
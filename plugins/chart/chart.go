@@ -0,0 +1,109 @@
+// Package chart integrates Chart.js with Via to render charts whose data is
+// updated live from the server.
+//
+// Example:
+//
+//	v.Config(via.Options{Plugins: []via.Plugin{chart.Plugin}})
+//
+//	v.Page("/", func(c *via.Context) {
+//		canvas, sales := chart.New(c, chart.Config{Type: "line"})
+//		c.OnInterval(time.Second, func() {
+//			sales.SetData(labels, []chart.Dataset{{Label: "Sales", Data: values}})
+//		}).Start()
+//
+//		c.View(func() h.H { return h.Div(canvas()) })
+//	})
+package chart
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/go-via/via"
+	"github.com/go-via/via/h"
+)
+
+// ScriptURL is the Chart.js bundle added to the document head by Plugin.
+var ScriptURL = "https://cdn.jsdelivr.net/npm/chart.js@4.4.1/dist/chart.umd.min.js"
+
+// dataEvent is the custom DOM event dispatched on the chart canvas to deliver new data.
+const dataEvent = "via-chart-data"
+
+// Plugin adds Chart.js to the document head.
+func Plugin(v *via.V) {
+	v.AppendToHead(via.NonceScript(h.Src(ScriptURL)))
+}
+
+// Dataset is a named series of values.
+type Dataset struct {
+	Label string    `json:"label"`
+	Data  []float64 `json:"data"`
+}
+
+// Config defines the initial state of a chart. Type is any Chart.js chart type such as
+// "line", "bar" or "pie". Options is passed as is to Chart.js.
+type Config struct {
+	Type     string
+	Labels   []string
+	Datasets []Dataset
+	Options  map[string]any
+}
+
+// Chart is the server-side handle of a chart rendered in the browser.
+type Chart struct {
+	id  string
+	c   *via.Context
+	mu  sync.Mutex
+	cfg Config
+}
+
+// New returns the chart canvas fn to place in the view and a handle to update its data.
+func New(c *via.Context, cfg Config) (func() h.H, *Chart) {
+	ch := &Chart{id: "chart-" + c.NewID(), c: c, cfg: cfg}
+	canvas := func() h.H {
+		return h.Canvas(h.ID(ch.id), h.Data("init", ch.initScript()))
+	}
+	return canvas, ch
+}
+
+// SetData replaces the labels and datasets of the chart and pushes them to the browser.
+func (ch *Chart) SetData(labels []string, datasets []Dataset) {
+	ch.mu.Lock()
+	ch.cfg.Labels = labels
+	ch.cfg.Datasets = datasets
+	ch.mu.Unlock()
+
+	detail, err := json.Marshal(chartData(labels, datasets))
+	if err != nil {
+		return
+	}
+	ch.c.ExecScript(fmt.Sprintf(
+		"document.getElementById('%s')?.dispatchEvent(new CustomEvent('%s',{detail:%s}))",
+		ch.id, dataEvent, detail))
+}
+
+func (ch *Chart) initScript() string {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	cfg, _ := json.Marshal(map[string]any{
+		"type":    ch.cfg.Type,
+		"data":    chartData(ch.cfg.Labels, ch.cfg.Datasets),
+		"options": ch.cfg.Options,
+	})
+	return fmt.Sprintf(`if(!el._viaChart){el._viaChart=new Chart(el,%s);`+
+		`el.addEventListener('%s',(e)=>{el._viaChart.data.labels=e.detail.labels;`+
+		`el._viaChart.data.datasets=e.detail.datasets;el._viaChart.update()})}`, cfg, dataEvent)
+}
+
+// chartData returns the Chart.js data object. Nil slices are replaced with empty ones
+// since Chart.js expects arrays.
+func chartData(labels []string, datasets []Dataset) map[string]any {
+	if labels == nil {
+		labels = []string{}
+	}
+	if datasets == nil {
+		datasets = []Dataset{}
+	}
+	return map[string]any{"labels": labels, "datasets": datasets}
+}
@@ -0,0 +1,135 @@
+// Package leaflet integrates Leaflet maps with Via. Markers, popups and the viewport
+// are controlled from the server and map clicks are delivered to Go with their
+// coordinates.
+//
+// Example:
+//
+//	v.Config(via.Options{Plugins: []via.Plugin{leaflet.Plugin}})
+//
+//	v.Page("/", func(c *via.Context) {
+//		mapEl, m := leaflet.New(c, leaflet.View{Lat: 51.5, Lng: -0.09, Zoom: 13}, nil)
+//		m.SetMarker(leaflet.Marker{ID: "hq", Lat: 51.5, Lng: -0.09, Popup: "HQ"})
+//
+//		c.View(func() h.H { return h.Div(mapEl()) })
+//	})
+package leaflet
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/go-via/via"
+	"github.com/go-via/via/h"
+)
+
+var (
+	// ScriptURL is the Leaflet bundle added to the document head by Plugin.
+	ScriptURL = "https://unpkg.com/leaflet@1.9.4/dist/leaflet.js"
+	// StyleURL is the Leaflet stylesheet added to the document head by Plugin.
+	StyleURL = "https://unpkg.com/leaflet@1.9.4/dist/leaflet.css"
+	// TileURL is the tile layer template used by new maps.
+	TileURL = "https://tile.openstreetmap.org/{z}/{x}/{y}.png"
+)
+
+// clickEvent is the custom DOM event dispatched on the map element when the map is clicked.
+const clickEvent = "via-map-click"
+
+// Plugin adds Leaflet to the document head.
+func Plugin(v *via.V) {
+	v.AppendToHead(
+		h.Link(h.Rel("stylesheet"), h.Href(StyleURL)),
+		via.NonceScript(h.Src(ScriptURL)),
+	)
+}
+
+// View is the map viewport.
+type View struct {
+	Lat  float64 `json:"lat"`
+	Lng  float64 `json:"lng"`
+	Zoom int     `json:"zoom"`
+}
+
+// Marker is a pin on the map. Popup is optional HTML shown when the marker is clicked.
+type Marker struct {
+	ID    string  `json:"id"`
+	Lat   float64 `json:"lat"`
+	Lng   float64 `json:"lng"`
+	Popup string  `json:"popup,omitempty"`
+}
+
+// Map is the server-side handle of a map rendered in the browser.
+type Map struct {
+	id      string
+	c       *via.Context
+	mu      sync.Mutex
+	view    View
+	markers map[string]Marker
+}
+
+// New returns the map element fn to place in the view and a handle to control the map.
+// If onClick is not nil, it is called with the coordinates of every click on the map.
+// The map element is 400px high unless styled otherwise.
+func New(c *via.Context, view View, onClick func(lat, lng float64)) (func() h.H, *Map) {
+	m := &Map{id: "map-" + c.NewID(), c: c, view: view, markers: make(map[string]Marker)}
+
+	lat := c.Signal(0)
+	lng := c.Signal(0)
+	var onClickAttr h.H
+	if onClick != nil {
+		click := c.Action(func() {
+			onClick(lat.Float(), lng.Float())
+		})
+		onClickAttr = click.OnEvent(clickEvent)
+	}
+	mapEl := func() h.H {
+		return h.Div(h.ID(m.id), h.Style("height:400px"), onClickAttr, h.Data("init", m.initScript(lat.ID(), lng.ID())))
+	}
+	return mapEl, m
+}
+
+// SetView moves the map to the given viewport.
+func (m *Map) SetView(view View) {
+	m.mu.Lock()
+	m.view = view
+	m.mu.Unlock()
+	m.exec(fmt.Sprintf("map.setView([%f,%f],%d)", view.Lat, view.Lng, view.Zoom))
+}
+
+// SetMarker adds a marker to the map or replaces the marker with the same ID.
+func (m *Map) SetMarker(marker Marker) {
+	m.mu.Lock()
+	m.markers[marker.ID] = marker
+	m.mu.Unlock()
+	j, _ := json.Marshal(marker)
+	m.exec(fmt.Sprintf("el._viaSetMarker(%s)", j))
+}
+
+// RemoveMarker removes the marker with the given ID from the map.
+func (m *Map) RemoveMarker(id string) {
+	m.mu.Lock()
+	delete(m.markers, id)
+	m.mu.Unlock()
+	j, _ := json.Marshal(id)
+	m.exec(fmt.Sprintf("el._viaMarkers[%s]?.remove();delete el._viaMarkers[%s]", j, j))
+}
+
+func (m *Map) exec(js string) {
+	m.c.ExecScript(fmt.Sprintf("{const el=document.getElementById('%s');if(el&&el._viaMap){const map=el._viaMap;%s}}", m.id, js))
+}
+
+func (m *Map) initScript(latSigID, lngSigID string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	markers := make([]Marker, 0, len(m.markers))
+	for _, mk := range m.markers {
+		markers = append(markers, mk)
+	}
+	j, _ := json.Marshal(markers)
+	return fmt.Sprintf(`if(!el._viaMap){const map=L.map(el).setView([%f,%f],%d);el._viaMap=map;el._viaMarkers={};`+
+		`L.tileLayer('%s',{maxZoom:19}).addTo(map);`+
+		`el._viaSetMarker=(mk)=>{el._viaMarkers[mk.id]?.remove();const p=L.marker([mk.lat,mk.lng]).addTo(map);`+
+		`if(mk.popup){p.bindPopup(mk.popup)}el._viaMarkers[mk.id]=p};%s.forEach(el._viaSetMarker);`+
+		`map.on('click',(e)=>{$%s=e.latlng.lat;$%s=e.latlng.lng;el.dispatchEvent(new CustomEvent('%s'))})}`,
+		m.view.Lat, m.view.Lng, m.view.Zoom, TileURL, j, latSigID, lngSigID, clickEvent)
+}
@@ -0,0 +1,79 @@
+package tailwind
+
+import (
+	"strings"
+
+	"github.com/go-via/via/h"
+)
+
+// Class is a Tailwind utility class.
+type Class string
+
+// Classes returns a class attribute with the given classes.
+func Classes(classes ...Class) h.H {
+	names := make([]string, 0, len(classes))
+	for _, c := range classes {
+		if c != "" {
+			names = append(names, string(c))
+		}
+	}
+	return h.Class(strings.Join(names, " "))
+}
+
+// Typed helpers for common layout utilities. Their classes are always included in
+// stylesheets compiled with Build, even though the package source is not scanned.
+const (
+	Block       Class = "block"
+	InlineBlock Class = "inline-block"
+	Inline      Class = "inline"
+	Hidden      Class = "hidden"
+	Flex        Class = "flex"
+	InlineFlex  Class = "inline-flex"
+	Grid        Class = "grid"
+
+	FlexRow  Class = "flex-row"
+	FlexCol  Class = "flex-col"
+	FlexWrap Class = "flex-wrap"
+	Flex1    Class = "flex-1"
+	Grow     Class = "grow"
+	Shrink0  Class = "shrink-0"
+
+	ItemsStart     Class = "items-start"
+	ItemsCenter    Class = "items-center"
+	ItemsEnd       Class = "items-end"
+	ItemsStretch   Class = "items-stretch"
+	JustifyStart   Class = "justify-start"
+	JustifyCenter  Class = "justify-center"
+	JustifyEnd     Class = "justify-end"
+	JustifyBetween Class = "justify-between"
+
+	Relative Class = "relative"
+	Absolute Class = "absolute"
+	Fixed    Class = "fixed"
+	Sticky   Class = "sticky"
+
+	WFull      Class = "w-full"
+	HFull      Class = "h-full"
+	MinHScreen Class = "min-h-screen"
+	MxAuto     Class = "mx-auto"
+
+	Truncate   Class = "truncate"
+	SrOnly     Class = "sr-only"
+	Rounded    Class = "rounded"
+	Shadow     Class = "shadow"
+	Border     Class = "border"
+	FontBold   Class = "font-bold"
+	TextSm     Class = "text-sm"
+	TextLg     Class = "text-lg"
+	TextCenter Class = "text-center"
+)
+
+// helperClasses are the classes of the typed helpers, included by Build.
+var helperClasses = []Class{
+	Block, InlineBlock, Inline, Hidden, Flex, InlineFlex, Grid,
+	FlexRow, FlexCol, FlexWrap, Flex1, Grow, Shrink0,
+	ItemsStart, ItemsCenter, ItemsEnd, ItemsStretch, JustifyStart, JustifyCenter, JustifyEnd, JustifyBetween,
+	Relative, Absolute, Fixed, Sticky,
+	WFull, HFull, MinHScreen, MxAuto,
+	Truncate, SrOnly, Rounded, Shadow, Border, FontBold, TextSm, TextLg, TextCenter,
+}
@@ -0,0 +1,154 @@
+// Package tailwind integrates Tailwind CSS with Via. In DevMode the Tailwind browser
+// build compiles the classes used by the page on the fly. In production a stylesheet
+// built with the standalone Tailwind CLI is served, see Build.
+//
+// Example:
+//
+//	v.Config(via.Options{
+//		DevMode: true,
+//		Plugins: []via.Plugin{tailwind.WithOptions(tailwind.Options{Stylesheet: "static/app.css"})},
+//	})
+//
+//	v.Page("/", func(c *via.Context) {
+//		c.View(func() h.H {
+//			return h.Div(tailwind.Classes(tailwind.Flex, tailwind.ItemsCenter, "gap-4 p-4"))
+//		})
+//	})
+//
+// The stylesheet is built before deploying, e.g. by a command run with go generate:
+//
+//	tailwind.Build(context.Background(), tailwind.BuildOptions{Output: "static/app.css", Minify: true})
+package tailwind
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-via/via"
+	"github.com/go-via/via/h"
+)
+
+// BrowserScriptURL is the Tailwind browser build added to the document head in DevMode.
+var BrowserScriptURL = "https://cdn.jsdelivr.net/npm/@tailwindcss/browser@4"
+
+// stylesheetRoute is the route the built stylesheet is served at.
+const stylesheetRoute = "/_tailwind.css"
+
+// Options configures the plugin.
+type Options struct {
+	// Path of the stylesheet built with Build, served outside DevMode. If empty, the
+	// browser build is used outside DevMode too, which is not recommended in production.
+	Stylesheet string
+}
+
+// Default adds the Tailwind browser build to the document head.
+var Default = WithOptions(Options{})
+
+// WithOptions returns the plugin with the given options.
+func WithOptions(opts Options) via.Plugin {
+	return func(v *via.V) {
+		if v.DevMode() || opts.Stylesheet == "" {
+			v.AppendToHead(via.NonceScript(h.Src(BrowserScriptURL)))
+			return
+		}
+		v.HandleFunc("GET "+stylesheetRoute, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/css; charset=utf-8")
+			w.Header().Set("Cache-Control", "no-cache")
+			http.ServeFile(w, r, opts.Stylesheet)
+		})
+		v.AppendToHead(h.Link(h.Rel("stylesheet"), h.Href(stylesheetRoute)))
+	}
+}
+
+// BuildOptions configures Build.
+type BuildOptions struct {
+	// The standalone Tailwind CLI binary. Default: 'tailwindcss' from PATH.
+	Binary string
+
+	// The CSS entry file. If empty, an entry that imports Tailwind and scans Sources
+	// is generated.
+	Input string
+
+	// Path of the stylesheet to write.
+	Output string
+
+	// Directories scanned for classes, e.g. in h.Class attributes of .go files, when
+	// Input is empty. Default: the working directory.
+	Sources []string
+
+	// If true, the stylesheet is minified.
+	Minify bool
+}
+
+// Build compiles the stylesheet with the standalone Tailwind CLI (v4). Tailwind scans the
+// sources as plain text, so classes must appear as complete literals such as
+// h.Class("p-4"), not be assembled at runtime. The classes of the typed helpers of this
+// package are always included.
+func Build(ctx context.Context, opts BuildOptions) error {
+	if opts.Output == "" {
+		return fmt.Errorf("tailwind build: output path is required")
+	}
+	input := opts.Input
+	if input == "" {
+		css, err := inputCSS(opts.Sources)
+		if err != nil {
+			return fmt.Errorf("tailwind build: %w", err)
+		}
+		f, err := os.CreateTemp("", "via-tailwind-*.css")
+		if err != nil {
+			return fmt.Errorf("tailwind build: %w", err)
+		}
+		defer os.Remove(f.Name())
+		if _, err := f.WriteString(css); err != nil {
+			f.Close()
+			return fmt.Errorf("tailwind build: %w", err)
+		}
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("tailwind build: %w", err)
+		}
+		input = f.Name()
+	}
+
+	binary := opts.Binary
+	if binary == "" {
+		binary = "tailwindcss"
+	}
+	args := []string{"-i", input, "-o", opts.Output}
+	if opts.Minify {
+		args = append(args, "--minify")
+	}
+	cmd := exec.CommandContext(ctx, binary, args...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("tailwind build: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// inputCSS returns the generated entry file that imports Tailwind, scans the given
+// directories and includes the classes of the typed helpers.
+func inputCSS(sources []string) (string, error) {
+	if len(sources) == 0 {
+		sources = []string{"."}
+	}
+	var b strings.Builder
+	b.WriteString("@import \"tailwindcss\" source(none);\n")
+	for _, src := range sources {
+		abs, err := filepath.Abs(src)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "@source %q;\n", filepath.ToSlash(abs))
+	}
+	names := make([]string, len(helperClasses))
+	for i, c := range helperClasses {
+		names[i] = string(c)
+	}
+	fmt.Fprintf(&b, "@source inline(%q);\n", strings.Join(names, " "))
+	return b.String(), nil
+}
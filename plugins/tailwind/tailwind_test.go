@@ -0,0 +1,103 @@
+package tailwind
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/go-via/via"
+	"github.com/go-via/via/h"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClasses(t *testing.T) {
+	testcases := []struct {
+		desc     string
+		classes  []Class
+		expected string
+	}{
+		{"none", nil, ` class=""`},
+		{"helpers", []Class{Flex, ItemsCenter}, ` class="flex items-center"`},
+		{"literal classes", []Class{Grid, "gap-4 p-4"}, ` class="grid gap-4 p-4"`},
+		{"empty classes skipped", []Class{"", Hidden, ""}, ` class="hidden"`},
+	}
+	for _, testcase := range testcases {
+		t.Run(testcase.desc, func(t *testing.T) {
+			html, err := h.Render(Classes(testcase.classes...))
+			assert.NoError(t, err)
+			assert.Equal(t, testcase.expected, html)
+		})
+	}
+}
+
+func TestInputCSS(t *testing.T) {
+	wd, err := os.Getwd()
+	assert.NoError(t, err)
+	testcases := []struct {
+		desc    string
+		sources []string
+		scanned []string
+	}{
+		{"working directory by default", nil, []string{wd}},
+		{"relative sources", []string{"pages", "../ui"}, []string{filepath.Join(wd, "pages"), filepath.Join(filepath.Dir(wd), "ui")}},
+		{"absolute source", []string{filepath.Join(wd, "app")}, []string{filepath.Join(wd, "app")}},
+	}
+	for _, testcase := range testcases {
+		t.Run(testcase.desc, func(t *testing.T) {
+			css, err := inputCSS(testcase.sources)
+			assert.NoError(t, err)
+			lines := strings.Split(strings.TrimSuffix(css, "\n"), "\n")
+			assert.Equal(t, `@import "tailwindcss" source(none);`, lines[0])
+			var expected []string
+			for _, src := range testcase.scanned {
+				expected = append(expected, `@source "`+filepath.ToSlash(src)+`";`)
+			}
+			assert.Equal(t, expected, lines[1:len(lines)-1])
+			assert.Equal(t, `@source inline("block inline-block inline hidden flex inline-flex grid flex-row flex-col flex-wrap flex-1 grow shrink-0 items-start items-center items-end items-stretch justify-start justify-center justify-end justify-between relative absolute fixed sticky w-full h-full min-h-screen mx-auto truncate sr-only rounded shadow border font-bold text-sm text-lg text-center");`, lines[len(lines)-1])
+		})
+	}
+}
+
+func TestWithOptions(t *testing.T) {
+	t.Chdir(t.TempDir())
+	stylesheet := filepath.Join(t.TempDir(), "app.css")
+	assert.NoError(t, os.WriteFile(stylesheet, []byte(".p-4{padding:1rem}"), 0o644))
+	testcases := []struct {
+		desc    string
+		devMode bool
+		opts    Options
+		script  bool
+	}{
+		{"browser build in DevMode", true, Options{Stylesheet: stylesheet}, true},
+		{"browser build without stylesheet", false, Options{}, true},
+		{"built stylesheet", false, Options{Stylesheet: stylesheet}, false},
+	}
+	for _, testcase := range testcases {
+		t.Run(testcase.desc, func(t *testing.T) {
+			v := via.New()
+			v.Config(via.Options{DevMode: testcase.devMode, CSP: via.NewCSP(), Plugins: []via.Plugin{WithOptions(testcase.opts)}})
+			v.Page("/", func(c *via.Context) { c.View(func() h.H { return h.Div() }) })
+
+			w := httptest.NewRecorder()
+			v.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+			body := w.Body.String()
+			nonce := regexp.MustCompile(`'nonce-([^']+)'`).FindStringSubmatch(w.Header().Get("Content-Security-Policy"))
+			if testcase.script {
+				if assert.Len(t, nonce, 2) {
+					assert.Contains(t, body, `<script nonce="`+nonce[1]+`" src="`+BrowserScriptURL+`"></script>`)
+				}
+				assert.NotContains(t, body, stylesheetRoute)
+				return
+			}
+			assert.NotContains(t, body, BrowserScriptURL)
+			assert.Contains(t, body, `<link rel="stylesheet" href="`+stylesheetRoute+`">`)
+			w = httptest.NewRecorder()
+			v.ServeHTTP(w, httptest.NewRequest("GET", stylesheetRoute, nil))
+			assert.Equal(t, ".p-4{padding:1rem}", w.Body.String())
+			assert.Equal(t, "text/css; charset=utf-8", w.Header().Get("Content-Type"))
+		})
+	}
+}
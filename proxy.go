@@ -0,0 +1,93 @@
+package via
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// parseTrustedProxies parses the given CIDRs or single IP addresses.
+func (v *V) parseTrustedProxies(cidrs []string) []netip.Prefix {
+	prefixes := make([]netip.Prefix, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		if p, err := netip.ParsePrefix(cidr); err == nil {
+			prefixes = append(prefixes, p.Masked())
+			continue
+		}
+		if addr, err := netip.ParseAddr(cidr); err == nil {
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		v.logErr(nil, "invalid trusted proxy '%s' ignored", cidr)
+	}
+	return prefixes
+}
+
+func (v *V) isTrustedProxy(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, p := range v.trustedProxies {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteIP returns the IP of the direct peer of the request.
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// clientIP returns the IP of the client that sent the request. If the request comes
+// from a trusted proxy, the X-Forwarded-For header is walked from the right, skipping
+// trusted proxies, and the first untrusted address is returned.
+func (v *V) clientIP(r *http.Request) string {
+	ip := remoteIP(r)
+	if !v.isTrustedProxy(ip) {
+		return ip
+	}
+	var hops []string
+	for _, h := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(h, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			continue
+		}
+		ip = hop
+		if !v.isTrustedProxy(hop) {
+			break
+		}
+	}
+	return ip
+}
+
+// baseURL returns the scheme and host the client used to reach the app, e.g.
+// 'https://example.com'. X-Forwarded-Proto and X-Forwarded-Host are honored if the
+// request comes from a trusted proxy.
+func (v *V) baseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	host := r.Host
+	if v.isTrustedProxy(remoteIP(r)) {
+		if p := r.Header.Get("X-Forwarded-Proto"); p == "http" || p == "https" {
+			scheme = p
+		}
+		if h := r.Header.Get("X-Forwarded-Host"); h != "" {
+			host = strings.TrimSpace(strings.Split(h, ",")[0])
+		}
+	}
+	return scheme + "://" + host
+}
@@ -0,0 +1,168 @@
+package via
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// RecordedAction is an action invocation recorded in DevMode. Actions and signals are
+// identified by the order in which the page registered them, so the recording can be
+// replayed on a fresh context whose IDs differ.
+type RecordedAction struct {
+	Time time.Time `json:"time"`
+	// The index of the action in registration order.
+	Action int `json:"action"`
+	// The name of an action registered with ActionNamed, or its ID.
+	Name string `json:"name"`
+	// Signal values sent by the browser, by index of the signal in registration order.
+	Signals map[int]any `json:"signals"`
+}
+
+// Recording holds the actions invoked on a context, see Context.Recording.
+type Recording struct {
+	// The page route and the request URI of the page load, e.g. '/users/{id}' and
+	// '/users/7?tab=profile'.
+	Route      string           `json:"route"`
+	RequestURI string           `json:"request_uri"`
+	Actions    []RecordedAction `json:"actions"`
+}
+
+// record appends the invocation of an action with the signals sent by the browser to
+// the recording of the context. Only DevMode records actions.
+func (c *Context) record(actionID string, sigs map[string]any) {
+	if !c.app.cfg.DevMode {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	rec := RecordedAction{Time: time.Now(), Action: slices.Index(c.actionOrder, actionID), Name: actionID, Signals: make(map[int]any)}
+	if name, ok := c.actionNames[actionID]; ok {
+		rec.Name = name
+	}
+	for id, val := range sigs {
+		if i := slices.Index(c.signalOrder, id); i >= 0 {
+			rec.Signals[i] = val
+		}
+	}
+	c.recording = append(c.recording, rec)
+}
+
+// Recording returns the actions invoked on the page of this context in DevMode, which
+// can be replayed with V.Replay to reproduce an interaction bug. In DevMode, it is also
+// served as JSON at /_recording?id=<context ID>.
+func (c *Context) Recording() Recording {
+	if c.isComponent() {
+		c = c.parentPageCtx
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return Recording{Route: c.route, RequestURI: c.requestURI, Actions: slices.Clone(c.recording)}
+}
+
+// Replay loads the page of the recording in a fresh context and invokes the recorded
+// actions with their signals in order, e.g. in a test. Actions run without timeouts and
+// patches are discarded; inspect the returned context, e.g. by rendering its view.
+// Replay requires DevMode. In DevMode, a recording posted as JSON to /_replay is
+// replayed and the rendered view is returned.
+func (v *V) Replay(rec Recording) (*Context, error) {
+	if !v.cfg.DevMode {
+		return nil, fmt.Errorf("replay failed: requires DevMode")
+	}
+	initContextFn, ok := v.devModePageInitFnMap[rec.Route]
+	if !ok {
+		return nil, fmt.Errorf("replay failed: page route '%s' not found", rec.Route)
+	}
+	c := newContext(rec.Route+"_/replay-"+v.genContextID(), rec.Route, v)
+	c.requestURI = rec.RequestURI
+	path, query, _ := strings.Cut(rec.RequestURI, "?")
+	route := rec.Route
+	if !strings.HasPrefix(route, "/") {
+		_, route, _ = strings.Cut(route, "/")
+		route = "/" + route
+	}
+	c.injectRouteParams(extractParams(route, path))
+	if q, err := url.ParseQuery(query); err == nil {
+		c.injectQueryParams(q)
+	}
+	initContextFn(c)
+
+	for i, a := range rec.Actions {
+		if a.Action < 0 || a.Action >= len(c.actionOrder) {
+			c.dispose()
+			return nil, fmt.Errorf("replay failed: action %d (%s) not found", i, a.Name)
+		}
+		id := c.actionOrder[a.Action]
+		sigs := make(map[string]any, len(a.Signals))
+		for idx, val := range a.Signals {
+			if idx >= 0 && idx < len(c.signalOrder) {
+				sigs[c.signalOrder[idx]] = val
+			}
+		}
+		c.injectSignals(sigs)
+		f, _ := c.getActionFn(id)
+		err := func() (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					err = panicErr(r)
+				}
+			}()
+			f()
+			return nil
+		}()
+		if err != nil {
+			c.dispose()
+			return nil, fmt.Errorf("replay failed: action %d (%s): %w", i, a.Name, err)
+		}
+	}
+	return c, nil
+}
+
+// serveRecording serves the recording of a live context as JSON in DevMode.
+func (v *V) serveRecording(w http.ResponseWriter, r *http.Request) {
+	if !v.cfg.DevMode {
+		http.NotFound(w, r)
+		return
+	}
+	c, err := v.getCtx(r.URL.Query().Get("id"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(c.Recording())
+}
+
+// serveReplay replays a recording posted as JSON and responds with the rendered view
+// in DevMode.
+func (v *V) serveReplay(w http.ResponseWriter, r *http.Request) {
+	if !v.cfg.DevMode {
+		http.NotFound(w, r)
+		return
+	}
+	var rec Recording
+	if err := json.NewDecoder(r.Body).Decode(&rec); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	c, err := v.Replay(rec)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	defer c.dispose()
+	html, err := c.renderView()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = io.WriteString(w, html)
+}
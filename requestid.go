@@ -0,0 +1,37 @@
+package via
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// maxRequestIDLen is the maximum length of a X-Request-ID header value that is honored.
+const maxRequestIDLen = 128
+
+// requestID returns the X-Request-ID of the request, or a new random ID if the header
+// is missing or invalid, and sets it on the response.
+func requestID(w http.ResponseWriter, r *http.Request) string {
+	id := r.Header.Get("X-Request-ID")
+	if !validRequestID(id) {
+		b := make([]byte, 16)
+		rand.Read(b)
+		id = hex.EncodeToString(b)
+	}
+	w.Header().Set("X-Request-ID", id)
+	return id
+}
+
+// validRequestID reports whether id is non-empty, not too long and only contains
+// printable ASCII characters other than space, so it is safe to log.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
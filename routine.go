@@ -43,7 +43,10 @@ func (r *OnIntervalRoutine) Stop() {
 	if !r.isRunning.CompareAndSwap(true, false) || r.routineFn == nil {
 		return
 	}
-	r.localInterrupt <- struct{}{}
+	select {
+	case r.localInterrupt <- struct{}{}:
+	case <-r.ctxDisposed: // the routine stopped with the context
+	}
 }
 
 func newOnIntervalRoutine(ctxDisposedChan chan struct{},
@@ -0,0 +1,230 @@
+package via
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSchedule is a parsed cron expression. Each field is a bit set of the values that
+// match.
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	// domStar and dowStar record unrestricted day fields: if both day fields are
+	// restricted, a day matches if either matches.
+	domStar, dowStar bool
+	every            time.Duration
+}
+
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// parseCron parses a standard five field cron expression (minute, hour, day of month,
+// month, day of week), a descriptor such as "@hourly", or "@every <duration>".
+func parseCron(spec string) (*cronSchedule, error) {
+	spec = strings.TrimSpace(spec)
+	if d, ok := strings.CutPrefix(spec, "@every "); ok {
+		every, err := time.ParseDuration(strings.TrimSpace(d))
+		if err != nil || every <= 0 {
+			return nil, fmt.Errorf("cron '%s': invalid duration", spec)
+		}
+		return &cronSchedule{every: every}, nil
+	}
+	if expr, ok := cronDescriptors[spec]; ok {
+		spec = expr
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron '%s': expected 5 fields, got %d", spec, len(fields))
+	}
+	var s cronSchedule
+	var err error
+	if s.minute, err = parseCronField(fields[0], 0, 59); err != nil {
+		return nil, fmt.Errorf("cron '%s': minute: %w", spec, err)
+	}
+	if s.hour, err = parseCronField(fields[1], 0, 23); err != nil {
+		return nil, fmt.Errorf("cron '%s': hour: %w", spec, err)
+	}
+	if s.dom, err = parseCronField(fields[2], 1, 31); err != nil {
+		return nil, fmt.Errorf("cron '%s': day of month: %w", spec, err)
+	}
+	if s.month, err = parseCronField(fields[3], 1, 12); err != nil {
+		return nil, fmt.Errorf("cron '%s': month: %w", spec, err)
+	}
+	if s.dow, err = parseCronField(fields[4], 0, 7); err != nil {
+		return nil, fmt.Errorf("cron '%s': day of week: %w", spec, err)
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1 // 7 is Sunday, like 0
+	}
+	s.domStar = fields[2] == "*"
+	s.dowStar = fields[4] == "*"
+	return &s, nil
+}
+
+// parseCronField parses a comma separated list of values, ranges 'a-b', '*' and
+// steps '/n' into a bit set.
+func parseCronField(field string, lo, hi int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step '%s'", stepStr)
+			}
+			step = n
+		}
+		start, end := lo, hi
+		if rng != "*" {
+			a, b, isRange := strings.Cut(rng, "-")
+			var err error
+			if start, err = strconv.Atoi(a); err != nil {
+				return 0, fmt.Errorf("invalid value '%s'", a)
+			}
+			end = start
+			if isRange {
+				if end, err = strconv.Atoi(b); err != nil {
+					return 0, fmt.Errorf("invalid value '%s'", b)
+				}
+			} else if hasStep {
+				end = hi
+			}
+		}
+		if start < lo || end > hi || start > end {
+			return 0, fmt.Errorf("'%s' out of range [%d, %d]", part, lo, hi)
+		}
+		for i := start; i <= end; i += step {
+			bits |= 1 << i
+		}
+	}
+	return bits, nil
+}
+
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<t.Day()) != 0
+	dow := s.dow&(1<<int(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// next returns the first time after t that matches the schedule, or the zero time if
+// there is none within five years.
+func (s *cronSchedule) next(t time.Time) time.Time {
+	if s.every > 0 {
+		return t.Add(s.every)
+	}
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if s.month&(1<<int(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if s.hour&(1<<t.Hour()) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if s.minute&(1<<t.Minute()) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+type scheduledJob struct {
+	spec     string
+	schedule *cronSchedule
+	fn       func()
+}
+
+// Schedule runs f on the given cron schedule while the app is served, e.g. to refresh
+// app-level state and push it to live contexts. The schedule is a standard five field
+// cron expression in local time (minute, hour, day of month, month, day of week), a
+// descriptor such as "@hourly" or "@daily", or "@every <duration>".
+// Jobs start with Start or ServeListener and stop on Shutdown. Runs of a job do not
+// overlap; a run that is due while the previous one is running is skipped.
+// Returns an error if the schedule is invalid.
+//
+// Example:
+//
+//	v.Schedule("*/5 * * * *", func() {
+//		stats.Refresh()
+//	})
+func (v *V) Schedule(spec string, f func()) error {
+	schedule, err := parseCron(spec)
+	if err != nil {
+		return err
+	}
+	job := &scheduledJob{spec: spec, schedule: schedule, fn: f}
+	v.serverMu.Lock()
+	defer v.serverMu.Unlock()
+	v.scheduledJobs = append(v.scheduledJobs, job)
+	if v.schedulerStarted {
+		go v.runScheduledJob(job)
+	}
+	return nil
+}
+
+// startScheduler starts the scheduled jobs and change feeds once.
+func (v *V) startScheduler() {
+	v.serverMu.Lock()
+	defer v.serverMu.Unlock()
+	if v.schedulerStarted {
+		return
+	}
+	v.schedulerStarted = true
+	for _, job := range v.scheduledJobs {
+		go v.runScheduledJob(job)
+	}
+	for _, sub := range v.changeFeeds {
+		go v.runChangeFeed(sub)
+	}
+}
+
+func (v *V) runScheduledJob(job *scheduledJob) {
+	for {
+		next := job.schedule.next(time.Now())
+		if next.IsZero() {
+			v.logWarn(nil, "scheduled job '%s' stopped: no next run", job.spec)
+			return
+		}
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-v.shutdownChan:
+			timer.Stop()
+			return
+		case <-timer.C:
+			v.runJob(job)
+		}
+	}
+}
+
+// runJob runs a scheduled job and logs its panics.
+func (v *V) runJob(job *scheduledJob) {
+	defer func() {
+		if r := recover(); r != nil {
+			v.logErr(nil, "scheduled job '%s' failed: %v", job.spec, r)
+			v.reportErr(nil, PhaseSchedule, panicErr(r))
+		}
+	}()
+	job.fn()
+}
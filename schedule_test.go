@@ -0,0 +1,56 @@
+package via
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCronNext(t *testing.T) {
+	from := time.Date(2025, time.January, 31, 10, 7, 30, 0, time.UTC)
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2025, time.January, 31, 10, 8, 0, 0, time.UTC)},
+		{"*/5 * * * *", time.Date(2025, time.January, 31, 10, 10, 0, 0, time.UTC)},
+		{"0 9-17 * * *", time.Date(2025, time.January, 31, 11, 0, 0, 0, time.UTC)},
+		{"30 8 * * 1", time.Date(2025, time.February, 3, 8, 30, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 * 5", time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{"15,45 10 * * *", time.Date(2025, time.January, 31, 10, 15, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2025, time.February, 2, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2025, time.January, 31, 11, 0, 0, 0, time.UTC)},
+		{"@every 90s", from.Add(90 * time.Second)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			s, err := parseCron(tt.spec)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, s.next(from))
+		})
+	}
+}
+
+func TestCronInvalid(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "@every -1s", "@often"} {
+		_, err := parseCron(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestScheduleStopsOnShutdown(t *testing.T) {
+	v := New()
+	runs := make(chan struct{}, 10)
+	assert.NoError(t, v.Schedule("@every 10ms", func() { runs <- struct{}{} }))
+	v.startScheduler()
+	<-runs
+	assert.NoError(t, v.Shutdown(t.Context()))
+	time.Sleep(30 * time.Millisecond)
+	for len(runs) > 0 {
+		<-runs
+	}
+	time.Sleep(30 * time.Millisecond)
+	assert.Empty(t, runs)
+}
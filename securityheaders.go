@@ -0,0 +1,82 @@
+package via
+
+import (
+	"net/http"
+	"strings"
+)
+
+// OmitHeader disables a header of SecurityHeaders.
+const OmitHeader = "-"
+
+// SecurityHeaders are response headers that Via sets on every response. Empty fields
+// use the default value; set a field to OmitHeader to not send the header.
+type SecurityHeaders struct {
+	// Strict-Transport-Security, sent on HTTPS requests only.
+	// Default: "max-age=63072000; includeSubDomains".
+	HSTS string
+
+	// X-Content-Type-Options. Default: "nosniff".
+	ContentTypeOptions string
+
+	// Referrer-Policy. Default: "strict-origin-when-cross-origin".
+	ReferrerPolicy string
+
+	// X-Frame-Options. Default: "SAMEORIGIN".
+	FrameOptions string
+}
+
+var defaultSecurityHeaders = SecurityHeaders{
+	HSTS:               "max-age=63072000; includeSubDomains",
+	ContentTypeOptions: "nosniff",
+	ReferrerPolicy:     "strict-origin-when-cross-origin",
+	FrameOptions:       "SAMEORIGIN",
+}
+
+// merge returns s with its empty fields set from base.
+func (s SecurityHeaders) merge(base SecurityHeaders) SecurityHeaders {
+	if s.HSTS == "" {
+		s.HSTS = base.HSTS
+	}
+	if s.ContentTypeOptions == "" {
+		s.ContentTypeOptions = base.ContentTypeOptions
+	}
+	if s.ReferrerPolicy == "" {
+		s.ReferrerPolicy = base.ReferrerPolicy
+	}
+	if s.FrameOptions == "" {
+		s.FrameOptions = base.FrameOptions
+	}
+	return s
+}
+
+// securityHeaders returns the security headers for the request path, applying the
+// override of the longest matching prefix in Options.RouteSecurityHeaders.
+func (v *V) securityHeaders(path string) SecurityHeaders {
+	headers := v.cfg.SecurityHeaders.merge(defaultSecurityHeaders)
+	prefix := ""
+	for p := range v.cfg.RouteSecurityHeaders {
+		if strings.HasPrefix(path, p) && len(p) > len(prefix) {
+			prefix = p
+		}
+	}
+	if prefix != "" {
+		headers = v.cfg.RouteSecurityHeaders[prefix].merge(headers)
+	}
+	return headers
+}
+
+// setSecurityHeaders sets the security headers for the request on the response.
+func (v *V) setSecurityHeaders(w http.ResponseWriter, r *http.Request) {
+	headers := v.securityHeaders(r.URL.Path)
+	set := func(name, value string) {
+		if value != OmitHeader {
+			w.Header().Set(name, value)
+		}
+	}
+	if strings.HasPrefix(v.baseURL(r), "https://") {
+		set("Strict-Transport-Security", headers.HSTS)
+	}
+	set("X-Content-Type-Options", headers.ContentTypeOptions)
+	set("Referrer-Policy", headers.ReferrerPolicy)
+	set("X-Frame-Options", headers.FrameOptions)
+}
@@ -0,0 +1,26 @@
+package via
+
+import (
+	"strings"
+
+	"github.com/go-via/via/seo"
+)
+
+// SEO sets the document title and the search engine and OpenGraph tags of the page, see
+// package seo. Call it in the page init func: the tags are rendered with the initial
+// page only, which is what crawlers see. Paths in Canonical and OGImage are resolved
+// against the base URL of the request.
+func (c *Context) SEO(m seo.Meta) {
+	if c.isComponent() {
+		c.parentPageCtx.SEO(m)
+		return
+	}
+	if strings.HasPrefix(m.Canonical, "/") {
+		m.Canonical = c.baseURL + m.Canonical
+	}
+	if strings.HasPrefix(m.OGImage, "/") {
+		m.OGImage = c.baseURL + m.OGImage
+	}
+	c.title = m.Title
+	c.headTags = m.Tags()
+}
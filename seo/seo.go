@@ -0,0 +1,83 @@
+// Package seo builds the head tags that describe a page to search engines and social
+// networks. Crawlers only see the first HTML response, so the tags are set in the page
+// init func with Context.SEO and rendered with the initial page.
+//
+// Example:
+//
+//	v.Page("/posts/{slug}", func(c *via.Context) {
+//		post := posts[c.GetPathParam("slug")]
+//		c.SEO(seo.Meta{
+//			Title:       post.Title,
+//			Description: post.Summary,
+//			Canonical:   "/posts/" + post.Slug,
+//			OGImage:     post.Cover,
+//		})
+//		c.View(func() h.H { return renderPost(post) })
+//	})
+package seo
+
+import "github.com/go-via/via/h"
+
+// Meta describes a page. Empty fields are omitted.
+type Meta struct {
+	// The document title, also used as the OpenGraph title.
+	Title string
+
+	// A short summary of the page, also used as the OpenGraph description.
+	Description string
+
+	// The canonical URL of the page, also used as the OpenGraph URL. Paths are resolved
+	// against the base URL of the request by Context.SEO.
+	Canonical string
+
+	// The image shown when the page is shared. Paths are resolved like Canonical.
+	OGImage string
+
+	// The OpenGraph type of the page. Default: 'website'.
+	OGType string
+
+	// The name of the site shown when the page is shared.
+	SiteName string
+
+	// Instructions for crawlers, e.g. 'noindex, nofollow'.
+	Robots string
+}
+
+// Tags returns the head elements of the page, except the title.
+func (m Meta) Tags() []h.H {
+	var tags []h.H
+	meta := func(name, content string) {
+		if content != "" {
+			tags = append(tags, h.Meta(h.Attr("name", name), h.Attr("content", content)))
+		}
+	}
+	property := func(name, content string) {
+		if content != "" {
+			tags = append(tags, h.Meta(h.Attr("property", name), h.Attr("content", content)))
+		}
+	}
+
+	meta("description", m.Description)
+	meta("robots", m.Robots)
+	if m.Canonical != "" {
+		tags = append(tags, h.Link(h.Rel("canonical"), h.Href(m.Canonical)))
+	}
+
+	ogType := m.OGType
+	if ogType == "" {
+		ogType = "website"
+	}
+	property("og:type", ogType)
+	property("og:title", m.Title)
+	property("og:description", m.Description)
+	property("og:url", m.Canonical)
+	property("og:image", m.OGImage)
+	property("og:site_name", m.SiteName)
+
+	card := "summary"
+	if m.OGImage != "" {
+		card = "summary_large_image"
+	}
+	meta("twitter:card", card)
+	return tags
+}
@@ -1,9 +1,14 @@
 package via
 
 import (
+	"encoding/json"
 	"fmt"
+	"math"
+	"reflect"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-via/via/h"
 )
@@ -18,6 +23,8 @@ type signal struct {
 	val     any
 	changed bool
 	err     error
+	noJS    bool
+	errSig  *signal
 }
 
 // ID returns the signal ID
@@ -33,14 +40,62 @@ func (s *signal) Err() error {
 	return s.err
 }
 
+// BindOption configures when Bind updates the signal from its input element.
+type BindOption interface {
+	apply(*bindOpts)
+}
+
+type bindOpts struct {
+	debounce time.Duration
+}
+
+type withDebounceOpt struct {
+	d time.Duration
+}
+
+func (o withDebounceOpt) apply(opts *bindOpts) {
+	opts.debounce = o.d
+}
+
+// Debounce delays updating the signal until the input has not changed for the given
+// duration, so actions and expressions that depend on the signal do not run on every
+// keystroke. It applies to inputs with a text-like value.
+func Debounce(d time.Duration) BindOption {
+	return withDebounceOpt{d}
+}
+
 // Bind binds this signal to an input element. When the input changes
 // its value the signal updates in real-time in the browser.
 //
 // Example:
 //
 //	h.Input(h.Type("number"), mysignal.Bind())
-func (s *signal) Bind() h.H {
-	return h.Data("bind", s.id)
+//	h.Input(h.Type("search"), query.Bind(via.Debounce(300*time.Millisecond)))
+func (s *signal) Bind(options ...BindOption) h.H {
+	var opts bindOpts
+	for _, opt := range options {
+		opt.apply(&opts)
+	}
+	if opts.debounce > 0 {
+		return s.withNoJSField(s.bindOn(fmt.Sprintf("input__debounce.%dms", opts.debounce.Milliseconds())))
+	}
+	return s.withNoJSField(h.Data("bind", s.id))
+}
+
+// BindLazy binds this signal to an input element like Bind, but updates the signal
+// only when the input commits its value, e.g. when a text input loses focus.
+// It applies to inputs with a text-like value.
+func (s *signal) BindLazy() h.H {
+	return s.withNoJSField(s.bindOn("change"))
+}
+
+// bindOn binds the signal to the value of the element, updating the signal only
+// on the given event.
+func (s *signal) bindOn(event string) h.H {
+	return h.Group(
+		h.Data("effect", fmt.Sprintf("if(el.value!=$%s)el.value=$%s", s.id, s.id)),
+		h.Data("on:"+event, fmt.Sprintf("$%s=el.value", s.id)),
+	)
 }
 
 // Text binds the signal value to an html span element as text.
@@ -52,6 +107,26 @@ func (s *signal) Text() h.H {
 	return h.Span(h.Data("text", "$"+s.id))
 }
 
+// Attr binds the attribute with the given name to the value of this signal, so it is
+// updated in the browser whenever the signal changes.
+//
+// Example:
+//
+//	h.Progress(h.Attr("max", "100"), progress.Attr("value"))
+func (s *signal) Attr(name string) h.H {
+	return h.DataAttr(name, "$"+s.id)
+}
+
+// Aria binds the ARIA state with the given name to the value of this signal as "true" or
+// "false", e.g. for a boolean signal that tracks whether a menu is open.
+//
+// Example:
+//
+//	h.Button(h.AriaControls("menu"), menuOpen.Aria("expanded"))
+func (s *signal) Aria(name string) h.H {
+	return h.DataAttr("aria-"+name, "String($"+s.id+")")
+}
+
 // SetValue updates the signal’s value and marks it for synchronization with the browser.
 // The change will be propagated to the browser using *Context.Sync() or *Context.SyncSignals().
 func (s *signal) SetValue(v any) {
@@ -60,11 +135,155 @@ func (s *signal) SetValue(v any) {
 	s.err = nil
 }
 
-// String return the signal value as a string.
+// String return the signal value as a string. Slices, maps and structs are returned
+// as JSON.
 func (s *signal) String() string {
+	switch v := s.val.(type) {
+	case float64: // numbers sent by the browser, without exponent notation
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	}
+	switch reflect.ValueOf(s.val).Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.Struct:
+		if j, err := json.Marshal(s.val); err == nil {
+			return string(j)
+		}
+	}
 	return fmt.Sprintf("%v", s.val)
 }
 
+// jsonValue returns the value of the signal as sent to the browser. Booleans, numbers
+// and strings keep their JSON type, so expressions like $count > 3 or $open compare
+// as expected. Slices, maps and structs are sent as JSON arrays and objects. Other
+// values, and values that do not encode as JSON, are sent as strings.
+func (s *signal) jsonValue() any {
+	rv := reflect.ValueOf(s.val)
+	switch rv.Kind() {
+	case reflect.Float32, reflect.Float64:
+		if f := rv.Float(); math.IsInf(f, 0) || math.IsNaN(f) { // not representable in JSON
+			return s.String()
+		}
+		return s.val
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return s.val
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.Struct, reflect.Pointer:
+		if _, err := json.Marshal(s.val); err == nil {
+			return s.val
+		}
+	}
+	return s.String()
+}
+
+// coerceSignalValue converts a value received from the browser, decoded from JSON, to
+// the type of the previous value of the signal, so an int signal stays an int after
+// an action. If the value does not convert, e.g. 4.5 for an int signal, it returns the
+// previous value and an error.
+func coerceSignalValue(prev, val any) (any, error) {
+	if prev == nil {
+		return val, nil
+	}
+	to := reflect.TypeOf(prev)
+	if val == nil {
+		return reflect.Zero(to).Interface(), nil
+	}
+	if reflect.TypeOf(val) == to {
+		return val, nil
+	}
+	invalid := fmt.Errorf("signal value %v is not a valid %s", val, to)
+	switch to.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		switch v := val.(type) {
+		case float64:
+			if v != math.Trunc(v) || v < math.MinInt64 || v >= math.MaxInt64 {
+				return prev, invalid
+			}
+			n = int64(v)
+		case string:
+			i, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return prev, invalid
+			}
+			n = i
+		default:
+			return prev, invalid
+		}
+		if reflect.Zero(to).OverflowInt(n) {
+			return prev, invalid
+		}
+		return reflect.ValueOf(n).Convert(to).Interface(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n uint64
+		switch v := val.(type) {
+		case float64:
+			if v != math.Trunc(v) || v < 0 || v >= math.MaxUint64 {
+				return prev, invalid
+			}
+			n = uint64(v)
+		case string:
+			u, err := strconv.ParseUint(v, 10, 64)
+			if err != nil {
+				return prev, invalid
+			}
+			n = u
+		default:
+			return prev, invalid
+		}
+		if reflect.Zero(to).OverflowUint(n) {
+			return prev, invalid
+		}
+		return reflect.ValueOf(n).Convert(to).Interface(), nil
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		switch v := val.(type) {
+		case float64:
+			f = v
+		case string:
+			p, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return prev, invalid
+			}
+			f = p
+		default:
+			return prev, invalid
+		}
+		return reflect.ValueOf(f).Convert(to).Interface(), nil
+	case reflect.Bool:
+		switch v := val.(type) {
+		case bool:
+			return reflect.ValueOf(v).Convert(to).Interface(), nil
+		case string:
+			if b, err := strconv.ParseBool(v); err == nil {
+				return reflect.ValueOf(b).Convert(to).Interface(), nil
+			}
+		}
+	case reflect.String:
+		switch v := val.(type) {
+		case string:
+			return reflect.ValueOf(v).Convert(to).Interface(), nil
+		case float64:
+			return reflect.ValueOf(strconv.FormatFloat(v, 'f', -1, 64)).Convert(to).Interface(), nil
+		case bool:
+			return reflect.ValueOf(strconv.FormatBool(v)).Convert(to).Interface(), nil
+		}
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.Struct, reflect.Pointer:
+		// decode the JSON value again into the type of the signal
+		j, err := json.Marshal(val)
+		if err != nil {
+			return prev, invalid
+		}
+		ptr := reflect.New(to)
+		if err := json.Unmarshal(j, ptr.Interface()); err != nil {
+			return prev, invalid
+		}
+		return ptr.Elem().Interface(), nil
+	}
+	return prev, invalid
+}
+
 // Bool tries to read the signal value as a bool.
 // Returns the value or false on failure.
 func (s *signal) Bool() bool {
@@ -72,12 +291,17 @@ func (s *signal) Bool() bool {
 	return val == "true" || val == "1" || val == "yes" || val == "on"
 }
 
-// Int tries to read the signal value as an int.
+// Int tries to read the signal value as an int. Fractional values, as sent by the
+// browser for number inputs, are truncated.
 // Returns the value or 0 on failure.
 func (s *signal) Int() int {
 	if n, err := strconv.Atoi(s.String()); err == nil {
 		return n
 	}
+	if f, err := strconv.ParseFloat(s.String(), 64); err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) &&
+		f >= math.MinInt && f <= math.MaxInt {
+		return int(f)
+	}
 	return 0
 }
 
@@ -104,3 +328,255 @@ func (s *signal) Float() float64 {
 func (s *signal) Bytes() []byte {
 	return []byte(s.String())
 }
+
+// SelectOption is an option rendered by the Select and MultiSelect bindings.
+type SelectOption struct {
+	Value string
+	Label string
+}
+
+// Select renders a select element bound to this signal with one option per entry.
+// The signal holds the value of the selected option. Extra attributes, such as
+// an action trigger, are added to the select element.
+//
+// Example:
+//
+//	color.Select([]via.SelectOption{{"r", "Red"}, {"g", "Green"}}, update.OnChange())
+func (s *signal) Select(options []SelectOption, attrs ...h.H) h.H {
+	selected := s.String()
+	children := append([]h.H{s.Bind()}, attrs...)
+	for _, o := range options {
+		children = append(children, h.Option(h.Value(o.Value), h.If(o.Value == selected, h.Attr("selected")), h.Text(o.Label)))
+	}
+	return h.Select(children...)
+}
+
+// MultiSelect renders a select element that allows multiple selections, bound to this
+// signal. The signal holds the values of the selected options as a list of strings;
+// read it with Strings.
+func (s *signal) MultiSelect(options []SelectOption, attrs ...h.H) h.H {
+	selected := s.Strings()
+	children := append([]h.H{h.Attr("multiple"), s.Bind()}, attrs...)
+	for _, o := range options {
+		children = append(children, h.Option(h.Value(o.Value), h.If(slices.Contains(selected, o.Value), h.Attr("selected")), h.Text(o.Label)))
+	}
+	return h.Select(children...)
+}
+
+// DataList renders a text input bound to this signal together with a datalist that
+// suggests the given values while typing.
+func (s *signal) DataList(suggestions []string, attrs ...h.H) h.H {
+	listID := s.id + "-list"
+	opts := make([]h.H, 0, len(suggestions)+1)
+	opts = append(opts, h.ID(listID))
+	for _, v := range suggestions {
+		opts = append(opts, h.Option(h.Value(v)))
+	}
+	input := append([]h.H{h.Attr("list", listID), s.Bind()}, attrs...)
+	return h.Span(h.Input(input...), h.DataList(opts...))
+}
+
+// Strings tries to read the signal value as a list of strings, as set by the browser
+// for a MultiSelect or by the server with a slice value.
+// Returns the value or nil on failure.
+func (s *signal) Strings() []string {
+	switch v := s.val.(type) {
+	case []string:
+		return v
+	case []any:
+		strs := make([]string, 0, len(v))
+		for _, item := range v {
+			strs = append(strs, fmt.Sprintf("%v", item))
+		}
+		return strs
+	case string:
+		var strs []string
+		if err := json.Unmarshal([]byte(v), &strs); err == nil {
+			return strs
+		}
+	}
+	return nil
+}
+
+// Layouts of the values of date and time input elements. Browsers submit wall clock
+// values without a time zone.
+const (
+	DateLayout     = "2006-01-02"
+	TimeLayout     = "15:04"
+	DateTimeLayout = "2006-01-02T15:04"
+)
+
+// DateInput renders an input element of type date bound to this signal.
+// Read the value with Time and set it with SetTime using DateLayout.
+func (s *signal) DateInput(attrs ...h.H) h.H {
+	return h.Input(append([]h.H{h.Type("date"), s.Bind()}, attrs...)...)
+}
+
+// TimeInput renders an input element of type time bound to this signal.
+// Read the value with Time and set it with SetTime using TimeLayout.
+func (s *signal) TimeInput(attrs ...h.H) h.H {
+	return h.Input(append([]h.H{h.Type("time"), s.Bind()}, attrs...)...)
+}
+
+// DateTimeInput renders an input element of type datetime-local bound to this signal.
+// Read the value with Time and set it with SetTime using DateTimeLayout.
+func (s *signal) DateTimeInput(attrs ...h.H) h.H {
+	return h.Input(append([]h.H{h.Type("datetime-local"), s.Bind()}, attrs...)...)
+}
+
+// SetTime sets the signal value to t formatted with the given layout, e.g. DateLayout.
+// Convert t to the time zone of the user with t.In before calling SetTime, since date
+// and time inputs display the wall clock value as is.
+func (s *signal) SetTime(t time.Time, layout string) {
+	s.SetValue(t.Format(layout))
+}
+
+// Time tries to read the signal value as a time.Time in the given location, accepting
+// the values of date, time and datetime-local inputs as well as RFC 3339 timestamps.
+// Values of time inputs have the date of the zero time. A nil location means UTC.
+// Returns the value or the zero time on failure.
+func (s *signal) Time(loc *time.Location) time.Time {
+	if loc == nil {
+		loc = time.UTC
+	}
+	val := s.String()
+	if t, err := time.Parse(time.RFC3339, val); err == nil {
+		return t.In(loc)
+	}
+	for _, layout := range []string{DateTimeLayout, "2006-01-02T15:04:05", DateLayout, TimeLayout, "15:04:05"} {
+		if t, err := time.ParseInLocation(layout, val, loc); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// NumberRange constrains the value of a numeric signal. Min and Max are only
+// enforced if Min is less than Max, and Step only if it is greater than 0.
+type NumberRange struct {
+	Min  float64
+	Max  float64
+	Step float64
+}
+
+func (r NumberRange) bounded() bool {
+	return r.Min < r.Max
+}
+
+func (r NumberRange) attrs() []h.H {
+	var attrs []h.H
+	if r.bounded() {
+		attrs = append(attrs,
+			h.Attr("min", strconv.FormatFloat(r.Min, 'f', -1, 64)),
+			h.Attr("max", strconv.FormatFloat(r.Max, 'f', -1, 64)))
+	}
+	if r.Step > 0 {
+		attrs = append(attrs, h.Attr("step", strconv.FormatFloat(r.Step, 'f', -1, 64)))
+	}
+	return attrs
+}
+
+// NumberInput renders an input element of type number bound to this signal, with the
+// min, max and step attributes of the given range.
+func (s *signal) NumberInput(r NumberRange, attrs ...h.H) h.H {
+	return h.Input(append(append([]h.H{h.Type("number"), s.Bind()}, r.attrs()...), attrs...)...)
+}
+
+// RangeInput renders an input element of type range bound to this signal, with the
+// min, max and step attributes of the given range.
+func (s *signal) RangeInput(r NumberRange, attrs ...h.H) h.H {
+	return h.Input(append(append([]h.H{h.Type("range"), s.Bind()}, r.attrs()...), attrs...)...)
+}
+
+// FloatIn reads the signal value as a float64 and checks it against the given range.
+// Browsers enforce min and max only on form submission, so values sent with actions
+// must be validated on the server.
+// Returns an error if the value is not a number or is out of range. The problem is
+// also set on the error signal of s, if any, see Context.ErrorSignal.
+func (s *signal) FloatIn(r NumberRange) (float64, error) {
+	f, problem := r.check(s.String())
+	return f, s.validated(problem)
+}
+
+// IntIn reads the signal value as an int and checks it against the given range.
+// Returns an error if the value is not an integer or is out of range. The problem is
+// also set on the error signal of s, if any, see Context.ErrorSignal.
+func (s *signal) IntIn(r NumberRange) (int, error) {
+	f, problem := r.check(s.String())
+	if problem != "" && f == 0 {
+		return 0, s.validated(problem)
+	}
+	if f != math.Trunc(f) {
+		return 0, s.validated(fmt.Sprintf("value %v is not an integer", f))
+	}
+	return int(f), s.validated(problem)
+}
+
+// check parses a number and checks it against the range. It returns the problem with
+// the value, or "" if it is valid.
+func (r NumberRange) check(val string) (float64, string) {
+	f, err := strconv.ParseFloat(val, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, fmt.Sprintf("value %q is not a number", val)
+	}
+	if r.bounded() && (f < r.Min || f > r.Max) {
+		return f, fmt.Sprintf("value %v is out of range [%v, %v]", f, r.Min, r.Max)
+	}
+	return f, ""
+}
+
+// validated sets the error signal of s, if any, to the problem with its value and
+// returns the problem as an error, or nil if problem is "".
+func (s *signal) validated(problem string) error {
+	if s.errSig != nil && s.errSig.String() != problem {
+		s.errSig.SetValue(problem)
+	}
+	if problem == "" {
+		return nil
+	}
+	return fmt.Errorf("signal '%s': %s", s.id, problem)
+}
+
+// ErrorSignal returns a signal that holds the problem found by the last IntIn or
+// FloatIn check of sig, or "" if its value was valid, so the view can show the error
+// next to the input. Like other signals, it is sent to the browser with Sync or
+// SyncSignals.
+//
+// Example:
+//
+//	qty := c.Signal(1)
+//	qtyErr := c.ErrorSignal(qty)
+//	order := c.Action(func() {
+//		n, err := qty.IntIn(via.NumberRange{Min: 1, Max: 10})
+//		if err == nil {
+//			place(n)
+//		}
+//		c.SyncSignals()
+//	})
+//	c.View(func() h.H {
+//		return h.Div(qty.NumberInput(via.NumberRange{Min: 1, Max: 10}), h.Span(h.Role("alert"), qtyErr.Text()),
+//			h.Button(h.Text("Order"), order.OnClick()))
+//	})
+func (c *Context) ErrorSignal(sig *signal) *signal {
+	if sig.errSig == nil {
+		sig.errSig = c.Signal("")
+	}
+	return sig.errSig
+}
+
+// Clamp reads the signal value as a float64 and limits it to the given range. If the
+// value is changed, the corrected value is marked for synchronization with the browser.
+// Values that are not a number are set to the range minimum.
+func (s *signal) Clamp(r NumberRange) float64 {
+	f, err := strconv.ParseFloat(s.String(), 64)
+	clamped := f
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		clamped = r.Min
+	} else if r.bounded() {
+		clamped = min(max(f, r.Min), r.Max)
+	}
+	if err != nil || clamped != f {
+		s.SetValue(clamped)
+	}
+	return clamped
+}
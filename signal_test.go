@@ -1,8 +1,10 @@
 package via
 
 import (
+	"bytes"
 	//	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/go-via/via/h"
 	"github.com/stretchr/testify/assert"
@@ -67,3 +69,128 @@ func TestSignalReturnAsStringComplexTypes(t *testing.T) {
 		})
 	}
 }
+
+func TestSignalStrings(t *testing.T) {
+	testcases := []struct {
+		desc     string
+		given    any
+		expected []string
+	}{
+		{"string slice", []string{"a", "b"}, []string{"a", "b"}},
+		{"injected slice", []any{"a", 1.5}, []string{"a", "1.5"}},
+		{"json string", `["a","b"]`, []string{"a", "b"}},
+		{"plain string", "a", nil},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.desc, func(t *testing.T) {
+			sig := &signal{id: "sig", val: testcase.given}
+			assert.Equal(t, testcase.expected, sig.Strings())
+		})
+	}
+}
+
+func TestSignalSelect(t *testing.T) {
+	sig := &signal{id: "sig", val: "g"}
+	b := bytes.NewBuffer(nil)
+	_ = sig.Select([]SelectOption{{"r", "Red"}, {"g", "Green"}}).Render(b)
+	assert.Contains(t, b.String(), `data-bind="sig"`)
+	assert.Contains(t, b.String(), `<option value="g" selected>Green</option>`)
+}
+
+func TestSignalAttrBindings(t *testing.T) {
+	sig := &signal{id: "open", val: false}
+	b := bytes.NewBuffer(nil)
+	_ = h.Button(sig.Aria("expanded"), sig.Attr("title"), h.Aria("bad\" onclick=\"x", "v")).Render(b)
+	assert.Equal(t, `<button data-attr:aria-expanded="String($open)" data-attr:title="$open" aria-badonclickx="v"></button>`, b.String())
+}
+
+func TestSignalTime(t *testing.T) {
+	loc := time.FixedZone("test", 2*60*60)
+	testcases := []struct {
+		desc     string
+		given    string
+		expected time.Time
+	}{
+		{"date", "2025-03-04", time.Date(2025, 3, 4, 0, 0, 0, 0, loc)},
+		{"time", "13:45", time.Date(0, 1, 1, 13, 45, 0, 0, loc)},
+		{"datetime-local", "2025-03-04T13:45", time.Date(2025, 3, 4, 13, 45, 0, 0, loc)},
+		{"datetime-local with seconds", "2025-03-04T13:45:10", time.Date(2025, 3, 4, 13, 45, 10, 0, loc)},
+		{"rfc3339", "2025-03-04T11:45:00Z", time.Date(2025, 3, 4, 13, 45, 0, 0, loc)},
+		{"invalid", "yesterday", time.Time{}},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.desc, func(t *testing.T) {
+			sig := &signal{id: "sig", val: testcase.given}
+			assert.True(t, testcase.expected.Equal(sig.Time(loc)), "got %v", sig.Time(loc))
+		})
+	}
+}
+
+func TestSignalNumberRange(t *testing.T) {
+	r := NumberRange{Min: 1, Max: 10, Step: 1}
+	testcases := []struct {
+		desc      string
+		given     any
+		expected  int
+		expectErr bool
+		clamped   float64
+	}{
+		{"int in range", 5, 5, false, 5},
+		{"injected float", 5.0, 5, false, 5},
+		{"string", "7", 7, false, 7},
+		{"below min", 0, 0, true, 1},
+		{"above max", 11.0, 11, true, 10},
+		{"fraction", 2.5, 0, true, 2.5},
+		{"not a number", "abc", 0, true, 1},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.desc, func(t *testing.T) {
+			sig := &signal{id: "sig", val: testcase.given}
+			n, err := sig.IntIn(r)
+			assert.Equal(t, testcase.expected, n)
+			assert.Equal(t, testcase.expectErr, err != nil)
+			assert.Equal(t, testcase.clamped, sig.Clamp(r))
+		})
+	}
+
+	// problems are set on the error signal
+	var qty, qtyErr *signal
+	v := New()
+	v.Page("/", func(c *Context) {
+		qty = c.Signal(5)
+		qtyErr = c.ErrorSignal(qty)
+		assert.Same(t, qtyErr, c.ErrorSignal(qty))
+		c.View(func() h.H { return h.Span(qtyErr.Text()) })
+	})
+	_, err := qty.IntIn(r)
+	assert.NoError(t, err)
+	assert.Equal(t, "", qtyErr.String())
+	qty.SetValue(11)
+	_, err = qty.IntIn(r)
+	assert.EqualError(t, err, "signal '"+qty.ID()+"': value 11 is out of range [1, 10]")
+	assert.Equal(t, "value 11 is out of range [1, 10]", qtyErr.String())
+	assert.True(t, qtyErr.changed)
+	qty.SetValue("x")
+	_, err = qty.FloatIn(r)
+	assert.Error(t, err)
+	assert.Equal(t, `value "x" is not a number`, qtyErr.String())
+	qty.SetValue(3)
+	_, err = qty.FloatIn(r)
+	assert.NoError(t, err)
+	assert.Equal(t, "", qtyErr.String())
+}
+
+func TestSignalBindModes(t *testing.T) {
+	sig := &signal{id: "sig", val: ""}
+	render := func(attr h.H) string {
+		b := bytes.NewBuffer(nil)
+		_ = h.Input(attr).Render(b)
+		return b.String()
+	}
+	assert.Equal(t, `<input data-bind="sig">`, render(sig.Bind()))
+	assert.Contains(t, render(sig.Bind(Debounce(300*time.Millisecond))), `data-on:input__debounce.300ms="$sig=el.value"`)
+	assert.Contains(t, render(sig.BindLazy()), `data-on:change="$sig=el.value"`)
+}
@@ -0,0 +1,143 @@
+package via
+
+import (
+	"encoding/xml"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SitemapEntry is a URL listed in the sitemap.
+type SitemapEntry struct {
+	// The path of the page, e.g. '/posts/hello', or an absolute URL.
+	Loc string
+
+	// The time the page was last modified. Omitted if zero.
+	LastMod time.Time
+
+	// How often the page is likely to change, e.g. 'daily'. Omitted if empty.
+	ChangeFreq string
+
+	// The priority of the page relative to other pages of the site, between 0 and 1.
+	// Omitted if 0.
+	Priority float64
+}
+
+// SitemapOptions configures the sitemap served by V.Sitemap.
+type SitemapOptions struct {
+	// The route of the sitemap. Default: '/sitemap.xml'.
+	Path string
+
+	// The scheme and host of the listed URLs, e.g. 'https://example.com'.
+	// Default: the base URL of the request, see Context.BaseURL.
+	BaseURL string
+
+	// Returns the entry of a page route without path parameters, e.g. to set its
+	// priority, or false to leave the route out. By default all such routes are listed.
+	Route func(route string) (SitemapEntry, bool)
+
+	// Enumerates the URLs of page routes with path parameters, keyed by route, e.g.
+	// "/posts/{slug}". Routes with path parameters are left out unless enumerated.
+	Expand map[string]func() []SitemapEntry
+}
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc        string `xml:"loc"`
+	LastMod    string `xml:"lastmod,omitempty"`
+	ChangeFreq string `xml:"changefreq,omitempty"`
+	Priority   string `xml:"priority,omitempty"`
+}
+
+// Sitemap serves a sitemap.xml of the page routes registered with Page. Entries are
+// built on each request, so enumerated URLs stay current. Pages of V.Host groups are
+// listed only in the sitemap of their host.
+//
+// Example:
+//
+//	v.Sitemap(via.SitemapOptions{
+//		BaseURL: "https://example.com",
+//		Expand: map[string]func() []via.SitemapEntry{
+//			"/posts/{slug}": func() []via.SitemapEntry {
+//				var entries []via.SitemapEntry
+//				for _, p := range posts {
+//					entries = append(entries, via.SitemapEntry{Loc: "/posts/" + p.Slug, LastMod: p.Updated})
+//				}
+//				return entries
+//			},
+//		},
+//	})
+func (v *V) Sitemap(opts SitemapOptions) {
+	path := opts.Path
+	if path == "" {
+		path = "/sitemap.xml"
+	}
+	v.mux.HandleFunc("GET "+path, func(w http.ResponseWriter, r *http.Request) {
+		base := opts.BaseURL
+		if base == "" {
+			base = v.baseURL(r)
+		}
+		base = strings.TrimSuffix(base, "/")
+
+		set := sitemapURLSet{Xmlns: "http://www.sitemaps.org/schemas/sitemap/0.9"}
+		add := func(e SitemapEntry) {
+			u := sitemapURL{Loc: e.Loc, ChangeFreq: e.ChangeFreq}
+			if strings.HasPrefix(u.Loc, "/") {
+				u.Loc = base + u.Loc
+			}
+			if !e.LastMod.IsZero() {
+				u.LastMod = e.LastMod.UTC().Format(time.RFC3339)
+			}
+			if e.Priority > 0 {
+				u.Priority = strconv.FormatFloat(min(e.Priority, 1), 'f', 1, 64)
+			}
+			set.URLs = append(set.URLs, u)
+		}
+		for _, route := range v.pageRoutes {
+			if !strings.HasPrefix(route, "/") {
+				// Pages of other hosts are left out of the sitemap of this host.
+				host, path, _ := strings.Cut(route, "/")
+				if host != hostWithoutPort(r.Host) {
+					continue
+				}
+				route = "/" + path
+			}
+			if expand, ok := opts.Expand[route]; ok {
+				for _, e := range expand() {
+					add(e)
+				}
+				continue
+			}
+			if strings.Contains(strings.TrimSuffix(route, "{$}"), "{") {
+				continue
+			}
+			e := SitemapEntry{Loc: strings.TrimSuffix(route, "{$}")}
+			if opts.Route != nil {
+				var ok bool
+				if e, ok = opts.Route(route); !ok {
+					continue
+				}
+				if e.Loc == "" {
+					e.Loc = strings.TrimSuffix(route, "{$}")
+				}
+			}
+			add(e)
+		}
+
+		out, err := xml.MarshalIndent(set, "", "  ")
+		if err != nil {
+			v.logErr(nil, "sitemap failed: %v", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+		_, _ = w.Write([]byte(xml.Header))
+		_, _ = w.Write(out)
+	})
+}
@@ -0,0 +1,71 @@
+package via
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/starfederation/datastar-go/datastar"
+)
+
+// SSEOptions tunes the SSE stream that carries patches to the browser, e.g. for
+// reverse proxies that buffer or time out long-lived responses. Every patch is flushed
+// to the client as soon as it is written.
+type SSEOptions struct {
+	// How long the browser waits before reconnecting a dropped stream.
+	// Default: Datastar's default of 1 second.
+	RetryInterval time.Duration
+
+	// The maximum time to write a patch. Streams of clients that stop reading are
+	// closed once it is exceeded. Disabled if 0.
+	WriteTimeout time.Duration
+
+	// The number of patches buffered per page while the stream is busy or not yet
+	// connected. Patches beyond the buffer are dropped. Default: 1.
+	PatchBuffer int
+}
+
+// patchBufferSize returns the capacity of the patch channel of a context.
+func (o SSEOptions) patchBufferSize() int {
+	return max(o.PatchBuffer, 1)
+}
+
+// writePatch writes a patch to the SSE stream of the context.
+func (v *V) writePatch(sse *datastar.ServerSentEventGenerator, c *Context, p patch) error {
+	retry := v.cfg.SSE.RetryInterval
+	switch p.typ {
+	case patchTypeElements:
+		opts := []datastar.PatchElementOption{datastar.WithUseViewTransitions(v.cfg.ViewTransitions)}
+		if p.selector != "" {
+			opts = append(opts, datastar.WithSelector(p.selector))
+		}
+		if p.mode != "" {
+			opts = append(opts, datastar.WithMode(p.mode))
+		}
+		if retry > 0 {
+			opts = append(opts, datastar.WithRetryDuration(retry))
+		}
+		if err := sse.PatchElements(p.content, opts...); err != nil {
+			return fmt.Errorf("PatchElements failed: %w", err)
+		}
+	case patchTypeSignals:
+		var opts []datastar.PatchSignalsOption
+		if retry > 0 {
+			opts = append(opts, datastar.WithPatchSignalsRetryDuration(retry))
+		}
+		if err := sse.PatchSignals([]byte(p.content), opts...); err != nil {
+			return fmt.Errorf("PatchSignals failed: %w", err)
+		}
+	case patchTypeScript:
+		opts := []datastar.ExecuteScriptOption{datastar.WithExecuteScriptAutoRemove(true)}
+		if c.nonce != "" {
+			opts = append(opts, datastar.WithExecuteScriptAttributes(fmt.Sprintf("nonce=%q", c.nonce)))
+		}
+		if retry > 0 {
+			opts = append(opts, datastar.WithExecuteScriptRetryDuration(retry))
+		}
+		if err := sse.ExecuteScript(p.content, opts...); err != nil {
+			return fmt.Errorf("ExecuteScript failed: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package via
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-via/via/h"
+)
+
+const (
+	// defaultTailLines is the number of lines kept by TailFile if no limit is given.
+	defaultTailLines = 1000
+	// tailPollInterval is how often TailFile checks the file for new lines.
+	tailPollInterval = 500 * time.Millisecond
+	// maxTailRead limits how much of the file is read at once, so a fast growing
+	// file does not block the stream.
+	maxTailRead = 1 << 20
+)
+
+// tailer reads the lines appended to a file.
+type tailer struct {
+	path     string
+	maxLines int
+	mu       sync.Mutex
+	offset   int64
+	partial  []byte
+	skipLine bool
+	lines    []string
+}
+
+// readNew returns the complete lines appended to the file since the last read. If the
+// file was truncated or replaced by a smaller file, it is read from the start.
+func (t *tailer) readNew() ([]string, error) {
+	f, err := os.Open(t.path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := info.Size()
+	if size < t.offset {
+		t.offset, t.partial, t.skipLine = 0, nil, false
+	}
+	if size == t.offset {
+		return nil, nil
+	}
+	buf := make([]byte, min(size-t.offset, maxTailRead))
+	n, err := f.ReadAt(buf, t.offset)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	t.offset += int64(n)
+	data := append(t.partial, buf[:n]...)
+	end := bytes.LastIndexByte(data, '\n')
+	if end < 0 {
+		t.partial = data
+		return nil, nil
+	}
+	t.partial = bytes.Clone(data[end+1:])
+	lines := strings.Split(string(data[:end]), "\n")
+	if t.skipLine {
+		lines, t.skipLine = lines[1:], false
+	}
+	for i, l := range lines {
+		lines[i] = strings.TrimSuffix(l, "\r")
+	}
+	return lines, nil
+}
+
+// append adds lines to the backlog and drops the oldest lines over the limit.
+func (t *tailer) append(lines []string) {
+	t.lines = append(t.lines, lines...)
+	if over := len(t.lines) - t.maxLines; over > 0 {
+		t.lines = append([]string(nil), t.lines[over:]...)
+	}
+}
+
+// TailFile returns a component that shows the last lines of a text file, such as a
+// build or server log, and streams lines appended to it to the browser. At most
+// maxLines lines are kept, 1000 if maxLines is 0 or less. The view scrolls to new lines
+// unless the user scrolled up.
+//
+// Example:
+//
+//	v.Page("/admin/logs", func(c *via.Context) {
+//		logs := c.Component(via.TailFile("/var/log/app.log", 500))
+//		c.View(func() h.H {
+//			return h.Div(h.H1(h.Text("Logs")), logs())
+//		})
+//	})
+func TailFile(path string, maxLines int) func(c *Context) {
+	if maxLines <= 0 {
+		maxLines = defaultTailLines
+	}
+	return func(c *Context) {
+		t := &tailer{path: path, maxLines: maxLines}
+		id := "tail-" + c.app.genID()
+
+		// start near the end of large files, dropping the first incomplete line
+		if info, err := os.Stat(path); err == nil && info.Size() > maxTailRead {
+			t.offset, t.skipLine = info.Size()-maxTailRead, true
+		}
+		lines, err := t.readNew()
+		if err != nil {
+			c.app.logWarn(c, "tail file '%s' failed: %v", path, err)
+		}
+		t.append(lines)
+
+		c.OnInterval(tailPollInterval, func() {
+			t.mu.Lock()
+			lines, err := t.readNew()
+			if err == nil {
+				t.append(lines)
+			}
+			t.mu.Unlock()
+			if err != nil {
+				c.app.logDebug(c, "tail file '%s' failed: %v", path, err)
+				return
+			}
+			if len(lines) == 0 {
+				return
+			}
+			lines = lines[max(len(lines)-maxLines, 0):]
+			elems := make([]h.H, len(lines))
+			for i, l := range lines {
+				elems[i] = tailLine(l)
+			}
+			c.AppendElements(id, elems...)
+			c.ExecScript(fmt.Sprintf(
+				"{const el=document.getElementById('%s');if(el){while(el.childElementCount>%d)el.firstElementChild.remove();if(el._viaStick!==false)el.scrollTop=el.scrollHeight}}",
+				id, maxLines))
+		}).Start()
+
+		c.View(func() h.H {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			children := []h.H{
+				h.ID(id),
+				h.Style("overflow-y:auto;max-height:30em"),
+				h.Data("init", "el.scrollTop=el.scrollHeight"),
+				h.Data("on:scroll", "el._viaStick=el.scrollTop+el.clientHeight>=el.scrollHeight-8"),
+			}
+			for _, l := range t.lines {
+				children = append(children, tailLine(l))
+			}
+			return h.Pre(children...)
+		})
+	}
+}
+
+func tailLine(line string) h.H {
+	return h.Div(h.Text(line))
+}
@@ -0,0 +1,50 @@
+package via
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTailerReadNew(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	assert.NoError(t, os.WriteFile(path, []byte("one\r\ntwo\nthr"), 0o644))
+	tl := &tailer{path: path, maxLines: 2}
+
+	lines, err := tl.readNew()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"one", "two"}, lines)
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	assert.NoError(t, err)
+	_, _ = f.WriteString("ee\nfour\n")
+	_ = f.Close()
+	lines, err = tl.readNew()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"three", "four"}, lines)
+
+	tl.append([]string{"a", "b", "c"})
+	assert.Equal(t, []string{"b", "c"}, tl.lines)
+
+	// truncated files are read from the start
+	assert.NoError(t, os.WriteFile(path, []byte("new\n"), 0o644))
+	lines, err = tl.readNew()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"new"}, lines)
+}
+
+func TestTailFileBacklog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	assert.NoError(t, os.WriteFile(path, []byte("one\ntwo\nthree\n"), 0o644))
+	c := newContext("ctx", "/", New())
+	view := c.Component(TailFile(path, 2))
+	defer c.stopAllRoutines()
+
+	var buf bytes.Buffer
+	assert.NoError(t, view().Render(&buf))
+	assert.NotContains(t, buf.String(), "<div>one</div>")
+	assert.Contains(t, buf.String(), "<div>two</div><div>three</div>")
+}
@@ -0,0 +1,64 @@
+package via
+
+import (
+	"net/http"
+	"sync"
+)
+
+// tenant returns the tenant of the request resolved by Options.TenantResolver, or an
+// empty string if tenancy is not configured.
+func (v *V) tenant(r *http.Request) string {
+	if v.cfg.TenantResolver == nil {
+		return ""
+	}
+	return v.cfg.TenantResolver(r)
+}
+
+// Tenant returns the tenant of the page request resolved by Options.TenantResolver, or
+// an empty string if tenancy is not configured.
+func (c *Context) Tenant() string {
+	if c.isComponent() {
+		c = c.parentPageCtx
+	}
+	return c.tenant
+}
+
+// PerTenant holds one instance of T per tenant, e.g. a store or a database handle,
+// created on first use.
+//
+// Example:
+//
+//	stores := via.NewPerTenant(func(tenant string) *Store {
+//		return OpenStore("data/" + tenant + ".db")
+//	})
+//	v.Page("/", func(c *via.Context) {
+//		store := stores.Get(c)
+//		// ...
+//	})
+type PerTenant[T any] struct {
+	mu        sync.Mutex
+	instances map[string]T
+	create    func(tenant string) T
+}
+
+// NewPerTenant returns a PerTenant that creates the instance of a tenant with create.
+func NewPerTenant[T any](create func(tenant string) T) *PerTenant[T] {
+	return &PerTenant[T]{instances: make(map[string]T), create: create}
+}
+
+// Get returns the instance of the tenant of the context.
+func (p *PerTenant[T]) Get(c *Context) T {
+	return p.For(c.Tenant())
+}
+
+// For returns the instance of the given tenant.
+func (p *PerTenant[T]) For(tenant string) T {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	t, ok := p.instances[tenant]
+	if !ok {
+		t = p.create(tenant)
+		p.instances[tenant] = t
+	}
+	return t
+}
@@ -0,0 +1,132 @@
+package via
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/go-via/via/h"
+)
+
+// themeCookie is the cookie that persists the theme preference of a browser.
+const themeCookie = "via-theme"
+
+// ThemeChangeEvent is the window event dispatched with the theme name as detail when
+// the theme is changed in another tab, e.g. to keep a theme toggle in sync:
+//
+//	h.Data("on:"+via.ThemeChangeEvent+"__window", "$"+theme.ID()+"=evt.detail")
+const ThemeChangeEvent = "via-theme"
+
+// Theme is a set of CSS custom properties applied to the document root while the theme
+// is active. The active theme is set as the data-theme attribute of the html element.
+type Theme struct {
+	// The name of the theme, e.g. 'dark'.
+	Name string
+
+	// The color scheme of the theme, 'light' or 'dark'. Without a preference, the first
+	// theme matching the prefers-color-scheme of the browser applies.
+	ColorScheme string
+
+	// CSS custom properties, e.g. {"--bg": "#111", "--fg": "#eee"}.
+	Vars map[string]string
+}
+
+// css returns the rules of the theme for the given selector.
+func (t Theme) css(selector string) string {
+	var b strings.Builder
+	b.WriteString(selector + "{")
+	if t.ColorScheme != "" {
+		fmt.Fprintf(&b, "color-scheme:%s;", t.ColorScheme)
+	}
+	for _, k := range slices.Sorted(maps.Keys(t.Vars)) {
+		fmt.Fprintf(&b, "%s:%s;", k, t.Vars[k])
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// themesCSS returns the stylesheet of the themes. Without a preference, the first light
+// theme (or the first theme) applies, and the first dark theme if the browser prefers it.
+func themesCSS(themes []Theme) string {
+	if len(themes) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	for _, t := range themes {
+		b.WriteString(t.css(fmt.Sprintf(":root[data-theme=%q]", t.Name)))
+	}
+	light := themes[0]
+	if i := slices.IndexFunc(themes, func(t Theme) bool { return t.ColorScheme == "light" }); i >= 0 {
+		light = themes[i]
+	}
+	b.WriteString(light.css(":root:not([data-theme])"))
+	if i := slices.IndexFunc(themes, func(t Theme) bool { return t.ColorScheme == "dark" }); i >= 0 {
+		b.WriteString("@media (prefers-color-scheme:dark){" + themes[i].css(":root:not([data-theme])") + "}")
+	}
+	return b.String()
+}
+
+// themeHead returns the elements that style the themes and apply theme changes of other
+// tabs, or nil if no themes are configured.
+func (v *V) themeHead(nonce string) []h.H {
+	if v.themesCSS == "" {
+		return nil
+	}
+	return []h.H{
+		h.StyleEl(h.Raw(v.themesCSS)),
+		h.Script(h.If(nonce != "", h.Attr("nonce", nonce)), h.Raw(fmt.Sprintf(
+			`new BroadcastChannel(%[1]q).onmessage=e=>{const t=e.data;`+
+				`t?document.documentElement.setAttribute('data-theme',t):document.documentElement.removeAttribute('data-theme');`+
+				`window.dispatchEvent(new CustomEvent(%[1]q,{detail:t}))}`, ThemeChangeEvent))),
+	}
+}
+
+// themeFromRequest returns the theme preference stored in the cookie of the request if it
+// names a configured theme.
+func (v *V) themeFromRequest(r *http.Request) string {
+	cookie, err := r.Cookie(themeCookie)
+	if err != nil {
+		return ""
+	}
+	if slices.ContainsFunc(v.cfg.Themes, func(t Theme) bool { return t.Name == cookie.Value }) {
+		return cookie.Value
+	}
+	return ""
+}
+
+// Theme returns the name of the theme preferred by the user of this context, or an empty
+// string if there is no preference and the theme follows the prefers-color-scheme of the
+// browser. The preference is persisted in a cookie, so it applies to all pages and tabs
+// of the browser.
+func (c *Context) Theme() string {
+	if c.isComponent() {
+		return c.parentPageCtx.Theme()
+	}
+	name, _ := c.theme.Load().(string)
+	return name
+}
+
+// SetTheme sets the theme preference of the user to the theme with the given name, or
+// removes the preference if name is empty. The theme is applied immediately in the
+// browser, including other open tabs. Names that are not in Options.Themes are ignored.
+func (c *Context) SetTheme(name string) {
+	if c.isComponent() {
+		c.parentPageCtx.SetTheme(name)
+		return
+	}
+	if name != "" && !slices.ContainsFunc(c.app.cfg.Themes, func(t Theme) bool { return t.Name == name }) {
+		c.app.logWarn(c, "set theme failed: theme '%s' not configured", name)
+		return
+	}
+	c.theme.Store(name)
+	maxAge := 365 * 24 * 60 * 60
+	if name == "" {
+		maxAge = 0
+	}
+	c.ExecScript(fmt.Sprintf(
+		`const t=%[1]q;t?document.documentElement.setAttribute('data-theme',t):document.documentElement.removeAttribute('data-theme');`+
+			`document.cookie=%[2]q+'='+t+';path=/;max-age=%[3]d;samesite=lax';`+
+			`new BroadcastChannel(%[4]q).postMessage(t)`, name, themeCookie, maxAge, ThemeChangeEvent))
+}
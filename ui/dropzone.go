@@ -0,0 +1,54 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/go-via/via"
+	"github.com/go-via/via/h"
+)
+
+// dropZoneScript uploads each dropped file with its own request and reports the
+// progress of every file in the list of the drop zone and the overall progress
+// in the progress signal.
+const dropZoneScript = `const url='%s';const files=[...evt.dataTransfer.files];const loaded=files.map(()=>0);
+files.forEach((f,i)=>{const bar=document.createElement('progress');bar.max=100;bar.value=0;
+const row=document.createElement('li');row.append(f.name+' ',bar);el.querySelector('ul').append(row);
+const fd=new FormData();fd.append('file',f);const xhr=new XMLHttpRequest();
+xhr.upload.onprogress=(e)=>{if(!e.lengthComputable)return;bar.value=e.loaded/e.total*100;loaded[i]=e.loaded;
+$%s=Math.round(loaded.reduce((a,b)=>a+b,0)/files.reduce((a,f)=>a+f.size,0)*100)};
+xhr.onload=()=>{bar.value=100};xhr.open('POST',url);xhr.send(fd)})`
+
+// DropZone returns a component that accepts files dropped onto it. Each dropped file is
+// uploaded with its own request, showing a progress bar per file, and handed to
+// onUpload once it is received by the server. onUpload runs like an action on the
+// drop zone context.
+//
+// Example:
+//
+//	dropZone := c.Component(ui.DropZone(func(c *via.Context, f via.UploadedFile) {
+//		saveFile(f)
+//		c.Sync()
+//	}))
+func DropZone(onUpload func(c *via.Context, file via.UploadedFile)) func(c *via.Context) {
+	return func(c *via.Context) {
+		progress := c.Signal(0)
+		uploadURL := c.Upload(func(files []via.UploadedFile) {
+			for _, f := range files {
+				if onUpload != nil {
+					onUpload(c, f)
+				}
+			}
+		})
+
+		c.View(func() h.H {
+			return h.Div(
+				h.Class("via-dropzone"),
+				h.Data("on:dragover", "evt.preventDefault()"),
+				h.Data("on:drop__prevent", fmt.Sprintf(dropZoneScript, uploadURL, progress.ID())),
+				h.P(h.Text("Drop files here")),
+				h.Progress(class(themeOf(c).Progress), h.Attr("max", "100"), h.Data("attr:value", "$"+progress.ID())),
+				h.Ul(),
+			)
+		})
+	}
+}
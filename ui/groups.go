@@ -0,0 +1,62 @@
+package ui
+
+import (
+	"github.com/go-via/via"
+	"github.com/go-via/via/h"
+)
+
+// CheckboxGroup renders a checkbox for each option, all bound to the given signal. The
+// signal holds the values of the checked options as a list of strings, so create it
+// with CheckboxSignal or with a []string value; read it with Strings. Extra
+// attributes, such as an action trigger, are added to every checkbox.
+//
+// Example:
+//
+//	toppings := ui.CheckboxSignal(c, "cheese")
+//	ui.CheckboxGroup([]via.SelectOption{{"cheese", "Cheese"}, {"ham", "Ham"}}, toppings)
+func CheckboxGroup(options []via.SelectOption, sig Signal, attrs ...h.H) h.H {
+	checked := sig.Strings()
+	return choiceGroup("checkbox", options, sig, attrs, func(v string) bool {
+		for _, c := range checked {
+			if c == v {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// CheckboxSignal returns a signal for a CheckboxGroup with the given values checked.
+// It holds a list even if no value is checked, so it is sent to the browser as an
+// array.
+func CheckboxSignal(c *via.Context, checked ...string) Signal {
+	if checked == nil {
+		checked = []string{}
+	}
+	return c.Signal(checked)
+}
+
+// RadioGroup renders a radio button for each option, all bound to the given signal. The
+// signal holds the value of the selected option. Extra attributes, such as an action
+// trigger, are added to every radio button.
+func RadioGroup(options []via.SelectOption, sig Signal, attrs ...h.H) h.H {
+	selected := sig.String()
+	return choiceGroup("radio", options, sig, attrs, func(v string) bool {
+		return v == selected
+	})
+}
+
+func choiceGroup(typ string, options []via.SelectOption, sig Signal, attrs []h.H, isChecked func(string) bool) h.H {
+	labels := make([]h.H, 0, len(options))
+	for _, o := range options {
+		input := append([]h.H{
+			h.Type(typ),
+			h.Attr("name", sig.ID()),
+			h.Value(o.Value),
+			h.Data("bind", sig.ID()),
+			h.If(isChecked(o.Value), h.Attr("checked")),
+		}, attrs...)
+		labels = append(labels, h.Label(h.Input(input...), h.Text(o.Label)))
+	}
+	return h.FieldSet(labels...)
+}
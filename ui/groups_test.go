@@ -0,0 +1,28 @@
+package ui
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-via/via"
+	"github.com/go-via/via/h"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckboxGroup(t *testing.T) {
+	var toppings, none Signal
+	v := via.New()
+	v.Page("/", func(c *via.Context) {
+		toppings = CheckboxSignal(c, "cheese")
+		none = CheckboxSignal(c)
+		options := []via.SelectOption{{Value: "cheese", Label: "Cheese"}, {Value: "ham", Label: "Ham"}}
+		c.View(func() h.H { return h.Div(CheckboxGroup(options, toppings), CheckboxGroup(options, none)) })
+	})
+	w := httptest.NewRecorder()
+	v.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	body := w.Body.String()
+	assert.Contains(t, body, `value="cheese" data-bind="`+toppings.ID()+`" checked>`)
+	assert.Contains(t, body, `value="ham" data-bind="`+toppings.ID()+`">`)
+	assert.Equal(t, []string{"cheese"}, toppings.Strings())
+	assert.Equal(t, []string{}, none.Strings())
+}
@@ -0,0 +1,90 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/go-via/via"
+	"github.com/go-via/via/data"
+	"github.com/go-via/via/h"
+)
+
+// InfiniteList returns a component that renders the items returned by fetch and loads the
+// next page when the end of the list scrolls into view. Pages are numbered from 0 and the
+// list stops loading once fetch returns no items.
+//
+// New pages are appended to the list in the browser without re-sending the loaded items.
+// The number of loaded pages is kept in the page URL query parameter param, e.g.
+// "pages", so after a refresh the list is restored up to the same position. Lists of the
+// same page need different params.
+func InfiniteList(param string, fetch func(page int) []h.H) func(c *via.Context) {
+	return func(c *via.Context) {
+		listID := newID(c)
+		sentinelID := newID(c)
+		var items []h.H
+		done := false
+
+		pages, _ := strconv.Atoi(c.GetQueryParam(param))
+		pages = max(pages, 1)
+		loaded := 0
+		for loaded < pages && !done {
+			page := fetch(loaded)
+			if len(page) == 0 {
+				done = true
+				break
+			}
+			items = append(items, page...)
+			loaded++
+		}
+
+		loadNext := c.Action(func() {
+			if done {
+				return
+			}
+			page := fetch(loaded)
+			if len(page) == 0 {
+				done = true
+				c.Sync()
+				return
+			}
+			items = append(items, page...)
+			loaded++
+			c.AppendElements(listID, page...)
+			c.ExecScript(fmt.Sprintf(
+				"const u=new URL(location.href);u.searchParams.set('%s','%d');history.replaceState(history.state,'',u)",
+				param, loaded))
+		})
+
+		c.View(func() h.H {
+			return h.Div(
+				h.Div(append([]h.H{h.ID(listID)}, items...)...),
+				h.If(!done, h.Div(h.ID(sentinelID), loadNext.OnIntersect(0))),
+			)
+		})
+	}
+}
+
+// ProviderPages adapts a data.Provider to the fetch func of InfiniteList. Pages are
+// fetched with the given query, whose Page is set for each page, and the context.Context
+// of c, see via.Context.Ctx. Each item is rendered with render. A failed fetch is
+// reported with via.Context.ReportError and ends the list.
+//
+// Example:
+//
+//	list := c.Component(ui.InfiniteList("pages", ui.ProviderPages(c, users, data.Query{PerPage: 20, Sort: "name"},
+//		func(u User) h.H { return h.P(h.Text(u.Name)) })))
+func ProviderPages[T any](c *via.Context, p data.Provider[T], q data.Query, render func(item T) h.H) func(page int) []h.H {
+	return func(page int) []h.H {
+		q.Page = page
+		res, err := p.Fetch(c.Ctx(), q)
+		if err != nil {
+			c.ReportError(err)
+			return nil
+		}
+		items := make([]h.H, len(res.Items))
+		for i, item := range res.Items {
+			items[i] = render(item)
+		}
+		return items
+	}
+}
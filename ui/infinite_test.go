@@ -0,0 +1,64 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/go-via/via"
+	"github.com/go-via/via/data"
+	"github.com/go-via/via/h"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProviderPages(t *testing.T) {
+	var reported []error
+	v := via.New()
+	v.Config(via.Options{OnError: func(c *via.Context, phase string, err error) { reported = append(reported, err) }})
+	var ctxs []context.Context
+	provider := data.ProviderFunc[string](func(ctx context.Context, q data.Query) (data.Result[string], error) {
+		ctxs = append(ctxs, ctx)
+		if q.Page > 1 {
+			return data.Result[string]{}, errors.New("db down")
+		}
+		return data.Result[string]{Items: []string{"a", "b"}, Total: 4}, nil
+	})
+	var pages func(page int) []h.H
+	var pageCtx *via.Context
+	v.Page("/", func(c *via.Context) {
+		pageCtx = c
+		pages = ProviderPages(c, provider, data.Query{PerPage: 2}, func(item string) h.H { return h.Li(h.Text(item)) })
+		c.View(func() h.H { return h.Div() })
+	})
+	v.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	ctxs, reported = nil, nil
+	assert.Len(t, pages(1), 2)
+	assert.Equal(t, []context.Context{pageCtx.Ctx()}, ctxs)
+	assert.Empty(t, reported)
+
+	assert.Nil(t, pages(2))
+	assert.Len(t, reported, 1)
+	assert.EqualError(t, reported[0], "db down")
+}
+
+func TestInfiniteListParams(t *testing.T) {
+	fetch := func(name string) func(page int) []h.H {
+		return func(page int) []h.H { return []h.H{h.P(h.Text(name + strconv.Itoa(page)))} }
+	}
+	v := via.New()
+	v.Page("/", func(c *via.Context) {
+		left := c.Component(InfiniteList("left", fetch("l")))
+		right := c.Component(InfiniteList("right", fetch("r")))
+		c.View(func() h.H { return h.Div(left(), right()) })
+	})
+	w := httptest.NewRecorder()
+	v.ServeHTTP(w, httptest.NewRequest("GET", "/?left=3", nil))
+	body := w.Body.String()
+	for _, item := range []string{"l0", "l1", "l2", "r0"} {
+		assert.Contains(t, body, "<p>"+item+"</p>")
+	}
+	assert.NotContains(t, body, "<p>r1</p>")
+}
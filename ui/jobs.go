@@ -0,0 +1,42 @@
+package ui
+
+import (
+	"strconv"
+
+	"github.com/go-via/via"
+	"github.com/go-via/via/h"
+)
+
+// JobProgress renders the state of a job enqueued with via.Jobs.EnqueueFor: a progress
+// bar while it is queued or running, and its result or error once it finished. The
+// view updates live as the context is synced by the job.
+//
+// Example:
+//
+//	c.View(func() h.H {
+//		if job == nil {
+//			return h.Button(h.Text("Export"), export.OnClick())
+//		}
+//		return ui.JobProgress(c, job)
+//	})
+func JobProgress(c *via.Context, job *via.Job) h.H {
+	status := job.Status()
+	var detail h.H
+	switch status {
+	case via.JobQueued:
+		detail = h.Progress(class(themeOf(c).Progress), h.Attr("max", "1"))
+	case via.JobRunning:
+		detail = h.Progress(class(themeOf(c).Progress), h.Attr("max", "1"), h.Value(strconv.FormatFloat(job.Progress(), 'f', 3, 64)),
+			h.Attr("aria-label", job.Name))
+	case via.JobDone:
+		if res := job.Result(); res != nil {
+			detail = h.P(h.Textf("%v", res))
+		}
+	case via.JobFailed:
+		detail = h.P(h.Role("alert"), h.Text(job.Err().Error()))
+	}
+	return h.Div(h.Attr("data-job-status", string(status)),
+		h.Span(h.Textf("%s: %s", job.Name, status)),
+		detail,
+	)
+}
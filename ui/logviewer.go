@@ -0,0 +1,168 @@
+package ui
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-via/via"
+	"github.com/go-via/via/h"
+)
+
+// logViewerRefresh is how often a LogViewer checks its buffer for new lines.
+const logViewerRefresh = 300 * time.Millisecond
+
+// Log levels recognized by LogBuffer, from the most to the least severe.
+var logLevels = []string{"error", "warn", "info", "debug"}
+
+// LogLine is a line written to a LogBuffer.
+type LogLine struct {
+	Time  time.Time
+	Level string // one of "error", "warn", "info", "debug" or empty if not recognized
+	Text  string
+}
+
+// LogBuffer is an io.Writer that keeps the most recent lines written to it, e.g. by a
+// log.Logger or a slog.TextHandler, for display in a LogViewer. It is shared by all
+// contexts of the app and safe for concurrent use.
+//
+// Example:
+//
+//	logs := ui.NewLogBuffer(1000)
+//	log.SetOutput(io.MultiWriter(os.Stderr, logs))
+type LogBuffer struct {
+	mu       sync.RWMutex
+	maxLines int
+	lines    []LogLine
+	partial  []byte
+	version  uint64
+}
+
+// NewLogBuffer returns a LogBuffer that keeps at most maxLines lines.
+func NewLogBuffer(maxLines int) *LogBuffer {
+	return &LogBuffer{maxLines: max(maxLines, 1)}
+}
+
+// Write adds the complete lines in p to the buffer. It never fails.
+func (b *LogBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	data := append(b.partial, p...)
+	end := bytes.LastIndexByte(data, '\n')
+	if end < 0 {
+		b.partial = data
+		return len(p), nil
+	}
+	b.partial = bytes.Clone(data[end+1:])
+	now := time.Now()
+	for _, text := range strings.Split(string(data[:end]), "\n") {
+		text = strings.TrimSuffix(text, "\r")
+		b.lines = append(b.lines, LogLine{Time: now, Level: logLevel(text), Text: text})
+	}
+	if over := len(b.lines) - b.maxLines; over > 0 {
+		b.lines = append([]LogLine(nil), b.lines[over:]...)
+	}
+	b.version++
+	return len(p), nil
+}
+
+// Lines returns the buffered lines, oldest first.
+func (b *LogBuffer) Lines() []LogLine {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return append([]LogLine(nil), b.lines...)
+}
+
+func (b *LogBuffer) currentVersion() uint64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.version
+}
+
+// logLevel detects the level of a line written by Via, log.Printf with a "[level]"
+// prefix, or a slog.TextHandler.
+func logLevel(text string) string {
+	lower := strings.ToLower(text)
+	for _, level := range logLevels {
+		if strings.Contains(lower, "["+level+"]") || strings.Contains(lower, "level="+level) {
+			return level
+		}
+	}
+	if strings.Contains(lower, "level=warning") || strings.Contains(lower, "[warning]") {
+		return "warn"
+	}
+	return ""
+}
+
+// matches reports whether the line is at least as severe as minLevel and contains
+// query, ignoring case. Lines without a level only match if minLevel is empty.
+func (l LogLine) matches(minLevel, query string) bool {
+	if minLevel != "" {
+		lvl := slices.Index(logLevels, l.Level)
+		if lvl < 0 || lvl > slices.Index(logLevels, minLevel) {
+			return false
+		}
+	}
+	return query == "" || strings.Contains(strings.ToLower(l.Text), strings.ToLower(query))
+}
+
+// LogViewer returns a component that shows the lines of the given buffer with a
+// minimum level filter and a search input. Every page showing the viewer updates live
+// as lines are written to the buffer.
+//
+// Example:
+//
+//	v.Page("/admin/logs", func(c *via.Context) {
+//		viewer := c.Component(ui.LogViewer(logs))
+//		c.View(func() h.H { return viewer() })
+//	})
+func LogViewer(buf *LogBuffer) func(c *via.Context) {
+	return func(c *via.Context) {
+		id := newID(c)
+		level := c.Signal("")
+		query := c.Signal("")
+		filter := c.Action(func() { c.Sync() })
+
+		rendered := buf.currentVersion()
+		var mu sync.Mutex
+		c.OnInterval(logViewerRefresh, func() {
+			mu.Lock()
+			v := buf.currentVersion()
+			changed := v != rendered
+			rendered = v
+			mu.Unlock()
+			if changed {
+				c.Sync()
+			}
+		}).Start()
+
+		levelOptions := []via.SelectOption{{Value: "", Label: "All levels"}}
+		for _, l := range logLevels {
+			levelOptions = append(levelOptions, via.SelectOption{Value: l, Label: l})
+		}
+
+		c.View(func() h.H {
+			minLevel, q := level.String(), query.String()
+			lines := []h.H{
+				h.Attr("aria-live", "polite"),
+				h.Style("overflow-y:auto;max-height:30em"),
+				h.Data("init", "el.scrollTop=el.scrollHeight"),
+			}
+			for _, l := range buf.Lines() {
+				if l.matches(minLevel, q) {
+					lines = append(lines, h.Div(h.If(l.Level != "", h.Attr("data-level", l.Level)), h.Text(l.Text)))
+				}
+			}
+			return h.Div(h.ID(id),
+				h.Div(
+					level.Select(levelOptions, class(themeOf(c).Select), h.Attr("aria-label", "Minimum level"), filter.OnChange()),
+					h.Input(class(themeOf(c).Input), h.Type("search"), h.Placeholder("Search"), h.Attr("aria-label", "Search logs"),
+						query.Bind(), filter.OnEvent("input__debounce.300ms")),
+				),
+				h.Pre(lines...),
+			)
+		})
+	}
+}
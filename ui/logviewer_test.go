@@ -0,0 +1,39 @@
+package ui
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogBuffer(t *testing.T) {
+	buf := NewLogBuffer(3)
+	fmt.Fprint(buf, "2025/01/01 [info] started\n")
+	fmt.Fprint(buf, "time=x level=ERROR msg=boom\nplain line\n[de")
+	fmt.Fprint(buf, "bug] details\n")
+
+	lines := buf.Lines()
+	assert.Len(t, lines, 3)
+	assert.Equal(t, []string{"error", "", "debug"}, []string{lines[0].Level, lines[1].Level, lines[2].Level})
+	assert.Equal(t, "[debug] details", lines[2].Text)
+}
+
+func TestLogLineMatches(t *testing.T) {
+	tests := []struct {
+		line     LogLine
+		minLevel string
+		query    string
+		want     bool
+	}{
+		{LogLine{Level: "debug", Text: "x"}, "", "", true},
+		{LogLine{Level: "debug", Text: "x"}, "info", "", false},
+		{LogLine{Level: "error", Text: "x"}, "warn", "", true},
+		{LogLine{Text: "x"}, "error", "", false},
+		{LogLine{Level: "info", Text: "User Login"}, "", "login", true},
+		{LogLine{Level: "info", Text: "User Login"}, "", "logout", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, tt.line.matches(tt.minLevel, tt.query), "%+v %s %s", tt.line, tt.minLevel, tt.query)
+	}
+}
@@ -0,0 +1,143 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/go-via/via"
+	"github.com/go-via/via/data"
+	"github.com/go-via/via/h"
+)
+
+// Paginator returns a component that renders page controls for total items split into
+// pages of perPage items. Pages are numbered from 1 and the current page is kept in the
+// given signal and in the page URL query parameter param, e.g. "page", so links and
+// refreshes keep their position. Paginators of the same page need different params.
+//
+// When the page changes, onChange is called with the new page. Use it to refresh the
+// paginated content, typically by calling Sync on the page context. If onChange is nil,
+// only the paginator is synced. The arrow keys move to the previous and next page while
+// the paginator has focus.
+func Paginator(param string, total, perPage int, current Signal, onChange func(page int)) func(c *via.Context) {
+	return func(c *via.Context) {
+		c.View(paginate(c, param, func() int { return total }, perPage, current, onChange))
+	}
+}
+
+// ProviderPaginator returns a component that renders the current page of the items of
+// a data.Provider with render, followed by a Paginator over all matching items, which
+// keeps the current page in the page URL query parameter param. Pages are fetched with
+// the given query, whose Page is set from current, and the context.Context of the
+// component, see via.Context.Ctx, when the component is initialized and when the
+// paginator changes the page; rendering does not fetch. A failed fetch is reported
+// with via.Context.ReportError and renders no items. Changing the page syncs the
+// component.
+//
+// Example:
+//
+//	page := c.Signal(1)
+//	users := c.Component(ui.ProviderPaginator("page", usersProvider, data.Query{PerPage: 20, Sort: "name"}, page,
+//		func(items []User) h.H { return usersTable(items) }))
+func ProviderPaginator[T any](param string, p data.Provider[T], q data.Query, current Signal, render func(items []T) h.H) func(c *via.Context) {
+	return func(c *via.Context) {
+		var res data.Result[T]
+		fetch := func() {
+			q.Page = max(current.Int(), 1) - 1
+			var err error
+			if res, err = p.Fetch(c.Ctx(), q); err != nil {
+				c.ReportError(err)
+			}
+		}
+		// fetch the page of the URL before paginate clamps it to the fetched total
+		if page, err := strconv.Atoi(c.GetQueryParam(param)); err == nil {
+			current.SetValue(max(page, 1))
+		}
+		fetch()
+		pager := paginate(c, param, func() int { return res.Total }, q.PerPage, current, func(int) {
+			fetch()
+			c.Sync()
+		})
+		if q.Page != max(current.Int(), 1)-1 {
+			fetch() // the page was clamped
+		}
+		c.View(func() h.H { return h.Div(render(res.Items), pager()) })
+	}
+}
+
+// paginate registers the actions and returns the view of a Paginator over total()
+// items, whose current page is kept in the URL query parameter param.
+func paginate(c *via.Context, param string, total func() int, perPage int, current Signal, onChange func(page int)) func() h.H {
+	count := func() int { return max((total()+perPage-1)/max(perPage, 1), 1) }
+	if p, err := strconv.Atoi(c.GetQueryParam(param)); err == nil {
+		current.SetValue(min(max(p, 1), count()))
+	}
+	target := c.Signal(0)
+
+	setPage := func(p int) {
+		p = min(max(p, 1), count())
+		if p == current.Int() {
+			return
+		}
+		current.SetValue(p)
+		c.ExecScript(fmt.Sprintf(
+			"const u=new URL(location.href);u.searchParams.set('%s','%d');history.replaceState(history.state,'',u)",
+			param, p))
+		if onChange != nil {
+			onChange(p)
+			return
+		}
+		c.Sync()
+	}
+	goTo := c.Action(func() { setPage(target.Int()) })
+	prev := c.Action(func() { setPage(current.Int() - 1) })
+	next := c.Action(func() { setPage(current.Int() + 1) })
+
+	return func() h.H {
+		t := themeOf(c)
+		cur, count := current.Int(), count()
+		items := []h.H{
+			h.Li(class(t.PageItem), h.Button(class(t.PageButton), h.Text("‹"), h.Attr("aria-label", "Previous page"),
+				h.If(cur <= 1, h.Attr("disabled")), prev.OnClick())),
+		}
+		for _, p := range pageWindow(cur, count) {
+			if p == 0 {
+				items = append(items, h.Li(class(t.PageItem), h.Span(h.Text("…"))))
+				continue
+			}
+			item, button := t.PageItem, t.PageButton
+			if p == cur {
+				item, button = t.PageItemActive, t.PageButtonActive
+			}
+			items = append(items, h.Li(class(item), h.Button(
+				class(button),
+				h.Text(strconv.Itoa(p)),
+				h.If(p == cur, h.Attr("aria-current", "page")),
+				goTo.OnClick(via.WithSignalInt(target, p)),
+			)))
+		}
+		items = append(items, h.Li(class(t.PageItem), h.Button(class(t.PageButton), h.Text("›"), h.Attr("aria-label", "Next page"),
+			h.If(cur >= count, h.Attr("disabled")), next.OnClick())))
+
+		return h.Nav(
+			h.Attr("aria-label", "Pagination"),
+			prev.OnKeyDown("ArrowLeft"),
+			h.Ul(append([]h.H{class(t.Pagination), next.OnKeyDown("ArrowRight")}, items...)...),
+		)
+	}
+}
+
+// pageWindow returns the page numbers to render for count pages: the first and last page
+// and two pages on either side of current. Gaps are marked with 0.
+func pageWindow(current, count int) []int {
+	var pages []int
+	for p := 1; p <= count; p++ {
+		if p == 1 || p == count || (p >= current-2 && p <= current+2) {
+			pages = append(pages, p)
+			continue
+		}
+		if pages[len(pages)-1] != 0 {
+			pages = append(pages, 0)
+		}
+	}
+	return pages
+}
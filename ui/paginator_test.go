@@ -0,0 +1,149 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/go-via/via"
+	"github.com/go-via/via/data"
+	"github.com/go-via/via/h"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPageWindow(t *testing.T) {
+	testcases := []struct {
+		desc           string
+		current, count int
+		expected       []int
+	}{
+		{"single page", 1, 1, []int{1}},
+		{"few pages", 2, 4, []int{1, 2, 3, 4}},
+		{"at start", 1, 10, []int{1, 2, 3, 0, 10}},
+		{"in middle", 5, 10, []int{1, 0, 3, 4, 5, 6, 7, 0, 10}},
+		{"at end", 10, 10, []int{1, 0, 8, 9, 10}},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.desc, func(t *testing.T) {
+			assert.Equal(t, testcase.expected, pageWindow(testcase.current, testcase.count))
+		})
+	}
+}
+
+func TestPaginatorInComponent(t *testing.T) {
+	v := via.New()
+	v.Page("/", func(c *via.Context) {
+		current := c.Signal(1)
+		pager := c.Component(Paginator("page", 50, 10, current, nil))
+		c.View(func() h.H { return h.Div(pager()) })
+	})
+	w := httptest.NewRecorder()
+	v.ServeHTTP(w, httptest.NewRequest("GET", "/?page=3", nil))
+	assert.Regexp(t, `<button aria-current="page" [^>]*>3</button>`, w.Body.String())
+}
+
+func TestPaginatorParams(t *testing.T) {
+	var first, second Signal
+	v := via.New()
+	v.Page("/", func(c *via.Context) {
+		first, second = c.Signal(1), c.Signal(1)
+		a := c.Component(Paginator("a", 50, 10, first, nil))
+		b := c.Component(Paginator("b", 50, 10, second, nil))
+		c.View(func() h.H { return h.Div(a(), b()) })
+	})
+	v.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/?a=2&b=4", nil))
+	assert.Equal(t, 2, first.Int())
+	assert.Equal(t, 4, second.Int())
+}
+
+func TestProviderPaginator(t *testing.T) {
+	names := []string{"Ann", "Bob", "Cid", "Dan", "Eve"}
+	provider := data.FromSlice(names, nil, nil)
+	failing := data.ProviderFunc[string](func(ctx context.Context, q data.Query) (data.Result[string], error) {
+		return data.Result[string]{}, errors.New("db down")
+	})
+	var reported []error
+	v := via.New()
+	v.Config(via.Options{OnError: func(c *via.Context, phase string, err error) { reported = append(reported, err) }})
+	render := func(items []string) h.H { return h.P(h.Text(strings.Join(items, ","))) }
+	v.Page("/", func(c *via.Context) {
+		list := c.Component(ProviderPaginator("page", provider, data.Query{PerPage: 2}, c.Signal(1), render))
+		c.View(func() h.H { return h.Div(list()) })
+	})
+	v.Page("/failing", func(c *via.Context) {
+		list := c.Component(ProviderPaginator("page", failing, data.Query{PerPage: 2}, c.Signal(1), render))
+		c.View(func() h.H { return h.Div(list()) })
+	})
+
+	testcases := []struct {
+		desc, uri, items string
+		page             int
+	}{
+		{"first page", "/", "Ann,Bob", 1},
+		{"page of the URL", "/?page=2", "Cid,Dan", 2},
+		{"last page", "/?page=3", "Eve", 3},
+		{"clamped page", "/?page=9", "Eve", 3},
+	}
+	for _, testcase := range testcases {
+		t.Run(testcase.desc, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			v.ServeHTTP(w, httptest.NewRequest("GET", testcase.uri, nil))
+			assert.Contains(t, w.Body.String(), "<p>"+testcase.items+"</p>")
+			assert.Regexp(t, fmt.Sprintf(`<button aria-current="page" [^>]*>%d</button>`, testcase.page), w.Body.String())
+			assert.NotContains(t, w.Body.String(), ">4</button>")
+		})
+	}
+
+	reported = nil
+	w := httptest.NewRecorder()
+	v.ServeHTTP(w, httptest.NewRequest("GET", "/failing", nil))
+	assert.Contains(t, w.Body.String(), "<p></p>")
+	assert.NotEmpty(t, reported)
+	for _, err := range reported {
+		assert.EqualError(t, err, "db down")
+	}
+}
+
+func TestProviderPaginatorFetches(t *testing.T) {
+	var pages []int
+	names := data.FromSlice([]string{"Ann", "Bob", "Cid", "Dan", "Eve"}, nil, nil)
+	provider := data.ProviderFunc[string](func(ctx context.Context, q data.Query) (data.Result[string], error) {
+		pages = append(pages, q.Page)
+		return names.Fetch(ctx, q)
+	})
+	var list func() h.H
+	v := via.New()
+	v.Page("/", func(c *via.Context) {
+		list = c.Component(ProviderPaginator("page", provider, data.Query{PerPage: 2}, c.Signal(1),
+			func(items []string) h.H { return h.P(h.Text(strings.Join(items, ","))) }))
+		c.View(func() h.H { return h.Div(list()) })
+	})
+	pages = nil
+	w := httptest.NewRecorder()
+	v.ServeHTTP(w, httptest.NewRequest("GET", "/?page=9", nil))
+	assert.Equal(t, []int{8, 2}, pages, "fetched at init, again once clamped")
+
+	pages = nil
+	for range 3 {
+		assert.NoError(t, list().Render(io.Discard))
+	}
+	assert.Empty(t, pages, "rendering does not fetch")
+
+	prev := regexp.MustCompile(`aria-label="Previous page" data-on:click="@get\(&#39;/_action/([^&]+)&#39;`).FindStringSubmatch(w.Body.String())
+	if assert.Len(t, prev, 2) {
+		ctxID := regexp.MustCompile(`<div id="([^"]+)"`).FindStringSubmatch(w.Body.String())[1]
+		signals := url.QueryEscape(fmt.Sprintf(`{"via-ctx":%q}`, ctxID))
+		v.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/_action/"+prev[1]+"?datastar="+signals, nil))
+		assert.Equal(t, []int{1}, pages)
+		var b strings.Builder
+		assert.NoError(t, list().Render(&b))
+		assert.Contains(t, b.String(), "<p>Cid,Dan</p>")
+	}
+}
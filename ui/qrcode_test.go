@@ -0,0 +1,352 @@
+package ui
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/go-via/via/h"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQRCode(t *testing.T) {
+	// error correction of version 1-M "HELLO WORLD" from the QR code specification examples
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	assert.Equal(t, []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}, rsRemainder(data, rsDivisor(10)))
+
+	q := &qrCode{version: 7, size: 45}
+	q.modules = make([][]bool, q.size)
+	q.isFunction = make([][]bool, q.size)
+	for i := range q.size {
+		q.modules[i] = make([]bool, q.size)
+		q.isFunction[i] = make([]bool, q.size)
+	}
+	assert.Equal(t, []int{6, 22, 38}, q.alignmentPatternPositions())
+	q.drawFormatBits(0)
+	format := ""
+	for i := 14; i >= 9; i-- {
+		format += map[bool]string{true: "1", false: "0"}[q.modules[8][14-i]]
+	}
+	assert.Equal(t, "101010", format, "format bits of level M, mask 0")
+
+	testcases := []struct {
+		text string
+		size int
+	}{
+		{"https://example.com", 25},
+		{strings.Repeat("a", 100), 41},
+		{strings.Repeat("a", 2331), 177},
+		{strings.Repeat("a", 2332), 0},
+	}
+	for _, testcase := range testcases {
+		modules := qrEncode([]byte(testcase.text))
+		assert.Len(t, modules, testcase.size)
+		if testcase.size > 0 {
+			// finder pattern in the top left corner
+			assert.True(t, modules[0][0])
+			assert.False(t, modules[1][1])
+			assert.True(t, modules[3][3])
+		}
+	}
+
+	html, err := h.Render(QRCode("https://example.com", h.Attr("width", "200")))
+	assert.NoError(t, err)
+	assert.Contains(t, html, `viewBox="0 0 33 33"`)
+	assert.Contains(t, html, `width="200"`)
+	assert.Contains(t, html, `<path d="M4,4h1v1h-1z`)
+	assert.Nil(t, QRCode(strings.Repeat("a", 2332)))
+}
+
+// The round trip below decodes the symbols with a decoder written from the QR code
+// specification, ISO/IEC 18004, independently of the encoder: it checks the function
+// patterns, the format and version information, reads the codewords, verifies the
+// Reed-Solomon codewords of every block and parses the data.
+
+// Alignment pattern centers and format information of level M by mask, from the
+// tables of the specification.
+var (
+	qrSpecAlignment = map[int][]int{
+		1: nil, 2: {6, 18}, 7: {6, 22, 38}, 10: {6, 28, 50}, 14: {6, 26, 46, 66},
+		17: {6, 30, 54, 78}, 27: {6, 34, 62, 90, 118}, 40: {6, 30, 58, 86, 114, 142, 170},
+	}
+	qrSpecFormatM = [8]int{0b101010000010010, 0b101000100100101, 0b101111001111100, 0b101101101001011,
+		0b100010111111001, 0b100000011001110, 0b100111110010111, 0b100101010100000}
+	qrSpecVersion = map[int]int{7: 0x07C94, 10: 0x0A4D3, 14: 0x0E60D, 17: 0x1145D, 27: 0x1B08E, 40: 0x28C69}
+)
+
+// qrDecode decodes the byte mode data of a level M symbol and returns it with the
+// version and mask of the symbol.
+func qrDecode(t *testing.T, modules [][]bool) (data []byte, version, mask int) {
+	t.Helper()
+	size := len(modules)
+	version = (size - 17) / 4
+	if !assert.Equal(t, version*4+17, size, "size") || !assert.Contains(t, qrSpecAlignment, version, "version under test") {
+		return nil, version, -1
+	}
+	dark := func(row, col int) bool { return modules[row][col] }
+	reserved := make([][]bool, size)
+	for i := range reserved {
+		reserved[i] = make([]bool, size)
+	}
+	reserve := func(row, col, rows, cols int) {
+		for r := row; r < row+rows; r++ {
+			for c := col; c < col+cols; c++ {
+				reserved[r][c] = true
+			}
+		}
+	}
+
+	// finder patterns with their separators and the format areas
+	for _, corner := range [][2]int{{0, 0}, {0, size - 7}, {size - 7, 0}} {
+		for r := -1; r <= 7; r++ {
+			for c := -1; c <= 7; c++ {
+				row, col := corner[0]+r, corner[1]+c
+				if row < 0 || row >= size || col < 0 || col >= size {
+					continue
+				}
+				ring := max(abs(r-3), abs(c-3))
+				assert.Equal(t, ring != 2 && ring != 4, dark(row, col), "finder pattern at %d,%d", row, col)
+			}
+		}
+	}
+	reserve(0, 0, 9, 9)
+	reserve(0, size-8, 9, 8)
+	reserve(size-8, 0, 8, 9)
+	assert.True(t, dark(size-8, 8), "dark module")
+
+	// timing patterns
+	for i := 8; i < size-8; i++ {
+		assert.Equal(t, i%2 == 0, dark(6, i), "horizontal timing pattern at %d", i)
+		assert.Equal(t, i%2 == 0, dark(i, 6), "vertical timing pattern at %d", i)
+	}
+	reserve(6, 0, 1, size)
+	reserve(0, 6, size, 1)
+
+	// alignment patterns, except where they overlap the finder patterns
+	align := qrSpecAlignment[version]
+	last := len(align) - 1
+	for i, row := range align {
+		for j, col := range align {
+			if i == 0 && j == 0 || i == 0 && j == last || i == last && j == 0 {
+				continue
+			}
+			for r := -2; r <= 2; r++ {
+				for c := -2; c <= 2; c++ {
+					assert.Equal(t, max(abs(r), abs(c)) != 1, dark(row+r, col+c), "alignment pattern at %d,%d", row+r, col+c)
+				}
+			}
+			reserve(row-2, col-2, 5, 5)
+		}
+	}
+
+	// version information of versions 7 and up, least significant bit first
+	if version >= 7 {
+		var upperRight, lowerLeft int
+		for i := range 18 {
+			if dark(i/3, size-11+i%3) {
+				upperRight |= 1 << i
+			}
+			if dark(size-11+i%3, i/3) {
+				lowerLeft |= 1 << i
+			}
+		}
+		assert.Equal(t, qrSpecVersion[version], upperRight, "version information upper right")
+		assert.Equal(t, qrSpecVersion[version], lowerLeft, "version information lower left")
+		reserve(0, size-11, 6, 3)
+		reserve(size-11, 0, 3, 6)
+	}
+
+	// format information, most significant bit first
+	var first, second int
+	appendBit := func(bits *int, row, col int) {
+		*bits <<= 1
+		if dark(row, col) {
+			*bits |= 1
+		}
+	}
+	for _, col := range []int{0, 1, 2, 3, 4, 5, 7, 8} {
+		appendBit(&first, 8, col)
+	}
+	for _, row := range []int{7, 5, 4, 3, 2, 1, 0} {
+		appendBit(&first, row, 8)
+	}
+	for row := size - 1; row >= size-7; row-- {
+		appendBit(&second, row, 8)
+	}
+	for col := size - 8; col < size; col++ {
+		appendBit(&second, 8, col)
+	}
+	assert.Equal(t, first, second, "copies of the format information")
+	mask = slices.Index(qrSpecFormatM[:], first)
+	if !assert.GreaterOrEqual(t, mask, 0, "format information of level M") {
+		return nil, version, mask
+	}
+
+	// codewords in zigzag order, unmasked
+	masked := func(i, j int) bool {
+		switch mask {
+		case 0:
+			return (i+j)%2 == 0
+		case 1:
+			return i%2 == 0
+		case 2:
+			return j%3 == 0
+		case 3:
+			return (i+j)%3 == 0
+		case 4:
+			return (i/2+j/3)%2 == 0
+		case 5:
+			return i*j%2+i*j%3 == 0
+		case 6:
+			return (i*j%2+i*j%3)%2 == 0
+		default:
+			return ((i+j)%2+i*j%3)%2 == 0
+		}
+	}
+	var bits []bool
+	upward := true
+	for right := size - 1; right > 0; right -= 2 {
+		if right == 6 {
+			right--
+		}
+		for k := range size {
+			row := k
+			if upward {
+				row = size - 1 - k
+			}
+			for _, col := range []int{right, right - 1} {
+				if !reserved[row][col] {
+					bits = append(bits, dark(row, col) != masked(row, col))
+				}
+			}
+		}
+		upward = !upward
+	}
+	codewords := make([]byte, len(bits)/8)
+	for i := range codewords {
+		for _, bit := range bits[i*8 : i*8+8] {
+			codewords[i] <<= 1
+			if bit {
+				codewords[i] |= 1
+			}
+		}
+	}
+	for _, bit := range bits[len(codewords)*8:] {
+		assert.False(t, bit, "remainder bits")
+	}
+
+	// deinterleave the blocks, the short ones first, and verify their error correction:
+	// a codeword evaluates to zero at the roots of the generator polynomial
+	numBlocks, eccLen := qrNumBlocks[version], qrECCPerBlock[version]
+	numShort := numBlocks - len(codewords)%numBlocks
+	shortLen := len(codewords) / numBlocks
+	blocks := make([][]byte, numBlocks)
+	k := 0
+	for i := range shortLen - eccLen + 1 {
+		for j := range blocks {
+			if i < shortLen-eccLen || j >= numShort {
+				blocks[j] = append(blocks[j], codewords[k])
+				k++
+			}
+		}
+	}
+	for range eccLen {
+		for j := range blocks {
+			blocks[j] = append(blocks[j], codewords[k])
+			k++
+		}
+	}
+	exp, log := gfTables()
+	for j, block := range blocks {
+		for i := range eccLen {
+			var syndrome byte
+			for _, b := range block {
+				if syndrome != 0 {
+					syndrome = exp[(int(log[syndrome])+i)%255]
+				}
+				syndrome ^= b
+			}
+			assert.Zero(t, syndrome, "syndrome %d of block %d", i, j)
+		}
+		data = append(data, block[:len(block)-eccLen]...)
+	}
+
+	// byte mode segment, terminator and padding
+	read := func(pos, n int) int {
+		val := 0
+		for i := pos; i < pos+n; i++ {
+			val = val<<1 | int(data[i/8]>>(7-i%8)&1)
+		}
+		return val
+	}
+	if !assert.Equal(t, 0b0100, read(0, 4), "byte mode") {
+		return nil, version, mask
+	}
+	countBits := 8
+	if version >= 10 {
+		countBits = 16
+	}
+	count := read(4, countBits)
+	end := 4 + countBits + 8*count
+	if !assert.LessOrEqual(t, end, 8*len(data), "character count") {
+		return nil, version, mask
+	}
+	text := make([]byte, count)
+	for i := range text {
+		text[i] = byte(read(4+countBits+8*i, 8))
+	}
+	padStart := (end + 4 + 7) / 8
+	if padStart < len(data) {
+		assert.Zero(t, read(end, padStart*8-end), "terminator")
+	}
+	for i := padStart; i < len(data); i++ {
+		assert.Equal(t, [2]byte{0xEC, 0x11}[(i-padStart)%2], data[i], "padding codeword %d", i)
+	}
+	return text, version, mask
+}
+
+// gfTables returns the powers of the generator 2 of GF(2^8) modulo
+// x^8 + x^4 + x^3 + x^2 + 1 and their logarithms.
+func gfTables() (exp [255]byte, log [256]byte) {
+	x := 1
+	for i := range 255 {
+		exp[i], log[x] = byte(x), byte(i)
+		x <<= 1
+		if x&0x100 != 0 {
+			x ^= 0x11D
+		}
+	}
+	return exp, log
+}
+
+func TestQRCodeRoundTrip(t *testing.T) {
+	testcases := []struct {
+		desc    string
+		text    string
+		version int
+	}{
+		{"empty", "", 1},
+		{"url", "https://example.com/7", 2},
+		{"capacity of version 1", strings.Repeat("1", 14), 1},
+		{"capacity of version 1 exceeded", strings.Repeat("1", 15), 2},
+		{"version information", strings.Repeat("x", 122), 7},
+		{"16 bit character count", strings.Repeat("é", 100) + "0123456789abc", 10},
+		{"short and long blocks", strings.Repeat("via ", 90), 14},
+		{"binary", string(bytes.Repeat([]byte{0x00, 0xFF, 0x80, 0x7F, 0xEC, 0x11}, 80)), 17},
+		{"many blocks", strings.Repeat("Datastar ", 125), 27},
+		{"capacity of version 40", strings.Repeat("Z", 2331), 40},
+	}
+
+	masks := map[int]bool{}
+	for _, testcase := range testcases {
+		t.Run(testcase.desc, func(t *testing.T) {
+			modules := qrEncode([]byte(testcase.text))
+			data, version, mask := qrDecode(t, modules)
+			assert.Equal(t, testcase.version, version)
+			assert.Equal(t, testcase.text, string(data))
+			masks[mask] = true
+		})
+	}
+	assert.Greater(t, len(masks), 1, "the masks vary with the data")
+}
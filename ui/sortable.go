@@ -0,0 +1,88 @@
+package ui
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/go-via/via"
+	"github.com/go-via/via/h"
+)
+
+// SortableItem is an entry of a SortableList. Key identifies the item and must be
+// unique within the list.
+type SortableItem struct {
+	Key     string
+	Content h.H
+}
+
+// SortableList returns a component that renders the given items as a list that can be
+// reordered with drag and drop. After every drop, onReorder receives the keys of the
+// items in their new order. While an item is dragged, the item it would be dropped on
+// carries the data-drop-target attribute, e.g. for a drop indicator:
+//
+//	li[data-drop-target] { border-top: 2px solid }
+func SortableList(items []SortableItem, onReorder func(keys []string)) func(c *via.Context) {
+	return func(c *via.Context) {
+		byKey := make(map[string]h.H, len(items))
+		order := make([]string, 0, len(items))
+		for _, it := range items {
+			byKey[it.Key] = it.Content
+			order = append(order, it.Key)
+		}
+
+		source := c.Signal("")
+		target := c.Signal("")
+		prefix := source.ID() + "-"
+		var dragging, over string
+
+		pick := c.Action(func() {
+			dragging, over = strings.TrimPrefix(source.String(), prefix), ""
+		})
+		hover := c.Action(func() {
+			dst := strings.TrimPrefix(target.String(), prefix)
+			if dragging == "" || dst == over {
+				return
+			}
+			over = dst
+			c.Sync()
+		})
+		move := c.Action(func() {
+			src := strings.TrimPrefix(source.String(), prefix)
+			dst := strings.TrimPrefix(target.String(), prefix)
+			dragging, over = "", ""
+			order = moveKey(order, src, dst)
+			if onReorder != nil {
+				onReorder(slices.Clone(order))
+			}
+			c.Sync()
+		})
+
+		c.View(func() h.H {
+			lis := make([]h.H, 0, len(order))
+			for _, key := range order {
+				lis = append(lis, h.Li(
+					h.ID(prefix+key),
+					h.Attr("draggable", "true"),
+					h.If(key == over && key != dragging, h.Attr("data-drop-target")),
+					pick.OnDragStart(via.WithSignal(source, prefix+key)),
+					hover.OnDragOver(via.WithSignal(target, prefix+key)),
+					move.OnDrop(source, target),
+					byKey[key],
+				))
+			}
+			return h.Ul(lis...)
+		})
+	}
+}
+
+// moveKey moves src to the position of dst. If either key is not in order, order is
+// returned unchanged.
+func moveKey(order []string, src, dst string) []string {
+	from := slices.Index(order, src)
+	to := slices.Index(order, dst)
+	if from < 0 || to < 0 || from == to {
+		return order
+	}
+	order = slices.Delete(order, from, from+1)
+	return slices.Insert(order, to, src)
+}
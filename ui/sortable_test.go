@@ -0,0 +1,77 @@
+package ui
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/go-via/via"
+	"github.com/go-via/via/h"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMoveKey(t *testing.T) {
+	testcases := []struct {
+		desc     string
+		src, dst string
+		expected []string
+	}{
+		{"move down", "a", "c", []string{"b", "c", "a", "d"}},
+		{"move up", "d", "b", []string{"a", "d", "b", "c"}},
+		{"same position", "b", "b", []string{"a", "b", "c", "d"}},
+		{"unknown source", "x", "b", []string{"a", "b", "c", "d"}},
+		{"unknown target", "a", "x", []string{"a", "b", "c", "d"}},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.desc, func(t *testing.T) {
+			order := []string{"a", "b", "c", "d"}
+			assert.Equal(t, testcase.expected, moveKey(order, testcase.src, testcase.dst))
+		})
+	}
+}
+
+func TestSortableList(t *testing.T) {
+	var reordered [][]string
+	var list func() h.H
+	v := via.New()
+	v.Page("/", func(c *via.Context) {
+		list = c.Component(SortableList([]SortableItem{
+			{Key: "a", Content: h.Text("A")}, {Key: "b", Content: h.Text("B")}, {Key: "c", Content: h.Text("C")},
+		}, func(keys []string) { reordered = append(reordered, keys) }))
+		c.View(func() h.H { return h.Div(list()) })
+	})
+	w := httptest.NewRecorder()
+	v.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	body := w.Body.String()
+	ctxID := regexp.MustCompile(`<div id="([^"]+)"`).FindStringSubmatch(body)[1]
+	action := func(event string) string {
+		return regexp.MustCompile(`data-on:` + event + `="[^"]*/_action/([^&]+)&`).FindStringSubmatch(body)[1]
+	}
+	source := regexp.MustCompile(`<li id="([^"]+)-a"`).FindStringSubmatch(body)[1]
+	target := regexp.MustCompile(`\$([^=;]+)=el.id`).FindStringSubmatch(body)[1]
+	run := func(id string, signals map[string]string) {
+		sigs := fmt.Sprintf(`{"via-ctx":%q`, ctxID)
+		for k, val := range signals {
+			sigs += fmt.Sprintf(`,%q:%q`, k, source+"-"+val)
+		}
+		v.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/_action/"+id+"?datastar="+url.QueryEscape(sigs+"}"), nil))
+	}
+	render := func() string {
+		var b strings.Builder
+		assert.NoError(t, list().Render(&b))
+		return b.String()
+	}
+	assert.Contains(t, body, "evt.dataTransfer.setData(&#39;text/plain&#39;,el.id)")
+
+	run(action("dragstart"), map[string]string{source: "a"})
+	run(action("dragover"), map[string]string{target: "c"})
+	assert.Contains(t, render(), `<li id="`+source+`-c" draggable="true" data-drop-target`)
+
+	run(action("drop__prevent"), map[string]string{source: "a", target: "c"})
+	assert.Equal(t, [][]string{{"b", "c", "a"}}, reordered)
+	assert.NotContains(t, render(), "data-drop-target")
+}
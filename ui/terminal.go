@@ -0,0 +1,194 @@
+package ui
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-via/via"
+	"github.com/go-via/via/h"
+)
+
+const (
+	// terminalTimeout limits how long a terminal command may run.
+	terminalTimeout = time.Minute
+	// terminalMaxLines is the number of output lines kept by a terminal.
+	terminalMaxLines = 1000
+)
+
+// Command is a command that can be run in a Terminal. It writes its output to out and
+// must return when ctx is done. args are the words typed after the command name.
+type Command func(ctx context.Context, args []string, out io.Writer) error
+
+// ExecCommand returns a Command that runs the given program with the given arguments
+// followed by the arguments typed by the user. No shell is involved, so the user can
+// not run other programs, but the program must be safe to run with any arguments.
+func ExecCommand(name string, args ...string) Command {
+	return func(ctx context.Context, userArgs []string, out io.Writer) error {
+		cmd := exec.CommandContext(ctx, name, append(slices.Clone(args), userArgs...)...)
+		cmd.Stdout = out
+		cmd.Stderr = out
+		return cmd.Run()
+	}
+}
+
+// lineWriter calls emit with each complete line written to it.
+type lineWriter struct {
+	mu      sync.Mutex
+	partial []byte
+	emit    func(lines ...string)
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	data := append(w.partial, p...)
+	end := bytes.LastIndexByte(data, '\n')
+	if end < 0 {
+		w.partial = data
+		w.mu.Unlock()
+		return len(p), nil
+	}
+	w.partial = bytes.Clone(data[end+1:])
+	w.mu.Unlock()
+	w.emit(strings.Split(strings.ReplaceAll(string(data[:end]), "\r", ""), "\n")...)
+	return len(p), nil
+}
+
+// flush emits the last line if it is not terminated by a newline.
+func (w *lineWriter) flush() {
+	w.mu.Lock()
+	rest := string(w.partial)
+	w.partial = nil
+	w.mu.Unlock()
+	if rest != "" {
+		w.emit(rest)
+	}
+}
+
+// Terminal returns a component that runs the given commands, keyed by name, from a
+// command line in the browser and streams their output live. Only the given commands
+// can be run; "help" lists them and "clear" clears the output. One command runs at a
+// time and is canceled after one minute or when the page is closed.
+//
+// Terminals give users control over the server; only add them to pages restricted to
+// trusted users, such as admin consoles or demo sandboxes.
+//
+// Example:
+//
+//	term := c.Component(ui.Terminal(map[string]ui.Command{
+//		"uptime": ui.ExecCommand("uptime"),
+//		"df":     ui.ExecCommand("df", "-h"),
+//	}))
+func Terminal(commands map[string]Command) func(c *via.Context) {
+	return func(c *via.Context) {
+		id := newID(c)
+		// canceled when the page is closed, unlike the context of the run action
+		life := c.Ctx()
+		input := c.Signal("")
+		var mu sync.Mutex
+		var lines []string
+		running := false
+
+		emit := func(out ...string) {
+			if life.Err() != nil {
+				return
+			}
+			mu.Lock()
+			lines = append(lines, out...)
+			if over := len(lines) - terminalMaxLines; over > 0 {
+				lines = append([]string(nil), lines[over:]...)
+			}
+			mu.Unlock()
+			elems := make([]h.H, len(out))
+			for i, l := range out {
+				elems[i] = h.Div(h.Text(l))
+			}
+			c.AppendElements(id, elems...)
+			c.ExecScript(fmt.Sprintf(
+				"{const el=document.getElementById('%s');if(el){while(el.childElementCount>%d)el.firstElementChild.remove();el.scrollTop=el.scrollHeight}}",
+				id, terminalMaxLines))
+		}
+
+		run := c.Action(func() {
+			line := strings.TrimSpace(input.String())
+			input.SetValue("")
+			c.SyncSignals()
+			fields := strings.Fields(line)
+			if len(fields) == 0 {
+				return
+			}
+			emit("$ " + line)
+			switch fields[0] {
+			case "help":
+				names := make([]string, 0, len(commands))
+				for name := range commands {
+					names = append(names, name)
+				}
+				slices.Sort(names)
+				emit("commands: " + strings.Join(append(names, "clear", "help"), " "))
+				return
+			case "clear":
+				mu.Lock()
+				lines = nil
+				mu.Unlock()
+				c.Sync()
+				return
+			}
+			cmd, ok := commands[fields[0]]
+			if !ok {
+				emit(fields[0] + ": command not found")
+				return
+			}
+			mu.Lock()
+			if running {
+				mu.Unlock()
+				emit("a command is already running")
+				return
+			}
+			running = true
+			mu.Unlock()
+
+			go func() {
+				defer func() {
+					if r := recover(); r != nil {
+						emit(fmt.Sprintf("%s: %v", fields[0], r))
+					}
+					mu.Lock()
+					running = false
+					mu.Unlock()
+				}()
+				ctx, cancel := context.WithTimeout(life, terminalTimeout)
+				defer cancel()
+				out := &lineWriter{emit: emit}
+				err := cmd(ctx, fields[1:], out)
+				out.flush()
+				if err != nil {
+					emit(fmt.Sprintf("%s: %v", fields[0], err))
+				}
+			}()
+		})
+
+		c.View(func() h.H {
+			mu.Lock()
+			output := []h.H{h.ID(id), h.Attr("aria-live", "polite"), h.Style("overflow-y:auto;max-height:30em")}
+			for _, l := range lines {
+				output = append(output, h.Div(h.Text(l)))
+			}
+			mu.Unlock()
+			return h.Div(
+				h.Pre(output...),
+				h.Div(
+					h.Span(h.Text("$ ")),
+					h.Input(class(themeOf(c).Input), h.Type("text"), h.Attr("aria-label", "Command"), h.Attr("autocomplete", "off"),
+						h.Attr("spellcheck", "false"), input.Bind(), run.OnKeyDown("Enter")),
+				),
+			)
+		})
+	}
+}
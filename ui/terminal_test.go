@@ -0,0 +1,88 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-via/via"
+	"github.com/go-via/via/h"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLineWriter(t *testing.T) {
+	var got []string
+	w := &lineWriter{emit: func(lines ...string) { got = append(got, lines...) }}
+	fmt.Fprint(w, "one\r\ntw")
+	assert.Equal(t, []string{"one"}, got)
+	fmt.Fprint(w, "o\nthree")
+	w.flush()
+	assert.Equal(t, []string{"one", "two", "three"}, got)
+}
+
+func TestExecCommand(t *testing.T) {
+	var got []string
+	w := &lineWriter{emit: func(lines ...string) { got = append(got, lines...) }}
+	assert.NoError(t, ExecCommand("echo", "hello")(context.Background(), []string{"world;", "ls"}, w))
+	assert.Equal(t, []string{"hello world; ls"}, got)
+}
+
+func TestTerminalIDs(t *testing.T) {
+	render := func() string {
+		v := via.New()
+		v.Config(via.Options{IDGenerator: via.SequentialIDs()})
+		v.Page("/", func(c *via.Context) {
+			term := c.Component(Terminal(nil))
+			c.View(func() h.H { return h.Div(term()) })
+		})
+		w := httptest.NewRecorder()
+		v.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		return w.Body.String()
+	}
+	first := render()
+	assert.Regexp(t, `id="ui-\d{8}"`, first)
+	assert.Equal(t, first, render(), "IDs come from the IDGenerator of the app")
+}
+
+func TestTerminalCanceledOnClose(t *testing.T) {
+	canceled := make(chan error, 1)
+	v := via.New()
+	v.Page("/", func(c *via.Context) {
+		term := c.Component(Terminal(map[string]Command{
+			"wait": func(ctx context.Context, _ []string, _ io.Writer) error {
+				<-ctx.Done()
+				canceled <- ctx.Err()
+				return ctx.Err()
+			},
+		}))
+		c.View(func() h.H { return h.Div(term()) })
+	})
+	w := httptest.NewRecorder()
+	v.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	body := w.Body.String()
+	ctxID := regexp.MustCompile(`<div id="([^"]+)"`).FindStringSubmatch(body)[1]
+	signalID := regexp.MustCompile(`data-bind="([^"]+)"`).FindStringSubmatch(body)[1]
+	actionID := regexp.MustCompile(`/_action/([^&]+)&`).FindStringSubmatch(body)[1]
+
+	signals := fmt.Sprintf(`{"via-ctx":%q,%q:"wait"}`, ctxID, signalID)
+	v.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/_action/"+actionID+"?datastar="+url.QueryEscape(signals), nil))
+	select {
+	case err := <-canceled:
+		t.Fatalf("command canceled with the action: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	v.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/_session/close", strings.NewReader(ctxID)))
+	select {
+	case err := <-canceled:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(time.Second):
+		t.Fatal("command not canceled when the page was closed")
+	}
+}
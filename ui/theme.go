@@ -0,0 +1,48 @@
+package ui
+
+import (
+	"github.com/go-via/via"
+	"github.com/go-via/via/h"
+)
+
+// Theme holds the CSS classes components add to their elements so they match the CSS
+// framework of the app. Empty fields add no class. The zero Theme suits classless
+// frameworks such as Pico, which style the semantic elements components render.
+type Theme struct {
+	// Text and search inputs.
+	Input string
+	// Select elements.
+	Select string
+	// Progress elements.
+	Progress string
+
+	// The list of page controls of a Paginator.
+	Pagination string
+	// The list items of a Paginator and the item of the current page.
+	PageItem, PageItemActive string
+	// The buttons of a Paginator and the button of the current page.
+	PageButton, PageButtonActive string
+}
+
+// themeKey is the key of the theme of an app, see SetTheme.
+type themeKey struct{}
+
+// SetTheme sets the classes the components of the app render with. It is typically
+// called by the plugin of a CSS framework, e.g. bootstrap.Default.
+func SetTheme(v *via.V, t Theme) {
+	v.SetValue(themeKey{}, t)
+}
+
+// themeOf returns the theme of the app of c set with SetTheme, or the zero Theme.
+func themeOf(c *via.Context) Theme {
+	t, _ := c.Value(themeKey{}).(Theme)
+	return t
+}
+
+// class returns a class attribute, or nil if name is empty.
+func class(name string) h.H {
+	if name == "" {
+		return nil
+	}
+	return h.Class(name)
+}
@@ -0,0 +1,32 @@
+package ui
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-via/via"
+	"github.com/go-via/via/h"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTheme(t *testing.T) {
+	assert.Nil(t, class(""))
+	var b bytes.Buffer
+	assert.NoError(t, class("form-control").Render(&b))
+	assert.Equal(t, ` class="form-control"`, b.String())
+
+	// each app renders with its own theme
+	render := func(v *via.V) string {
+		v.Page("/", func(c *via.Context) {
+			c.View(func() h.H { return h.Div(c.Component(DropZone(nil))()) })
+		})
+		w := httptest.NewRecorder()
+		v.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		return w.Body.String()
+	}
+	themed := via.New()
+	SetTheme(themed, Theme{Progress: "progress is-primary"})
+	assert.Contains(t, render(themed), `<progress class="progress is-primary"`)
+	assert.Contains(t, render(via.New()), `<progress max="100"`)
+}
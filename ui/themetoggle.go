@@ -0,0 +1,36 @@
+package ui
+
+import (
+	"slices"
+
+	"github.com/go-via/via"
+	"github.com/go-via/via/h"
+)
+
+// ThemeToggle returns a component with a button that switches to the next of the given
+// themes of Options.Themes, by default 'light' and 'dark'. The preference is set with
+// Context.SetTheme, so it persists and applies to all open tabs, and toggles in other
+// tabs follow the change.
+func ThemeToggle(names ...string) func(c *via.Context) {
+	if len(names) == 0 {
+		names = []string{"light", "dark"}
+	}
+	return func(c *via.Context) {
+		current := c.Signal(c.Theme())
+		toggle := c.Action(func() {
+			next := names[(slices.Index(names, current.String())+1)%len(names)]
+			current.SetValue(next)
+			c.SetTheme(next)
+			c.Sync()
+		})
+
+		c.View(func() h.H {
+			return h.Button(
+				h.Attr("aria-label", "Switch theme"),
+				h.Data("on:"+via.ThemeChangeEvent+"__window", "$"+current.ID()+"=evt.detail"),
+				toggle.OnClick(),
+				h.Span(h.Data("text", "$"+current.ID()+"||'auto'")),
+			)
+		})
+	}
+}
@@ -0,0 +1,33 @@
+// Package ui provides ready-made Via components for common interface patterns.
+//
+// Components are returned as init funcs that are registered with
+// *via.Context.Component, so each instance keeps its own signals and actions.
+//
+// Example:
+//
+//	v.Page("/", func(c *via.Context) {
+//		list := c.Component(ui.SortableList(items, func(keys []string) {
+//			// persist the new order
+//		}))
+//
+//		c.View(func() h.H {
+//			return h.Div(list())
+//		})
+//	})
+package ui
+
+import "github.com/go-via/via"
+
+// newID returns a DOM element ID that is unique within the app of c.
+func newID(c *via.Context) string {
+	return "ui-" + c.NewID()
+}
+
+// Signal is a reactive value created with *via.Context.Signal.
+type Signal interface {
+	ID() string
+	String() string
+	Int() int
+	Strings() []string
+	SetValue(v any)
+}
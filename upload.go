@@ -0,0 +1,131 @@
+package via
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// maxUploadMemory is the maximum number of bytes of an upload request kept in memory
+// while parsing. Larger file parts are buffered on disk by net/http.
+const maxUploadMemory = 32 << 20
+
+// defaultMaxUploadSize is the default of UploadOptions.MaxRequestSize.
+const defaultMaxUploadSize = 32 << 20
+
+// errUploadTooLarge is returned for uploads that exceed the limits of UploadOptions.
+var errUploadTooLarge = errors.New("upload too large")
+
+// UploadOptions limits the size of uploads, see Context.Upload.
+type UploadOptions struct {
+	// The maximum size of an upload request, including all its files.
+	// Default: 32 MiB.
+	MaxRequestSize int64
+
+	// The maximum size of a single file. Default: MaxRequestSize.
+	MaxFileSize int64
+}
+
+// maxRequestSize returns the maximum size of an upload request.
+func (o UploadOptions) maxRequestSize() int64 {
+	if o.MaxRequestSize > 0 {
+		return o.MaxRequestSize
+	}
+	return defaultMaxUploadSize
+}
+
+// maxFileSize returns the maximum size of an uploaded file.
+func (o UploadOptions) maxFileSize() int64 {
+	if o.MaxFileSize > 0 {
+		return min(o.MaxFileSize, o.maxRequestSize())
+	}
+	return o.maxRequestSize()
+}
+
+// UploadedFile is a file received through an upload endpoint registered with Upload.
+type UploadedFile struct {
+	Name        string
+	ContentType string
+	Size        int64
+	Data        []byte
+}
+
+// Upload registers a handler for files posted as multipart/form-data and returns the URL
+// of the upload endpoint. Every part of the request is read into memory and handed to the
+// handler, which runs like an action and can call Sync to update the view. Uploads that
+// exceed Options.Upload are rejected with 413 Request Entity Too Large.
+//
+// Example:
+//
+//	uploadURL := c.Upload(func(files []via.UploadedFile) {
+//		for _, f := range files {
+//			names = append(names, f.Name)
+//		}
+//		c.Sync()
+//	})
+func (c *Context) Upload(f func(files []UploadedFile)) string {
+	id := c.app.genID()
+	if f == nil {
+		c.app.logErr(c, "failed to bind upload '%s' to context: nil func", id)
+		return ""
+	}
+
+	pageCtx := c
+	if c.isComponent() {
+		pageCtx = c.parentPageCtx
+	}
+	pageCtx.uploadRegistry[id] = f
+	return fmt.Sprintf("/_upload/%s?via-ctx=%s", id, url.QueryEscape(pageCtx.id))
+}
+
+func (c *Context) getUploadFn(id string) (func([]UploadedFile), error) {
+	if f, ok := c.uploadRegistry[id]; ok {
+		return f, nil
+	}
+	return nil, fmt.Errorf("upload '%s' not found", id)
+}
+
+// readUploadedFiles reads the files of an upload request within the given limits. It
+// returns an error wrapping errUploadTooLarge if they are exceeded.
+func readUploadedFiles(w http.ResponseWriter, r *http.Request, opts UploadOptions) ([]UploadedFile, error) {
+	maxRequest, maxFile := opts.maxRequestSize(), opts.maxFileSize()
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequest)
+	if err := r.ParseMultipartForm(min(maxUploadMemory, maxRequest)); err != nil {
+		if r.MultipartForm != nil {
+			_ = r.MultipartForm.RemoveAll()
+		}
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, fmt.Errorf("%w: request exceeds %d bytes", errUploadTooLarge, maxRequest)
+		}
+		return nil, err
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	var files []UploadedFile
+	for _, headers := range r.MultipartForm.File {
+		for _, fh := range headers {
+			if fh.Size > maxFile {
+				return nil, fmt.Errorf("%w: file '%s' exceeds %d bytes", errUploadTooLarge, fh.Filename, maxFile)
+			}
+			file, err := fh.Open()
+			if err != nil {
+				return nil, err
+			}
+			data, err := io.ReadAll(io.LimitReader(file, maxFile))
+			file.Close()
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, UploadedFile{
+				Name:        fh.Filename,
+				ContentType: fh.Header.Get("Content-Type"),
+				Size:        fh.Size,
+				Data:        data,
+			})
+		}
+	}
+	return files, nil
+}
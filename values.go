@@ -0,0 +1,19 @@
+package via
+
+// SetValue stores a value of the app under the given key, e.g. the settings of a plugin
+// that its components read with Context.Value. Like with context.WithValue, keys should
+// be of an unexported type of the package that defines them, so they do not collide.
+func (v *V) SetValue(key, val any) {
+	v.values.Store(key, val)
+}
+
+// Value returns the value of the app stored under the given key with SetValue, or nil.
+func (v *V) Value(key any) any {
+	val, _ := v.values.Load(key)
+	return val
+}
+
+// Value returns the value of the app stored under the given key, see V.SetValue.
+func (c *Context) Value(key any) any {
+	return c.app.Value(key)
+}
@@ -6,18 +6,25 @@
 package via
 
 import (
+	"context"
 	"crypto/rand"
+	"crypto/tls"
 	_ "embed"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"net"
 	"net/http"
+	"net/netip"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/go-via/via/h"
 	"github.com/starfederation/datastar-go/datastar"
@@ -33,48 +40,77 @@ type V struct {
 	mux                  *http.ServeMux
 	contextRegistry      map[string]*Context
 	contextRegistryMutex sync.RWMutex
+	assetsMu             sync.RWMutex
+	assets               map[string]*asset
+	embeddedAssets       map[string]*asset
+	datastarAsset        *asset
+	islandsAsset         *asset
 	documentHeadIncludes []h.H
 	documentFootIncludes []h.H
+	documentHTMLAttrs    []h.H
+	themesCSS            string
+	pageRoutes           []string
+	hooks                hooks
+	viewerGrants         viewerGrants
 	devModePageInitFnMap map[string]func(*Context)
+	server               *http.Server
+	extraServers         []*http.Server
+	trustedProxies       []netip.Prefix
+	serverMu             sync.Mutex
+	shutdownChan         chan struct{}
+	shutdownOnce         sync.Once
+	apiPatterns          map[string]bool
+	namedRoutes          map[string]string
+	pendingLinks         []pendingLink
+	scheduledJobs        []*scheduledJob
+	schedulerStarted     bool
+	changeFeeds          []*changeFeedSub
+	jobs                 *Jobs
+	jobsOnce             sync.Once
+	events               *Events
+	eventsOnce           sync.Once
+	baseCtx              context.Context
+	cancelBaseCtx        context.CancelFunc
+	desktopToken         string
+	values               sync.Map
 }
 
 func (v *V) logFatal(format string, a ...any) {
 	log.Printf("[fatal] msg=%q", fmt.Sprintf(format, a...))
 }
 
-func (v *V) logErr(c *Context, format string, a ...any) {
-	cRef := ""
-	if c != nil && c.id != "" {
-		cRef = fmt.Sprintf("via-ctx=%q ", c.id)
+// ctxLogRef returns the log fields that identify the given context and its latest request.
+func ctxLogRef(c *Context) string {
+	if c == nil || c.id == "" {
+		return ""
+	}
+	if reqID := c.RequestID(); reqID != "" {
+		return fmt.Sprintf("via-ctx=%q req-id=%q ", c.id, reqID)
 	}
+	return fmt.Sprintf("via-ctx=%q ", c.id)
+}
+
+func (v *V) logErr(c *Context, format string, a ...any) {
+	cRef := ctxLogRef(c)
 	log.Printf("[error] %smsg=%q", cRef, fmt.Sprintf(format, a...))
 }
 
 func (v *V) logWarn(c *Context, format string, a ...any) {
-	cRef := ""
-	if c != nil && c.id != "" {
-		cRef = fmt.Sprintf("via-ctx=%q ", c.id)
-	}
+	cRef := ctxLogRef(c)
 	if v.cfg.LogLvl >= LogLevelWarn {
 		log.Printf("[warn] %smsg=%q", cRef, fmt.Sprintf(format, a...))
 	}
 }
 
 func (v *V) logInfo(c *Context, format string, a ...any) {
-	cRef := ""
-	if c != nil && c.id != "" {
-		cRef = fmt.Sprintf("via-ctx=%q ", c.id)
-	}
+	cRef := ctxLogRef(c)
 	if v.cfg.LogLvl >= LogLevelInfo {
 		log.Printf("[info] %smsg=%q", cRef, fmt.Sprintf(format, a...))
 	}
 }
 
 func (v *V) logDebug(c *Context, format string, a ...any) {
-	cRef := ""
-	if c != nil && c.id != "" {
-		cRef = fmt.Sprintf("via-ctx=%q ", c.id)
-	}
+	cRef := ctxLogRef(c)
 	if v.cfg.LogLvl == LogLevelDebug {
 		log.Printf("[debug] %smsg=%q", cRef, fmt.Sprintf(format, a...))
 	}
@@ -88,6 +124,109 @@ func (v *V) Config(cfg Options) {
 	if cfg.DocumentTitle != "" {
 		v.cfg.DocumentTitle = cfg.DocumentTitle
 	}
+	if cfg.DevMode {
+		v.cfg.DevMode = true
+	}
+	if cfg.NoDevMode {
+		v.cfg.DevMode = false
+	}
+	if cfg.ServerAddress != "" {
+		v.cfg.ServerAddress = cfg.ServerAddress
+	}
+	if cfg.TrustedProxies != nil {
+		v.cfg.TrustedProxies = cfg.TrustedProxies
+		v.trustedProxies = v.parseTrustedProxies(cfg.TrustedProxies)
+	}
+	if cfg.Addresses != nil {
+		v.cfg.Addresses = cfg.Addresses
+	}
+	if cfg.AutoTLS != nil {
+		v.cfg.AutoTLS = cfg.AutoTLS
+	}
+	if cfg.HTTPServer != nil {
+		v.cfg.HTTPServer = cfg.HTTPServer
+	}
+	v.cfg.SecurityHeaders = cfg.SecurityHeaders.merge(v.cfg.SecurityHeaders)
+	if cfg.RouteSecurityHeaders != nil {
+		v.cfg.RouteSecurityHeaders = cfg.RouteSecurityHeaders
+	}
+	if cfg.Datastar.Script != nil {
+		v.cfg.Datastar.Script = cfg.Datastar.Script
+	}
+	if cfg.Datastar.DebugScript != nil {
+		v.cfg.Datastar.DebugScript = cfg.Datastar.DebugScript
+	}
+	if cfg.Datastar.URL != "" {
+		v.cfg.Datastar.URL = cfg.Datastar.URL
+	}
+	if cfg.Datastar.Integrity != "" {
+		v.cfg.Datastar.Integrity = cfg.Datastar.Integrity
+	}
+	if cfg.Datastar.Extensions != nil {
+		v.cfg.Datastar.Extensions = cfg.Datastar.Extensions
+	}
+	if cfg.Themes != nil {
+		v.cfg.Themes = cfg.Themes
+		v.themesCSS = themesCSS(cfg.Themes)
+	}
+	if cfg.SSE.RetryInterval != 0 {
+		v.cfg.SSE.RetryInterval = cfg.SSE.RetryInterval
+	}
+	if cfg.SSE.WriteTimeout != 0 {
+		v.cfg.SSE.WriteTimeout = cfg.SSE.WriteTimeout
+	}
+	if cfg.SSE.PatchBuffer != 0 {
+		v.cfg.SSE.PatchBuffer = cfg.SSE.PatchBuffer
+	}
+	if cfg.Upload.MaxRequestSize != 0 {
+		v.cfg.Upload.MaxRequestSize = cfg.Upload.MaxRequestSize
+	}
+	if cfg.Upload.MaxFileSize != 0 {
+		v.cfg.Upload.MaxFileSize = cfg.Upload.MaxFileSize
+	}
+	if cfg.ActionTimeout != 0 {
+		v.cfg.ActionTimeout = cfg.ActionTimeout
+	}
+	if cfg.OnError != nil {
+		v.cfg.OnError = cfg.OnError
+	}
+	if cfg.CORS != nil {
+		v.cfg.CORS = cfg.CORS
+	}
+	if cfg.CSP != nil {
+		v.cfg.CSP = cfg.CSP
+	}
+	if cfg.ClientBinding != 0 {
+		v.cfg.ClientBinding = cfg.ClientBinding
+	}
+	if cfg.TenantResolver != nil {
+		v.cfg.TenantResolver = cfg.TenantResolver
+	}
+	if cfg.Audit != nil {
+		v.cfg.Audit = cfg.Audit
+	}
+	if cfg.IDGenerator != nil {
+		v.cfg.IDGenerator = cfg.IDGenerator
+	}
+	if cfg.StaticSnapshot != nil {
+		v.cfg.StaticSnapshot = cfg.StaticSnapshot
+	}
+	if cfg.HTMLFormat != htmlFormatUndefined {
+		v.cfg.HTMLFormat = cfg.HTMLFormat
+	}
+	if cfg.Compression != compressionUndefined {
+		v.cfg.Compression = cfg.Compression
+	}
+	if cfg.ViewTransitions {
+		v.cfg.ViewTransitions = cfg.ViewTransitions
+	}
+	if cfg.NoJSFallback {
+		v.cfg.NoJSFallback = cfg.NoJSFallback
+	}
+	if cfg.DevMode || cfg.Datastar.Script != nil || cfg.Datastar.DebugScript != nil {
+		v.updateDatastarAsset()
+	}
+	// Plugins run last so they see the rest of the config, e.g. DevMode.
 	if cfg.Plugins != nil {
 		for _, plugin := range cfg.Plugins {
 			if plugin != nil {
@@ -95,12 +234,11 @@ func (v *V) Config(cfg Options) {
 			}
 		}
 	}
-	if cfg.DevMode != v.cfg.DevMode {
-		v.cfg.DevMode = cfg.DevMode
-	}
-	if cfg.ServerAddress != "" {
-		v.cfg.ServerAddress = cfg.ServerAddress
-	}
+}
+
+// DevMode reports whether the development mode is enabled.
+func (v *V) DevMode() bool {
+	return v.cfg.DevMode
 }
 
 // AppendToHead appends the given h.H nodes to the head of the base HTML document.
@@ -113,6 +251,16 @@ func (v *V) AppendToHead(elements ...h.H) {
 	}
 }
 
+// AppendToHTMLAttrs appends the given attributes to the html element of the base HTML
+// document. Useful for document-wide settings such as the color scheme of CSS frameworks.
+func (v *V) AppendToHTMLAttrs(attrs ...h.H) {
+	for _, a := range attrs {
+		if a != nil {
+			v.documentHTMLAttrs = append(v.documentHTMLAttrs, a)
+		}
+	}
+}
+
 // AppendToFoot appends the given h.H nodes to the end of the base HTML document body.
 // Useful for including JS scripts.
 func (v *V) AppendToFoot(elements ...h.H) {
@@ -134,6 +282,12 @@ func (v *V) AppendToFoot(elements ...h.H) {
 //		})
 //	})
 func (v *V) Page(route string, initContextFn func(c *Context)) {
+	v.page("", route, initContextFn)
+}
+
+// page registers a page for the route on the given host, or on any host if empty.
+func (v *V) page(host, route string, initContextFn func(c *Context)) {
+	pattern := host + route
 	// check for panics
 	func() {
 		defer func() {
@@ -144,54 +298,161 @@ func (v *V) Page(route string, initContextFn func(c *Context)) {
 		}()
 		c := newContext("", "", v)
 		initContextFn(c)
-		c.view()
+		if !c.aborted {
+			c.view()
+		}
 		c.stopAllRoutines()
+		c.dispose()
 	}()
 
+	v.pageRoutes = append(v.pageRoutes, pattern)
+
 	// save page init function allows devmode to restore persisted ctx later
 	if v.cfg.DevMode {
-		v.devModePageInitFnMap[route] = initContextFn
+		v.devModePageInitFnMap[pattern] = initContextFn
 	}
-	v.mux.HandleFunc("GET "+route, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		v.logDebug(nil, "GET %s", r.URL.String())
+	v.mux.HandleFunc("GET "+pattern, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		v.logDebug(nil, "GET %s client=%s", r.URL.String(), v.clientIP(r))
 		if strings.Contains(r.URL.Path, "favicon") ||
 			strings.Contains(r.URL.Path, ".well-known") ||
 			strings.Contains(r.URL.Path, "js.map") {
 			return
 		}
-		id := fmt.Sprintf("%s_/%s", route, genRandID())
-		c := newContext(id, route, v)
-		routeParams := extractParams(route, r.URL.Path)
-		c.injectRouteParams(routeParams)
+		if c := v.initPage(w, r, pattern, route, initContextFn); c != nil {
+			v.servePage(w, r, c)
+		}
+	}))
+}
+
+// initPage creates the context of a page request and runs the init func of the page. It
+// returns nil if the page load was aborted or redirected, after writing the response.
+func (v *V) initPage(w http.ResponseWriter, r *http.Request, pattern, route string, initContextFn func(c *Context)) *Context {
+	id := fmt.Sprintf("%s_/%s", pattern, v.genContextID())
+	c := v.newPageContext(w, r, id, pattern)
+	c.injectRouteParams(extractParams(route, r.URL.Path))
+	if v.runBeforePage(c) {
 		initContextFn(c)
+	}
+	if c.aborted || c.redirect != "" {
+		c.stopAllRoutines()
+		c.dispose()
+		if c.redirect != "" {
+			http.Redirect(w, r, c.redirect, http.StatusFound)
+		} else {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		}
+		return nil
+	}
+	return c
+}
+
+// newPageContext creates the context of a page request with the given ID and route.
+func (v *V) newPageContext(w http.ResponseWriter, r *http.Request, id, route string) *Context {
+	c := newContext(id, route, v)
+	c.setRequestID(requestID(w, r))
+	c.injectQueryParams(r.URL.Query())
+	c.clientIP = v.clientIP(r)
+	c.baseURL = v.baseURL(r)
+	c.requestURI = r.URL.RequestURI()
+	c.fingerprint = v.clientFingerprint(r)
+	c.tenant = v.tenant(r)
+	c.theme.Store(v.themeFromRequest(r))
+	c.cookies = r.Cookies()
+	if v.cfg.CSP != nil {
+		c.nonce = genNonce()
+		w.Header().Set(v.cfg.CSP.headerName(), v.cfg.CSP.header(c.nonce))
+	}
+	return c
+}
+
+// servePage keeps the initialized context of a page request and writes the page.
+func (v *V) servePage(w http.ResponseWriter, r *http.Request, c *Context) {
+	static := v.isStaticRender(r)
+	if static {
+		// Static pages have no live connection, so the context is not kept.
+		c.stopAllRoutines()
+		c.dispose()
+	} else {
 		v.registerCtx(c)
-		if v.cfg.DevMode {
+		if v.cfg.DevMode && c.viewing == nil {
 			v.devModePersist(c)
 		}
-		headElements := []h.H{}
-		headElements = append(headElements, v.documentHeadIncludes...)
+	}
+	headElements := []h.H{v.cfg.Datastar.importMap(v.datastarSrc(), c.nonce)}
+	headElements = append(headElements, withNonce(v.documentHeadIncludes, c.nonce)...)
+	headElements = append(headElements, v.themeHead(c.nonce)...)
+	headElements = append(headElements, c.headTags...)
+	if !static {
+		devSignals := ""
+		if v.cfg.DevMode {
+			devSignals = ",'" + metricsSignal + "':''"
+		}
 		headElements = append(headElements,
-			h.Meta(h.Data("signals", fmt.Sprintf("{'via-ctx':'%s'}", id))),
+			h.Meta(h.Data("signals", fmt.Sprintf("{'via-ctx':'%s'%s}", c.id, devSignals))),
 			h.Meta(h.Data("init", "@get('/_sse')")),
 			h.Meta(h.Data("init", fmt.Sprintf(`window.addEventListener('beforeunload', (evt) => {
-			navigator.sendBeacon('/_session/close', '%s');});`, c.id))),
+		navigator.sendBeacon('/_session/close', '%s');});`, c.id))),
+			clientErrorScript(c),
 		)
+		if v.cfg.NoJSFallback {
+			headElements = append(headElements, noJSScript())
+		}
+	}
 
-		bodyElements := []h.H{c.view()}
-		bodyElements = append(bodyElements, v.documentFootIncludes...)
-		if v.cfg.DevMode {
-			bodyElements = append(bodyElements, h.Script(h.Type("module"),
-				h.Src("https://cdn.jsdelivr.net/gh/dataSPA/dataSPA-inspector@latest/dataspa-inspector.bundled.js")))
-			bodyElements = append(bodyElements, h.Raw("<dataspa-inspector/>"))
-		}
-		view := h.HTML5(h.HTML5Props{
-			Title:     v.cfg.DocumentTitle,
-			Head:      headElements,
-			Body:      bodyElements,
-			HTMLAttrs: []h.H{},
-		})
-		_ = view.Render(w)
-	}))
+	start := time.Now()
+	viewHTML, err := c.renderView()
+	if err != nil {
+		v.logErr(c, "render page failed: %v", err)
+		v.reportErr(c, PhaseRender, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	// gesture triggers rendered from now on patch the gesture head in, see useGestures
+	c.headSent.Store(true)
+	if c.gestures.Load() && !static {
+		headElements = append(headElements, gestureHead()...)
+	}
+	var bodyElements []h.H
+	if v.cfg.NoJSFallback && !static {
+		bodyElements = append(bodyElements, noJSForm(c))
+	}
+	bodyElements = append(bodyElements, h.Raw(viewHTML))
+	bodyElements = append(bodyElements, withNonce(v.documentFootIncludes, c.nonce)...)
+	bodyElements = append(bodyElements, v.cfg.Datastar.extensions(c.nonce)...)
+	if !static {
+		bodyElements = append(bodyElements, v.islandsScript(c.nonce))
+	}
+	if v.cfg.DevMode && !static {
+		bodyElements = append(bodyElements, h.Script(h.Type("module"), h.If(c.nonce != "", h.Attr("nonce", c.nonce)),
+			h.Src("https://cdn.jsdelivr.net/gh/dataSPA/dataSPA-inspector@latest/dataspa-inspector.bundled.js")))
+		bodyElements = append(bodyElements, h.Raw("<dataspa-inspector/>"), metricsOverlay())
+	}
+	title := v.cfg.DocumentTitle
+	if c.title != "" {
+		title = c.title
+	}
+	view := h.HTML5(h.HTML5Props{
+		Title:             title,
+		Nonce:             c.nonce,
+		DatastarSrc:       v.datastarSrc(),
+		DatastarIntegrity: v.cfg.Datastar.Integrity,
+		Head:              headElements,
+		Body:              bodyElements,
+		HTMLAttrs:         append(slices.Clip(v.documentHTMLAttrs), h.If(c.Theme() != "", h.Attr("data-theme", c.Theme()))),
+	})
+	doc, err := h.Render(view)
+	if err != nil {
+		v.logErr(c, "render page failed: %v", err)
+		v.reportErr(c, PhaseRender, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	html := v.formatHTML(doc)
+	v.runAfterPageRender(c, html, time.Since(start))
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	cw, closeFn := v.compressResponse(w, r)
+	_, _ = io.WriteString(cw, html)
+	_ = closeFn()
 }
 
 func (v *V) registerCtx(c *Context) {
@@ -219,6 +480,7 @@ func (v *V) unregisterCtx(c *Context) {
 	defer v.contextRegistryMutex.Unlock()
 	v.logDebug(c, "ctx removed from registry")
 	delete(v.contextRegistry, c.id)
+	c.dispose()
 	v.logDebug(nil, "number of sessions in registry: %d", v.currSessionNum())
 }
 
@@ -237,10 +499,183 @@ func (v *V) HandleFunc(pattern string, f http.HandlerFunc) {
 	v.mux.HandleFunc(pattern, f)
 }
 
-// Start starts the Via HTTP server on the given address.
+// ServeHTTP serves the Via app, so it can be mounted in another router or server.
+func (v *V) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if v.desktopToken != "" && !v.checkDesktop(w, r) {
+		return
+	}
+	v.setSecurityHeaders(w, r)
+	if v.handleCORS(w, r) {
+		return
+	}
+	v.mux.ServeHTTP(w, r)
+}
+
+// Start starts the Via HTTP server on the given address. If Options.HTTPServer is set,
+// that server is used instead, and it serves TLS if its TLSConfig holds certificates.
+// With Options.AutoTLS, Start serves HTTPS on :443 with certificates from Let's Encrypt.
+// Options.Addresses are served alongside the main address.
+// Start blocks until the server is shut down with Shutdown.
 func (v *V) Start() {
-	v.logInfo(nil, "via started at [%s]", v.cfg.ServerAddress)
-	log.Fatalf("[fatal] %v", http.ListenAndServe(v.cfg.ServerAddress, v.mux))
+	if err := v.checkLinks(); err != nil {
+		log.Fatalf("[fatal] %v", err)
+	}
+	srv := v.httpServer()
+	for _, addr := range v.cfg.Addresses {
+		if err := v.serveAddress(srv, addr); err != nil {
+			log.Fatalf("[fatal] %v", err)
+		}
+	}
+	v.startScheduler()
+	v.logInfo(nil, "via started at [%s]", srv.Addr)
+	var err error
+	if v.cfg.AutoTLS != nil {
+		err = v.autoTLSServe(srv)
+	} else if hasTLSCertificates(srv) {
+		err = srv.ListenAndServeTLS("", "")
+	} else {
+		err = srv.ListenAndServe()
+	}
+	if !errors.Is(err, http.ErrServerClosed) {
+		log.Fatalf("[fatal] %v", err)
+	}
+}
+
+// ServeListener serves the Via app on the given listener, e.g. a Unix socket, a socket
+// passed by systemd, or a listener inherited for a zero-downtime restart. It serves TLS
+// if the TLSConfig of Options.HTTPServer holds certificates. ServerAddress is ignored.
+// ServeListener blocks until the server is shut down with Shutdown, then returns nil.
+//
+// Example:
+//
+//	l, err := net.Listen("unix", "/run/myapp.sock")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	log.Fatal(v.ServeListener(l))
+func (v *V) ServeListener(l net.Listener) error {
+	if err := v.checkLinks(); err != nil {
+		return err
+	}
+	srv := v.httpServer()
+	v.startScheduler()
+	v.logInfo(nil, "via started at [%s]", l.Addr())
+	var err error
+	if hasTLSCertificates(srv) {
+		err = srv.ServeTLS(l, "", "")
+	} else {
+		err = srv.Serve(l)
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// Shutdown gracefully shuts down the server started with Start or ServeListener. Open SSE streams are
+// closed right away and scheduled jobs stop, then Shutdown waits for in-flight requests until ctx is done.
+func (v *V) Shutdown(ctx context.Context) error {
+	v.serverMu.Lock()
+	srv := v.server
+	extraSrvs := v.extraServers
+	v.serverMu.Unlock()
+	v.shutdownOnce.Do(func() {
+		close(v.shutdownChan)
+		v.cancelBaseCtx()
+	})
+	for _, extraSrv := range extraSrvs {
+		_ = extraSrv.Shutdown(ctx)
+	}
+	if srv == nil {
+		return nil
+	}
+	return srv.Shutdown(ctx)
+}
+
+// serveAddress starts serving the Via app on an additional address in the background,
+// sharing the handler and timeouts of the main server. The address is bound and its
+// certificates loaded before serveAddress returns, so configuration errors are returned
+// to the caller.
+func (v *V) serveAddress(main *http.Server, addr Address) error {
+	srv := &http.Server{
+		Addr:              addr.Addr,
+		Handler:           main.Handler,
+		TLSConfig:         addr.TLSConfig,
+		ReadTimeout:       main.ReadTimeout,
+		ReadHeaderTimeout: main.ReadHeaderTimeout,
+		WriteTimeout:      main.WriteTimeout,
+		IdleTimeout:       main.IdleTimeout,
+		MaxHeaderBytes:    main.MaxHeaderBytes,
+		Protocols:         main.Protocols,
+	}
+	if addr.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(addr.CertFile, addr.KeyFile)
+		if err != nil {
+			return fmt.Errorf("address %s: %w", addr.Addr, err)
+		}
+		if srv.TLSConfig == nil {
+			srv.TLSConfig = &tls.Config{}
+		} else {
+			srv.TLSConfig = srv.TLSConfig.Clone()
+		}
+		srv.TLSConfig.Certificates = append(srv.TLSConfig.Certificates, cert)
+	}
+	useTLS := hasTLSCertificates(srv)
+	listenAddr := srv.Addr
+	if listenAddr == "" && useTLS {
+		listenAddr = ":https"
+	} else if listenAddr == "" {
+		listenAddr = ":http"
+	}
+	l, err := net.Listen("tcp", listenAddr)
+	if err != nil {
+		return err
+	}
+	v.serverMu.Lock()
+	v.extraServers = append(v.extraServers, srv)
+	v.serverMu.Unlock()
+
+	go func() {
+		v.logInfo(nil, "via started at [%s]", l.Addr())
+		var err error
+		if useTLS {
+			err = srv.ServeTLS(l, "", "")
+		} else {
+			err = srv.Serve(l)
+		}
+		if !errors.Is(err, http.ErrServerClosed) {
+			v.logErr(nil, "serve %s failed: %v", l.Addr(), err)
+		}
+	}()
+	return nil
+}
+
+func hasTLSCertificates(srv *http.Server) bool {
+	return srv.TLSConfig != nil && (len(srv.TLSConfig.Certificates) > 0 || srv.TLSConfig.GetCertificate != nil)
+}
+
+// httpServer returns the *http.Server used by Start, configured from Options.
+func (v *V) httpServer() *http.Server {
+	v.serverMu.Lock()
+	defer v.serverMu.Unlock()
+	if v.server != nil {
+		return v.server
+	}
+	srv := v.cfg.HTTPServer
+	if srv == nil {
+		srv = &http.Server{}
+	}
+	if srv.Addr == "" && v.cfg.AutoTLS != nil {
+		srv.Addr = ":443"
+	}
+	if srv.Addr == "" {
+		srv.Addr = v.cfg.ServerAddress
+	}
+	if srv.Handler == nil {
+		srv.Handler = v
+	}
+	v.server = srv
+	return srv
 }
 
 func (v *V) devModePersist(c *Context) {
@@ -348,29 +783,57 @@ const (
 )
 
 type patch struct {
-	typ     patchType
-	content string
+	typ      patchType
+	content  string
+	selector string
+	mode     datastar.ElementPatchMode
+	signals  int // the number of signals of a signal patch
 }
 
-// New creates a new *V application with default configuration.
+// devModeEnv is the environment variable that enables DevMode by default, set by the
+// 'via dev' command.
+const devModeEnv = "VIA_DEV"
+
+// New creates a new *V application with default configuration. DevMode is enabled if
+// the VIA_DEV environment variable is set.
 func New() *V {
 	mux := http.NewServeMux()
+	baseCtx, cancelBaseCtx := context.WithCancel(context.Background())
 
 	v := &V{
 		mux:                  mux,
 		contextRegistry:      make(map[string]*Context),
 		devModePageInitFnMap: make(map[string]func(*Context)),
+		shutdownChan:         make(chan struct{}),
+		apiPatterns:          make(map[string]bool),
+		assets:               make(map[string]*asset),
+		baseCtx:              baseCtx,
+		cancelBaseCtx:        cancelBaseCtx,
 		cfg: Options{
-			DevMode:       false,
+			DevMode:       os.Getenv(devModeEnv) != "",
 			ServerAddress: ":3000",
 			LogLvl:        LogLevelInfo,
 			DocumentTitle: "⚡ Via",
+			Compression:   CompressionAuto,
+			HTMLFormat:    HTMLFormatAuto,
 		},
 	}
 
-	v.mux.HandleFunc("GET /_datastar.js", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/javascript")
-		_, _ = w.Write(datastarJS)
+	v.updateDatastarAsset()
+	v.mux.HandleFunc("GET "+datastarPath, func(w http.ResponseWriter, r *http.Request) {
+		v.serveAsset(w, r, v.loadAsset(&v.datastarAsset), false)
+	})
+	v.mux.HandleFunc("GET "+assetsPath+"{name...}", func(w http.ResponseWriter, r *http.Request) {
+		if a, ok := v.getAsset(r.PathValue("name")); ok {
+			v.serveAsset(w, r, a, true)
+			return
+		}
+		// embedded assets are served by their unhashed names too, see EmbedAssets
+		if a, ok := v.getEmbeddedAsset(r.PathValue("name")); ok {
+			v.serveAsset(w, r, a, false)
+			return
+		}
+		http.NotFound(w, r)
 	})
 
 	v.mux.HandleFunc("GET /_sse", func(w http.ResponseWriter, r *http.Request) {
@@ -388,12 +851,25 @@ func New() *V {
 			v.logErr(nil, "sse stream failed to start: %v", err)
 			return
 		}
+		c.setRequestID(requestID(w, r))
+		if !v.checkClientBinding(c, w, r) {
+			return
+		}
 
-		sse := datastar.NewSSE(w, r, datastar.WithCompression(datastar.WithBrotli(datastar.WithBrotliLevel(5))))
+		sse := datastar.NewSSE(w, r, v.sseOptions()...)
+		rc := http.NewResponseController(w)
+		if v.cfg.NoJSFallback {
+			_ = sse.ExecuteScript(noJSLiveScript)
+		}
 
 		v.logDebug(c, "SSE connection established")
+		v.runOnSSEConnect(c)
 
 		go func() {
+			if c.viewing != nil {
+				c.syncViewer()
+				return
+			}
 			if v.cfg.DevMode {
 				c.Sync()
 				return
@@ -406,26 +882,28 @@ func New() *V {
 			case <-sse.Context().Done():
 				v.logDebug(c, "SSE connection ended")
 				return
+			case <-v.shutdownChan:
+				v.logDebug(c, "SSE connection closed on shutdown")
+				return
 			case patch, ok := <-c.patchChan:
 				if !ok {
 					continue
 				}
-				switch patch.typ {
-				case patchTypeElements:
-					if err := sse.PatchElements(patch.content); err != nil {
-						v.logErr(c, "PatchElements failed: %v", err)
-						continue
-					}
-				case patchTypeSignals:
-					if err := sse.PatchSignals([]byte(patch.content)); err != nil {
-						v.logErr(c, "PatchSignals failed: %v", err)
-						continue
-					}
-				case patchTypeScript:
-					if err := sse.ExecuteScript(patch.content, datastar.WithExecuteScriptAutoRemove(true)); err != nil {
-						v.logErr(c, "ExecuteScript failed: %v", err)
-						continue
+				if v.cfg.SSE.WriteTimeout > 0 {
+					_ = rc.SetWriteDeadline(time.Now().Add(v.cfg.SSE.WriteTimeout))
+				}
+				if err := v.writePatch(sse, c, patch); err != nil {
+					v.logErr(c, "%v", err)
+					v.reportErr(c, PhaseSSE, err)
+					if errors.Is(err, os.ErrDeadlineExceeded) {
+						v.logWarn(c, "SSE connection closed: write timeout exceeded")
+						return
 					}
+					continue
+				}
+				c.metrics.flushed(patch, time.Now())
+				if v.cfg.DevMode {
+					_ = v.writePatch(sse, c, c.metricsPatch())
 				}
 			}
 		}
@@ -441,22 +919,76 @@ func New() *V {
 			v.logErr(nil, "action '%s' failed: %v", actionID, err)
 			return
 		}
-		actionFn, err := c.getActionFn(actionID)
+		c.setRequestID(requestID(w, r))
+		if !v.checkClientBinding(c, w, r) {
+			return
+		}
+		v.runAction(w, r, c, actionID, sigs)
+	})
+
+	v.mux.HandleFunc("POST "+formPath, v.serveForm)
+
+	v.mux.HandleFunc("GET /_viewer/{token}", v.serveViewer)
+
+	v.mux.HandleFunc("GET /_recording", v.serveRecording)
+
+	v.mux.HandleFunc("POST /_replay", v.serveReplay)
+
+	v.mux.HandleFunc("POST /_upload/{id}", func(w http.ResponseWriter, r *http.Request) {
+		uploadID := r.PathValue("id")
+		cID := r.URL.Query().Get("via-ctx")
+		c, err := v.getCtx(cID)
 		if err != nil {
-			v.logDebug(c, "action '%s' failed: %v", actionID, err)
+			v.logErr(nil, "upload '%s' failed: %v", uploadID, err)
+			w.WriteHeader(http.StatusNotFound)
 			return
 		}
-		// log err if actionFn panics
+		c.setRequestID(requestID(w, r))
+		if !v.checkClientBinding(c, w, r) {
+			return
+		}
+		uploadFn, err := c.getUploadFn(uploadID)
+		if err != nil {
+			v.logDebug(c, "upload '%s' failed: %v", uploadID, err)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if !v.runBeforeAction(c, uploadID) {
+			v.logDebug(c, "upload '%s' rejected by hook", uploadID)
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		files, err := readUploadedFiles(w, r, v.cfg.Upload)
+		if errors.Is(err, errUploadTooLarge) {
+			v.logWarn(c, "upload '%s' rejected: %v", uploadID, err)
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+		if err != nil {
+			v.logErr(c, "upload '%s' failed: %v", uploadID, err)
+			v.reportErr(c, PhaseUpload, err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, endAction := c.beginAction(r.Context(), 0, w.Header().Get("X-Request-ID"))
+		defer endAction()
+		start := time.Now()
+		var uploadErr error
+		// log err if uploadFn panics
 		defer func() {
 			if r := recover(); r != nil {
-				v.logErr(c, "action '%s' failed: %v", actionID, r)
+				v.logErr(c, "upload '%s' failed: %v", uploadID, r)
+				uploadErr = panicErr(r)
+				v.reportErr(c, PhaseUpload, uploadErr)
 			}
+			v.runAfterAction(c, uploadID, time.Since(start), uploadErr)
 		}()
-
-		c.injectSignals(sigs)
-		actionFn()
+		uploadFn(files)
 	})
 
+	v.mux.HandleFunc("POST "+clientErrorsPath, v.serveClientError)
+	v.mux.HandleFunc("POST "+noJSPath, v.serveNoJSAction)
+
 	v.mux.HandleFunc("POST /_session/close", func(w http.ResponseWriter, r *http.Request) {
 		body, err := io.ReadAll(r.Body)
 		if err != nil {
@@ -504,3 +1036,85 @@ func extractParams(pattern, path string) map[string]string {
 	}
 	return params
 }
+
+// runAction runs the action of a context with the signals sent by the browser, with the
+// hooks, timeout and audit of actions. It answers rejected actions with an error status
+// and reports whether the action ran.
+func (v *V) runAction(w http.ResponseWriter, r *http.Request, c *Context, actionID string, sigs map[string]any) bool {
+	actionFn, err := c.getActionFn(actionID)
+	if err != nil {
+		v.logDebug(c, "action '%s' failed: %v", actionID, err)
+		if c.viewing != nil {
+			// actions of read-only viewers are rejected unless allowed
+			w.WriteHeader(http.StatusForbidden)
+		}
+		return false
+	}
+	return v.invokeAction(w, r, c, actionID, actionFn, sigs)
+}
+
+// invokeAction runs actionFn as the action with the given ID, with the hooks, timeout
+// and error reporting of actions. It returns false if a hook rejected the action.
+func (v *V) invokeAction(w http.ResponseWriter, r *http.Request, c *Context, actionID string, actionFn func(), sigs map[string]any) bool {
+	if !v.runBeforeAction(c, actionID) {
+		v.logDebug(c, "action '%s' rejected by hook", actionID)
+		w.WriteHeader(http.StatusForbidden)
+		return false
+	}
+	name := c.actionName(actionID)
+	runAction := func() (err error) {
+		// log err if actionFn panics
+		defer func() {
+			if r := recover(); r != nil {
+				v.logErr(c, "action '%s' failed: %v", name, r)
+				err = panicErr(r)
+				v.reportErr(c, PhaseAction, err)
+			}
+		}()
+		actionFn()
+		return nil
+	}
+
+	c.metrics.actionReceived(time.Now())
+	timeout := c.getActionTimeout(actionID)
+	// actions of a context run one at a time, see Options.ActionTimeout
+	ctx, endAction := c.beginAction(r.Context(), timeout, w.Header().Get("X-Request-ID"))
+	c.injectSignals(sigs)
+	c.record(actionID, sigs)
+	before := c.signalValues()
+	start := time.Now()
+	finish := func(err error) {
+		v.runAfterAction(c, actionID, time.Since(start), err)
+		v.audit(c, actionID, before, time.Since(start), err)
+		if ids := r.URL.Query().Get(reconcileParam); ids != "" {
+			c.reconcile(strings.Split(ids, ","))
+		}
+	}
+	if timeout <= 0 {
+		defer endAction()
+		finish(runAction())
+		return true
+	}
+	done := make(chan struct{})
+	var actionErr error
+	go func() {
+		// the action ends when actionFn returns, which may be after the timeout
+		defer endAction()
+		defer close(done)
+		actionErr = runAction()
+	}()
+	select {
+	case <-done:
+		finish(actionErr)
+	case <-ctx.Done():
+		err := ctx.Err()
+		if errors.Is(err, context.DeadlineExceeded) {
+			err = fmt.Errorf("action '%s': %w", name, ErrActionTimeout)
+			v.logWarn(c, "action '%s' timed out after %v", name, timeout)
+			v.reportErr(c, PhaseAction, err)
+			go c.sendPatchWait(patch{typ: patchTypeSignals, content: fmt.Sprintf(`{%q:%q}`, actionTimeoutSignal, actionID), signals: 1})
+		}
+		finish(err)
+	}
+	return true
+}
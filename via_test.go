@@ -1,12 +1,43 @@
 package via
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"mime/multipart"
+	"net"
 	"net/http"
+	"net/http/cookiejar"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"testing/fstest"
+	"time"
 
+	"github.com/go-via/via/desktop"
+	"github.com/go-via/via/feed"
 	"github.com/go-via/via/h"
+	"github.com/go-via/via/seo"
+	"github.com/starfederation/datastar-go/datastar"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
 )
 
 func TestPageRoute(t *testing.T) {
@@ -37,6 +68,240 @@ func TestDatastarJS(t *testing.T) {
 	assert.Contains(t, w.Body.String(), "🖕JS_DS🚀")
 }
 
+func TestAssets(t *testing.T) {
+	v := New()
+	src := v.datastarSrc()
+	assert.Regexp(t, `^/_assets/datastar\.[0-9a-f]{16}\.js$`, src)
+
+	w := httptest.NewRecorder()
+	v.mux.ServeHTTP(w, httptest.NewRequest("GET", src, nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "public, max-age=31536000, immutable", w.Header().Get("Cache-Control"))
+	assert.NotEmpty(t, w.Header().Get("Last-Modified"))
+	assert.Equal(t, datastarJS, w.Body.Bytes())
+
+	req := httptest.NewRequest("GET", "/_datastar.js", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w = httptest.NewRecorder()
+	v.mux.ServeHTTP(w, req)
+	assert.Equal(t, "no-cache", w.Header().Get("Cache-Control"))
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	gr, err := gzip.NewReader(w.Body)
+	assert.NoError(t, err)
+	body, _ := io.ReadAll(gr)
+	assert.Equal(t, datastarJS, body)
+
+	req = httptest.NewRequest("GET", "/_datastar.js", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	req.Header.Set("If-None-Match", w.Header().Get("ETag"))
+	w = httptest.NewRecorder()
+	v.mux.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+
+	w = httptest.NewRecorder()
+	v.mux.ServeHTTP(w, httptest.NewRequest("GET", "/_assets/datastar.0000000000000000.js", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	v.Config(Options{Datastar: DatastarBundle{Script: []byte("custom")}})
+	assert.NotEqual(t, src, v.datastarSrc())
+	w = httptest.NewRecorder()
+	v.mux.ServeHTTP(w, httptest.NewRequest("GET", src, nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestEmbedAssets(t *testing.T) {
+	v := New()
+	v.EmbedAssets(fstest.MapFS{
+		"assets/app.css":      {Data: []byte("body{color:red}")},
+		"assets/img/logo.png": {Data: []byte("\x89PNG\r\n\x1a\n")},
+		"main.go":             {Data: []byte("package main")},
+	})
+	css := v.Asset("app.css")
+	var ctx *Context
+	v.Page("/", func(c *Context) {
+		ctx = c
+		c.View(func() h.H { return h.Link(h.Rel("stylesheet"), h.Href(c.Asset("app.css"))) })
+	})
+	w := httptest.NewRecorder()
+	v.mux.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.Contains(t, w.Body.String(), `<link rel="stylesheet" href="`+css+`">`)
+	assert.Equal(t, css, ctx.Asset("/app.css"))
+
+	// assets resolve per app
+	assert.Equal(t, "/_assets/app.css", New().Asset("app.css"))
+	assert.Regexp(t, `^/_assets/app\.[0-9a-f]{16}\.css$`, css)
+	assert.Regexp(t, `^/_assets/img/logo\.[0-9a-f]{16}\.png$`, v.Asset("img/logo.png"))
+
+	req := httptest.NewRequest("GET", css, nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w = httptest.NewRecorder()
+	v.mux.ServeHTTP(w, req)
+	assert.Equal(t, "public, max-age=31536000, immutable", w.Header().Get("Cache-Control"))
+	assert.Equal(t, "text/css; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+
+	// images are not compressed again
+	req = httptest.NewRequest("GET", v.Asset("img/logo.png"), nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w = httptest.NewRecorder()
+	v.mux.ServeHTTP(w, req)
+	assert.Equal(t, "", w.Header().Get("Content-Encoding"))
+	assert.Equal(t, "\x89PNG\r\n\x1a\n", w.Body.String())
+
+	// unhashed names are revalidated
+	w = httptest.NewRecorder()
+	v.mux.ServeHTTP(w, httptest.NewRequest("GET", "/_assets/app.css", nil))
+	assert.Equal(t, "no-cache", w.Header().Get("Cache-Control"))
+	assert.Equal(t, "body{color:red}", w.Body.String())
+
+	w = httptest.NewRecorder()
+	v.mux.ServeHTTP(w, httptest.NewRequest("GET", "/_assets/main.go", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestEmbedAssetsDevMode(t *testing.T) {
+	t.Chdir(t.TempDir())
+	assert.NoError(t, os.Mkdir("assets", 0o755))
+	assert.NoError(t, os.WriteFile("assets/app.css", []byte("body{color:red}"), 0o644))
+	prevInterval := assetPollInterval
+	assetPollInterval = 10 * time.Millisecond
+	defer func() { assetPollInterval = prevInterval }()
+
+	var ctx *Context
+	v := New()
+	defer v.Shutdown(context.Background())
+	v.Config(Options{DevMode: true, LogLvl: LogLevelError})
+	v.EmbedAssets(fstest.MapFS{"assets/app.css": {Data: []byte("stale")}})
+	v.Page("/{$}", func(c *Context) {
+		ctx = c
+		c.View(func() h.H { return h.Div() })
+	})
+	v.mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	oldURL := v.Asset("app.css")
+	w := httptest.NewRecorder()
+	v.mux.ServeHTTP(w, httptest.NewRequest("GET", oldURL, nil))
+	assert.Equal(t, "body{color:red}", w.Body.String(), "DevMode reads the assets directory")
+
+	assert.NoError(t, os.WriteFile("assets/app.css", []byte("body{color:blue}"), 0o644))
+	select {
+	case p := <-ctx.patchChan:
+		newURL := v.Asset("app.css")
+		assert.NotEqual(t, oldURL, newURL)
+		assert.Equal(t, patchType(patchTypeScript), p.typ)
+		assert.Contains(t, p.content, oldURL)
+		assert.Contains(t, p.content, newURL)
+	case <-time.After(5 * time.Second):
+		t.Fatal("stylesheet was not swapped")
+	}
+}
+
+func TestDatastarBundle(t *testing.T) {
+	assert.True(t, bytes.HasPrefix(datastarJS, []byte("// Datastar v"+DatastarVersion+"\n")))
+
+	v := New()
+	v.Config(Options{Datastar: DatastarBundle{
+		Script:     []byte("custom"),
+		URL:        "https://cdn.example.com/datastar.js",
+		Integrity:  "sha384-abc",
+		Extensions: []string{"import { attribute } from 'datastar'"},
+	}})
+	v.Page("/", func(c *Context) {
+		c.View(func() h.H { return h.Div() })
+	})
+
+	w := httptest.NewRecorder()
+	v.mux.ServeHTTP(w, httptest.NewRequest("GET", "/_datastar.js", nil))
+	assert.Equal(t, "custom", w.Body.String())
+
+	w = httptest.NewRecorder()
+	v.mux.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	body := w.Body.String()
+	assert.Contains(t, body, `<script type="importmap">{"imports":{"datastar":"https://cdn.example.com/datastar.js"}}</script>`)
+	assert.Contains(t, body, `<script type="module" src="https://cdn.example.com/datastar.js" integrity="sha384-abc" crossorigin="anonymous"></script>`)
+	assert.Contains(t, body, `<script type="module">import { attribute } from 'datastar'</script>`)
+}
+
+func TestIslands(t *testing.T) {
+	var ctx *Context
+	var title, slug *signal
+	var comp *compute
+	v := New()
+	v.Config(Options{SSE: SSEOptions{PatchBuffer: 4}})
+	v.Islands([]byte("\x00asm"), []byte("class Go {}"))
+	v.Page("/{$}", func(c *Context) {
+		ctx = c
+		title, slug = c.Signal(""), c.Signal("")
+		comp = c.Compute("slugify", func(args ...any) any {
+			return strings.ReplaceAll(strings.ToLower(fmt.Sprint(args[0])), " ", "-")
+		}, slug, title)
+		c.View(func() h.H { return h.Input(title.Bind(), comp.OnInput()) })
+	})
+	w := httptest.NewRecorder()
+	v.mux.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	body := w.Body.String()
+	assert.Contains(t, body, `<script type="module" src="`+v.islandsAsset.url()+`"></script>`)
+	assert.Contains(t, body, `data-on:input="window.viaIslands?.has(&#39;slugify&#39;) ? ($`+slug.ID()+` = viaIslands.call(&#39;slugify&#39;, $`+title.ID()+`)) : @get(&#39;/_action/`+comp.action.id+`&#39;)"`)
+
+	w = httptest.NewRecorder()
+	v.mux.ServeHTTP(w, httptest.NewRequest("GET", v.islandsAsset.url(), nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "window.viaIslands")
+	assert.Contains(t, w.Body.String(), "/_assets/islands.")
+	for _, a := range v.assets {
+		if strings.HasSuffix(a.hashedName, ".wasm") {
+			w = httptest.NewRecorder()
+			v.mux.ServeHTTP(w, httptest.NewRequest("GET", a.url(), nil))
+			assert.Equal(t, "application/wasm", w.Header().Get("Content-Type"))
+		}
+	}
+
+	// without WASM the func runs as a server action
+	ctx.prepareSignalsForPatch()
+	req := httptest.NewRequest("GET", "/_action/"+comp.action.id+"?datastar="+url.QueryEscape(`{"via-ctx":"`+ctx.id+`","`+title.ID()+`":"Hello World"}`), nil)
+	v.mux.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, "hello-world", slug.String())
+	p := <-ctx.patchChan
+	assert.Equal(t, `{"`+slug.ID()+`":"hello-world"}`, p.content)
+
+	assert.Panics(t, func() { ctx.Compute("bad name", nil, slug) })
+}
+
+func TestIslandsReplacedWhileServing(t *testing.T) {
+	v := New()
+	v.Islands([]byte("\x00asm"), []byte("class Go {}"))
+	v.Page("/{$}", func(c *Context) {
+		c.View(func() h.H { return h.Div() })
+	})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := range 50 {
+			v.Islands([]byte("\x00asm"+strconv.Itoa(i)), []byte("class Go {}"))
+		}
+	}()
+	for range 50 {
+		w := httptest.NewRecorder()
+		v.mux.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		assert.Contains(t, w.Body.String(), "/_assets/islands.")
+		w = httptest.NewRecorder()
+		v.mux.ServeHTTP(w, httptest.NewRequest("GET", datastarPath, nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+	<-done
+}
+
+func TestAppendToHTMLAttrs(t *testing.T) {
+	v := New()
+	v.AppendToHTMLAttrs(h.Attr("data-theme", "dark"), nil)
+	v.Page("/", func(c *Context) {
+		c.View(func() h.H { return h.Div() })
+	})
+
+	w := httptest.NewRecorder()
+	v.mux.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.Contains(t, w.Body.String(), `<html data-theme="dark">`)
+}
+
 func TestSignal(t *testing.T) {
 	var sig *signal
 	v := New()
@@ -52,6 +317,72 @@ func TestSignal(t *testing.T) {
 	assert.Equal(t, "test", sig.String())
 }
 
+func TestSignalTypes(t *testing.T) {
+	type level int
+	type point struct {
+		X int `json:"x"`
+		Y int `json:"y"`
+	}
+	var ctx *Context
+	var count, open, ratio, name, lvl, tags, ids, pos *signal
+	v := New()
+	v.Page("/{$}", func(c *Context) {
+		ctx = c
+		count = c.Signal(3)
+		open = c.Signal(true)
+		ratio = c.Signal(0.5)
+		name = c.Signal("7")
+		lvl = c.Signal(level(2))
+		tags = c.Signal("")
+		tags.SetValue([]string{"a"})
+		ids = c.Signal([]int{1, 2})
+		pos = c.Signal(point{1, 2})
+		c.View(func() h.H { return h.Div() })
+	})
+	v.mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	var sent map[string]any
+	j, err := json.Marshal(ctx.prepareSignalsForPatch())
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal(j, &sent))
+	assert.Equal(t, map[string]any{
+		count.ID(): float64(3), open.ID(): true, ratio.ID(): 0.5,
+		name.ID(): "7", lvl.ID(): float64(2), tags.ID(): []any{"a"},
+		ids.ID(): []any{float64(1), float64(2)}, pos.ID(): map[string]any{"x": float64(1), "y": float64(2)},
+	}, sent)
+
+	// values decoded from the browser keep the type of the signal
+	ctx.injectSignals(map[string]any{
+		count.ID(): float64(4), open.ID(): false, ratio.ID(): float64(1),
+		name.ID(): "8", lvl.ID(): "3", tags.ID(): []any{"b"},
+		ids.ID(): []any{float64(3)}, pos.ID(): map[string]any{"x": float64(5), "y": float64(6)},
+	})
+	assert.Equal(t, 4, count.val)
+	assert.Equal(t, false, open.val)
+	assert.Equal(t, 1.0, ratio.val)
+	assert.Equal(t, "8", name.val)
+	assert.Equal(t, level(3), lvl.val)
+	assert.Equal(t, []string{"b"}, tags.Strings())
+	assert.Equal(t, []int{3}, ids.val)
+	assert.Equal(t, point{5, 6}, pos.val)
+	for _, sig := range []*signal{count, open, ratio, name, lvl, tags, ids, pos} {
+		assert.NoError(t, sig.Err())
+	}
+
+	// values that do not convert keep the previous value and set an error
+	ctx.injectSignals(map[string]any{count.ID(): 4.5, lvl.ID(): "", ids.ID(): "x"})
+	assert.Equal(t, 4, count.val)
+	assert.EqualError(t, count.Err(), "signal value 4.5 is not a valid int")
+	assert.Equal(t, level(3), lvl.val)
+	assert.Error(t, lvl.Err())
+	assert.Equal(t, []int{3}, ids.val)
+	assert.Error(t, ids.Err())
+	ctx.injectSignals(map[string]any{count.ID(): float64(5), ratio.ID(): float64(1e6)})
+	assert.Equal(t, 5, count.val)
+	assert.NoError(t, count.Err())
+	assert.Equal(t, "1000000", ratio.String())
+}
+
 func TestAction(t *testing.T) {
 	var trigger *actionTrigger
 	var sig *signal
@@ -64,6 +395,7 @@ func TestAction(t *testing.T) {
 				h.Button(trigger.OnClick()),
 				h.Input(trigger.OnChange()),
 				h.Input(trigger.OnKeyDown("Enter")),
+				h.Div(trigger.OnIntersect(0.5)),
 				h.Button(trigger.OnClick(WithSignal(sig, "test"))),
 				h.Button(trigger.OnClick(WithSignalInt(sig, 42))),
 			)
@@ -77,6 +409,7 @@ func TestAction(t *testing.T) {
 	assert.Contains(t, body, "data-on:click")
 	assert.Contains(t, body, "data-on:change__debounce.200ms")
 	assert.Contains(t, body, "data-on:keydown")
+	assert.Contains(t, body, "data-on-intersect__half")
 	assert.Contains(t, body, "/_action/")
 }
 
@@ -86,9 +419,2470 @@ func TestConfig(t *testing.T) {
 	assert.Equal(t, "Test", v.cfg.DocumentTitle)
 }
 
+func TestConfigDevModeEnv(t *testing.T) {
+	t.Setenv(devModeEnv, "")
+	assert.False(t, New().DevMode())
+
+	t.Setenv(devModeEnv, "1")
+	v := New()
+	assert.True(t, v.DevMode())
+	v.Config(Options{DocumentTitle: "Test"})
+	assert.True(t, v.DevMode(), "options without DevMode keep it")
+	v.Config(Options{NoDevMode: true})
+	assert.False(t, v.DevMode())
+	v.Config(Options{DevMode: true})
+	assert.True(t, v.DevMode())
+}
+
 func TestPage_PanicsOnNoView(t *testing.T) {
 	assert.Panics(t, func() {
 		v := New()
 		v.Page("/", func(c *Context) {})
 	})
 }
+
+func TestConfigViewTransitions(t *testing.T) {
+	var ctx *Context
+	v := New()
+	v.Page("/", func(c *Context) {
+		ctx = c
+		c.View(func() h.H { return h.Div() })
+	})
+	v.mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	writePatch := func() string {
+		w := httptest.NewRecorder()
+		sse := datastar.NewSSE(w, httptest.NewRequest("GET", "/_sse", nil))
+		assert.NoError(t, v.writePatch(sse, ctx, patch{typ: patchTypeElements, content: `<div id="a"></div>`}))
+		return w.Body.String()
+	}
+	assert.NotContains(t, writePatch(), "useViewTransition")
+
+	v.Config(Options{ViewTransitions: true})
+	v.Config(Options{DocumentTitle: "Test"})
+	assert.Contains(t, writePatch(), "data: useViewTransition true")
+}
+
+func TestDragAndDropTriggers(t *testing.T) {
+	v := New()
+	v.Page("/", func(c *Context) {
+		trigger := c.Action(func() {})
+		source := c.Signal("")
+		target := c.Signal("")
+		c.View(func() h.H {
+			return h.Div(
+				h.Li(h.ID("a"), trigger.OnDragStart()),
+				h.Li(h.ID("b"), trigger.OnDragOver(), trigger.OnDrop(source, target)),
+			)
+		})
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	v.mux.ServeHTTP(w, req)
+	body := w.Body.String()
+	assert.Contains(t, body, "data-on:dragstart")
+	assert.Contains(t, body, "data-on:dragover")
+	assert.Contains(t, body, "data-on:drop__prevent")
+}
+
+func TestGestureTriggers(t *testing.T) {
+	v := New()
+	v.Page("/", func(c *Context) {
+		trigger := c.Action(func() {})
+		scale := c.Signal(1.0)
+		c.View(func() h.H {
+			return h.Div(
+				h.Div(h.ID("card"), trigger.OnSwipe(SwipeLeft), trigger.OnSwipe(SwipeRight), trigger.OnLongPress()),
+				h.Img(trigger.OnPinch(scale)),
+			)
+		})
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	v.mux.ServeHTTP(w, req)
+	body := w.Body.String()
+	assert.Contains(t, body, `<div id="card" via-gesture data-on:via-swipe-left="@get(`)
+	assert.Contains(t, body, "data-on:via-swipe-right")
+	assert.Contains(t, body, "data-on:via-longpress")
+	assert.Contains(t, body, "data-on:via-pinch=\"$")
+	assert.Contains(t, body, "evt.detail.scale")
+	assert.Contains(t, body, "[via-gesture]{touch-action:none")
+	assert.Contains(t, body, "&#39;via-&#39; + name")
+}
+
+func TestGestureHead(t *testing.T) {
+	var ctx *Context
+	var dx, dy *signal
+	showCard := false
+	v := New()
+	v.Config(Options{SSE: SSEOptions{PatchBuffer: 4}})
+	v.Page("/", func(c *Context) {
+		ctx = c
+		fling := c.Action(func() {})
+		dx, dy = c.Signal(0), c.Signal(0)
+		c.View(func() h.H {
+			return h.Div(h.If(showCard, h.Div(h.ID("card"), fling.OnSwipeDistance(SwipeLeft, dx, dy))))
+		})
+	})
+
+	w := httptest.NewRecorder()
+	v.mux.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.NotContains(t, w.Body.String(), "[via-gesture]", "pages without gesture triggers")
+
+	// a trigger rendered after the page load patches the head in, once
+	showCard = true
+	ctx.Sync()
+	ctx.Sync()
+	var heads []patch
+	for len(ctx.patchChan) > 0 {
+		if p := <-ctx.patchChan; p.selector == "head" {
+			heads = append(heads, p)
+		}
+	}
+	if assert.Len(t, heads, 1) {
+		assert.Equal(t, datastar.ElementPatchModeAppend, heads[0].mode)
+		assert.Contains(t, heads[0].content, "[via-gesture]{touch-action:none")
+	}
+
+	w = httptest.NewRecorder()
+	v.mux.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	body := w.Body.String()
+	assert.Contains(t, body, "[via-gesture]", "pages with gesture triggers")
+	assert.Contains(t, body, `data-on:via-swipe-left="$`+dx.ID()+`=Math.round(evt.detail.dx);$`+dy.ID()+`=Math.round(evt.detail.dy);@get(`)
+}
+
+func TestUpload(t *testing.T) {
+	var received []UploadedFile
+	var uploadURL string
+	v := New()
+	v.Page("/", func(c *Context) {
+		uploadURL = c.Upload(func(files []UploadedFile) {
+			received = files
+		})
+		c.View(func() h.H { return h.Div() })
+	})
+
+	w := httptest.NewRecorder()
+	v.mux.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	body := &bytes.Buffer{}
+	mw := multipart.NewWriter(body)
+	fw, _ := mw.CreateFormFile("file", "hello.txt")
+	_, _ = fw.Write([]byte("hello"))
+	_ = mw.Close()
+
+	req := httptest.NewRequest("POST", uploadURL, body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	w = httptest.NewRecorder()
+	v.mux.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	if assert.Len(t, received, 1) {
+		assert.Equal(t, "hello.txt", received[0].Name)
+		assert.Equal(t, []byte("hello"), received[0].Data)
+	}
+}
+
+func TestUploadTooLarge(t *testing.T) {
+	calls := 0
+	var uploadURL string
+	v := New()
+	v.Config(Options{Upload: UploadOptions{MaxRequestSize: 4 << 10, MaxFileSize: 1 << 10}})
+	v.Page("/", func(c *Context) {
+		uploadURL = c.Upload(func([]UploadedFile) { calls++ })
+		c.View(func() h.H { return h.Div() })
+	})
+	v.mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	tests := []struct {
+		name  string
+		sizes []int
+		want  int
+	}{
+		{"within limits", []int{1 << 10, 1 << 10}, http.StatusOK},
+		{"file too large", []int{1<<10 + 1}, http.StatusRequestEntityTooLarge},
+		{"request too large", []int{1 << 10, 1 << 10, 1 << 10, 1 << 10, 1 << 10}, http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := &bytes.Buffer{}
+			mw := multipart.NewWriter(body)
+			for i, size := range tt.sizes {
+				fw, _ := mw.CreateFormFile("file", fmt.Sprintf("f%d.bin", i))
+				_, _ = fw.Write(bytes.Repeat([]byte("x"), size))
+			}
+			_ = mw.Close()
+			req := httptest.NewRequest("POST", uploadURL, body)
+			req.Header.Set("Content-Type", mw.FormDataContentType())
+			w := httptest.NewRecorder()
+			v.mux.ServeHTTP(w, req)
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+	assert.Equal(t, 1, calls)
+}
+
+func TestUploadHooks(t *testing.T) {
+	calls := 0
+	var uploadURL string
+	var after []string
+	v := New()
+	v.BeforeAction(func(c *Context, id string) bool { return c.Cookie("session") == "ok" })
+	v.AfterAction(func(c *Context, id string, _ time.Duration, err error) { after = append(after, id) })
+	v.Page("/", func(c *Context) {
+		uploadURL = c.Upload(func([]UploadedFile) { calls++ })
+		c.View(func() h.H { return h.Div() })
+	})
+	post := func(cookie string) int {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.AddCookie(&http.Cookie{Name: "session", Value: cookie})
+		v.mux.ServeHTTP(httptest.NewRecorder(), req)
+		body := &bytes.Buffer{}
+		mw := multipart.NewWriter(body)
+		fw, _ := mw.CreateFormFile("file", "a.txt")
+		_, _ = fw.Write([]byte("a"))
+		_ = mw.Close()
+		req = httptest.NewRequest("POST", uploadURL, body)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		w := httptest.NewRecorder()
+		v.mux.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusForbidden, post("bad"))
+	assert.Equal(t, 0, calls)
+	assert.Empty(t, after)
+	assert.Equal(t, http.StatusOK, post("ok"))
+	assert.Equal(t, 1, calls)
+	assert.Len(t, after, 1)
+}
+
+func TestPageCompression(t *testing.T) {
+	v := New()
+	v.Page("/", func(c *Context) {
+		c.View(func() h.H { return h.Div(h.Text("Hello Via!")) })
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	v.mux.ServeHTTP(w, req)
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	gr, err := gzip.NewReader(w.Body)
+	if assert.NoError(t, err) {
+		body, _ := io.ReadAll(gr)
+		assert.Contains(t, string(body), "Hello Via!")
+	}
+
+	v.Config(Options{Compression: CompressionOff})
+	w = httptest.NewRecorder()
+	v.mux.ServeHTTP(w, req)
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.Contains(t, w.Body.String(), "Hello Via!")
+}
+
+func TestShutdown(t *testing.T) {
+	v := New()
+	v.Config(Options{HTTPServer: &http.Server{Addr: "127.0.0.1:0"}})
+	done := make(chan struct{})
+	go func() {
+		v.Start()
+		close(done)
+	}()
+	assert.Eventually(t, func() bool {
+		v.serverMu.Lock()
+		defer v.serverMu.Unlock()
+		return v.server != nil
+	}, time.Second, 10*time.Millisecond)
+	assert.NoError(t, v.Shutdown(context.Background()))
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Start did not return after Shutdown")
+	}
+}
+
+func TestServeListener(t *testing.T) {
+	v := New()
+	v.Page("/", func(c *Context) {
+		c.View(func() h.H { return h.Div(h.Text("Hello Via!")) })
+	})
+	l, err := net.Listen("unix", filepath.Join(t.TempDir(), "via.sock"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	done := make(chan error)
+	go func() { done <- v.ServeListener(l) }()
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return net.Dial("unix", l.Addr().String())
+		},
+	}}
+	res, err := client.Get("http://via/")
+	if assert.NoError(t, err) {
+		body, _ := io.ReadAll(res.Body)
+		res.Body.Close()
+		assert.Contains(t, string(body), "Hello Via!")
+	}
+
+	assert.NoError(t, v.Shutdown(context.Background()))
+	assert.NoError(t, <-done)
+}
+
+func TestServeAddress(t *testing.T) {
+	v := New()
+	v.Page("/", func(c *Context) {
+		c.View(func() h.H { return h.Div(h.Text("Hello Via!")) })
+	})
+	main := v.httpServer()
+
+	free, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		return
+	}
+	addr := free.Addr().String()
+	free.Close()
+	if assert.NoError(t, v.serveAddress(main, Address{Addr: addr})) {
+		res, err := http.Get("http://" + addr + "/")
+		if assert.NoError(t, err) {
+			body, _ := io.ReadAll(res.Body)
+			res.Body.Close()
+			assert.Contains(t, string(body), "Hello Via!")
+		}
+	}
+
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer busy.Close()
+	assert.Error(t, v.serveAddress(main, Address{Addr: busy.Addr().String()}))
+
+	dir := t.TempDir()
+	assert.Error(t, v.serveAddress(main, Address{Addr: "127.0.0.1:0",
+		CertFile: filepath.Join(dir, "cert.pem"), KeyFile: filepath.Join(dir, "key.pem")}))
+
+	assert.NoError(t, v.Shutdown(context.Background()))
+	_, err = http.Get("http://" + addr + "/")
+	assert.Error(t, err)
+}
+
+func TestAutoTLSConfig(t *testing.T) {
+	m := &autocert.Manager{Prompt: autocert.AcceptTOS, HostPolicy: autocert.HostWhitelist("example.com")}
+
+	cfg := autoTLSConfig(m, nil)
+	assert.Contains(t, cfg.NextProtos, acme.ALPNProto)
+	assert.NotNil(t, cfg.GetCertificate)
+
+	internal := &tls.Certificate{}
+	user := &tls.Config{
+		MinVersion: tls.VersionTLS13,
+		NextProtos: []string{"http/1.1"},
+		GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			if hello.ServerName == "internal.example" {
+				return internal, nil
+			}
+			return nil, nil
+		},
+	}
+	cfg = autoTLSConfig(m, user)
+	assert.Equal(t, uint16(tls.VersionTLS13), cfg.MinVersion)
+	assert.Equal(t, []string{"http/1.1", acme.ALPNProto}, cfg.NextProtos)
+	assert.Equal(t, []string{"http/1.1"}, user.NextProtos)
+	cert, err := cfg.GetCertificate(&tls.ClientHelloInfo{ServerName: "internal.example"})
+	assert.NoError(t, err)
+	assert.Same(t, internal, cert)
+	_, err = cfg.GetCertificate(&tls.ClientHelloInfo{ServerName: "other.example"})
+	assert.ErrorContains(t, err, "not configured")
+}
+
+func TestDesktop(t *testing.T) {
+	v := New()
+	v.Page("/{$}", func(c *Context) {
+		c.View(func() h.H { return h.Div(h.Text("Hello Via!")) })
+	})
+	var opened desktop.Options
+	err := Desktop(v, desktop.Options{Title: "Notes", Window: func(url string, o desktop.Options) error {
+		opened = o
+		assert.True(t, strings.HasPrefix(url, "http://127.0.0.1:"))
+		jar, _ := cookiejar.New(nil)
+		client := &http.Client{Jar: jar}
+		res, err := client.Get(url)
+		if assert.NoError(t, err) {
+			body, _ := io.ReadAll(res.Body)
+			res.Body.Close()
+			assert.Contains(t, string(body), "Hello Via!")
+			assert.Contains(t, string(body), "<title>Notes</title>")
+			assert.NotContains(t, res.Request.URL.String(), desktopTokenParam)
+		}
+		root := strings.TrimSuffix(url, "?"+desktopTokenParam+"="+v.desktopToken)
+
+		// requests without the token are rejected
+		res, err = http.Get(root)
+		if assert.NoError(t, err) {
+			res.Body.Close()
+			assert.Equal(t, http.StatusForbidden, res.StatusCode)
+		}
+		res, err = http.Get(root + "?" + desktopTokenParam + "=wrong")
+		if assert.NoError(t, err) {
+			res.Body.Close()
+			assert.Equal(t, http.StatusForbidden, res.StatusCode)
+		}
+
+		// DNS rebinding: the token cookie is sent but the host is not a loopback address
+		req, _ := http.NewRequest("GET", root, nil)
+		req.Host = "evil.example:80"
+		res, err = client.Do(req)
+		if assert.NoError(t, err) {
+			res.Body.Close()
+			assert.Equal(t, http.StatusForbidden, res.StatusCode)
+		}
+
+		res, err = client.Get(root)
+		if assert.NoError(t, err) {
+			res.Body.Close()
+			assert.Equal(t, http.StatusOK, res.StatusCode)
+		}
+		return nil
+	}})
+	assert.NoError(t, err)
+	assert.Equal(t, "Notes", opened.Title)
+	assert.Equal(t, desktop.DefaultSize, opened.Size)
+
+	// the app shuts down when the window fails
+	v = New()
+	err = Desktop(v, desktop.Options{Window: func(string, desktop.Options) error { return desktop.ErrNoBrowser }})
+	assert.ErrorIs(t, err, desktop.ErrNoBrowser)
+	select {
+	case <-v.shutdownChan:
+	default:
+		t.Error("app not shut down")
+	}
+}
+
+func TestClientIPAndBaseURL(t *testing.T) {
+	v := New()
+	v.Config(Options{TrustedProxies: []string{"10.0.0.0/8", "127.0.0.1"}})
+
+	testcases := []struct {
+		desc        string
+		remoteAddr  string
+		xff         string
+		proto       string
+		expectedIP  string
+		expectedURL string
+	}{
+		{"direct", "203.0.113.7:1234", "198.51.100.1", "https", "203.0.113.7", "http://example.com"},
+		{"trusted proxy", "127.0.0.1:1234", "198.51.100.1", "https", "198.51.100.1", "https://example.com"},
+		{"proxy chain", "10.0.0.2:1234", "198.51.100.1, 203.0.113.9, 10.0.0.1", "", "203.0.113.9", "http://example.com"},
+		{"only proxies", "10.0.0.2:1234", "10.0.0.1", "", "10.0.0.1", "http://example.com"},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.desc, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "http://example.com/", nil)
+			req.RemoteAddr = testcase.remoteAddr
+			req.Header.Set("X-Forwarded-For", testcase.xff)
+			req.Header.Set("X-Forwarded-Proto", testcase.proto)
+			assert.Equal(t, testcase.expectedIP, v.clientIP(req))
+			assert.Equal(t, testcase.expectedURL, v.baseURL(req))
+		})
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	var ctx *Context
+	v := New()
+	v.Page("/", func(c *Context) {
+		ctx = c
+		c.View(func() h.H { return h.Div() })
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Request-ID", "abc-123")
+	w := httptest.NewRecorder()
+	v.mux.ServeHTTP(w, req)
+	assert.Equal(t, "abc-123", w.Header().Get("X-Request-ID"))
+	assert.Equal(t, "abc-123", ctx.RequestID())
+
+	req = httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Request-ID", "bad id\n")
+	w = httptest.NewRecorder()
+	v.mux.ServeHTTP(w, req)
+	assert.Len(t, w.Header().Get("X-Request-ID"), 32)
+	assert.Equal(t, w.Header().Get("X-Request-ID"), ctx.RequestID())
+
+	// actions see their own request ID, even if other requests of the context come in
+	var seen []string
+	started, proceed := make(chan struct{}), make(chan struct{})
+	act := ctx.Action(func() {
+		close(started)
+		<-proceed
+		seen = append(seen, ctx.RequestID())
+	})
+	done := make(chan struct{})
+	go func() {
+		req := httptest.NewRequest("GET", "/_action/"+act.id+"?datastar="+url.QueryEscape(`{"via-ctx":"`+ctx.id+`"}`), nil)
+		req.Header.Set("X-Request-ID", "action-1")
+		v.mux.ServeHTTP(httptest.NewRecorder(), req)
+		close(done)
+	}()
+	<-started
+	ctx.setRequestID("sse-1")
+	close(proceed)
+	<-done
+	assert.Equal(t, []string{"action-1"}, seen)
+	assert.Equal(t, "sse-1", ctx.RequestID())
+}
+
+func TestClientBinding(t *testing.T) {
+	var ctx *Context
+	v := New()
+	v.Config(Options{ClientBinding: BindClientIP | BindUserAgent})
+	v.Page("/", func(c *Context) {
+		ctx = c
+		c.View(func() h.H { return h.Div() })
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "192.0.2.10:1234"
+	req.Header.Set("User-Agent", "browser")
+	v.mux.ServeHTTP(httptest.NewRecorder(), req)
+	actionID := ctx.Action(func() {}).id
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		userAgent  string
+		status     int
+	}{
+		{"same client", "192.0.2.10:1234", "browser", http.StatusOK},
+		{"same network", "192.0.2.99:4321", "browser", http.StatusOK},
+		{"other network", "198.51.100.10:1234", "browser", http.StatusForbidden},
+		{"other user agent", "192.0.2.10:1234", "curl", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/_action/"+actionID+"?datastar="+url.QueryEscape(`{"via-ctx":"`+ctx.id+`"}`), nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set("User-Agent", tt.userAgent)
+			w := httptest.NewRecorder()
+			v.mux.ServeHTTP(w, req)
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestCSP(t *testing.T) {
+	var ctx *Context
+	v := New()
+	v.AppendToHead(NonceScript(h.Src("https://cdn.example.com/lib.js")))
+	v.AppendToFoot(NonceScript(h.Raw("init()")))
+	v.Page("/", func(c *Context) {
+		ctx = c
+		c.View(func() h.H { return h.Div() })
+	})
+
+	w := httptest.NewRecorder()
+	v.mux.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.Contains(t, w.Body.String(), `<script src="https://cdn.example.com/lib.js"></script>`, "without CSP")
+	assert.Contains(t, w.Body.String(), `<script>init()</script>`, "without CSP")
+
+	v.Config(Options{CSP: NewCSP().Allow("img-src", "https:").Allow("worker-src", "'self'")})
+	w = httptest.NewRecorder()
+	v.mux.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	csp := w.Header().Get("Content-Security-Policy")
+	assert.NotEmpty(t, ctx.Nonce())
+	assert.Contains(t, csp, "script-src 'self' 'unsafe-eval' 'nonce-"+ctx.Nonce()+"'")
+	assert.Contains(t, csp, "img-src 'self' data: https:")
+	assert.Contains(t, csp, "; worker-src 'self'")
+	assert.Contains(t, w.Body.String(), `<script type="module" src="`+v.datastarSrc()+`" nonce="`+ctx.Nonce()+`">`)
+	assert.Contains(t, w.Body.String(), `<script nonce="`+ctx.Nonce()+`" src="https://cdn.example.com/lib.js"></script>`)
+	assert.Contains(t, w.Body.String(), `<script nonce="`+ctx.Nonce()+`">init()</script>`)
+
+	v.Config(Options{CSP: NewCSP().ReportOnly()})
+	w = httptest.NewRecorder()
+	v.mux.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.Empty(t, w.Header().Get("Content-Security-Policy"))
+	assert.Contains(t, w.Header().Get("Content-Security-Policy-Report-Only"), "'nonce-"+ctx.Nonce()+"'")
+}
+
+func TestSecurityHeaders(t *testing.T) {
+	v := New()
+	v.Config(Options{
+		TrustedProxies:       []string{"192.0.2.1"},
+		SecurityHeaders:      SecurityHeaders{ReferrerPolicy: "no-referrer"},
+		RouteSecurityHeaders: map[string]SecurityHeaders{"/embed/": {FrameOptions: OmitHeader}},
+	})
+	v.Page("/", func(c *Context) { c.View(func() h.H { return h.Div() }) })
+	v.Page("/embed/widget", func(c *Context) { c.View(func() h.H { return h.Div() }) })
+
+	w := httptest.NewRecorder()
+	v.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+	assert.Equal(t, "no-referrer", w.Header().Get("Referrer-Policy"))
+	assert.Equal(t, "SAMEORIGIN", w.Header().Get("X-Frame-Options"))
+	assert.Empty(t, w.Header().Get("Strict-Transport-Security"))
+
+	req := httptest.NewRequest("GET", "/embed/widget", nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	req.Header.Set("X-Forwarded-Proto", "https")
+	w = httptest.NewRecorder()
+	v.ServeHTTP(w, req)
+	assert.Empty(t, w.Header().Get("X-Frame-Options"))
+	assert.Equal(t, "no-referrer", w.Header().Get("Referrer-Policy"))
+	assert.Equal(t, "max-age=63072000; includeSubDomains", w.Header().Get("Strict-Transport-Security"))
+}
+
+func TestCORS(t *testing.T) {
+	v := New()
+	v.Config(Options{CORS: &CORS{AllowedOrigins: []string{"https://app.example.com"}, MaxAge: time.Hour}})
+	v.API("POST /api/items", func(c *APIContext) (any, error) {
+		c.Status(http.StatusCreated)
+		return nil, nil
+	})
+	v.HandleFunc("POST /hooks/deploy", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		origin      string
+		status      int
+		allowOrigin string
+	}{
+		{"preflight", "OPTIONS", "/api/items", "https://app.example.com", http.StatusNoContent, "https://app.example.com"},
+		{"request", "POST", "/api/items", "https://app.example.com", http.StatusCreated, "https://app.example.com"},
+		{"preflight from other origin", "OPTIONS", "/api/items", "https://evil.example.com", http.StatusForbidden, ""},
+		{"request from other origin", "POST", "/api/items", "https://evil.example.com", http.StatusCreated, ""},
+		{"preflight to sse endpoint", "OPTIONS", "/_sse", "https://app.example.com", http.StatusMethodNotAllowed, ""},
+		{"preflight to HandleFunc route", "OPTIONS", "/hooks/deploy", "https://app.example.com", http.StatusMethodNotAllowed, ""},
+		{"request to HandleFunc route", "POST", "/hooks/deploy", "https://app.example.com", http.StatusAccepted, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("Origin", tt.origin)
+			if tt.method == "OPTIONS" {
+				req.Header.Set("Access-Control-Request-Method", "GET")
+				if tt.path != "/_sse" {
+					req.Header.Set("Access-Control-Request-Method", "POST")
+				}
+			}
+			w := httptest.NewRecorder()
+			v.ServeHTTP(w, req)
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, tt.allowOrigin, w.Header().Get("Access-Control-Allow-Origin"))
+		})
+	}
+
+	v.Config(Options{CORS: &CORS{AllowedOrigins: []string{"*"}, FrameworkEndpoints: true}})
+	req := httptest.NewRequest("OPTIONS", "/_sse", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", "GET")
+	w := httptest.NewRecorder()
+	v.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Contains(t, w.Header().Get("Access-Control-Allow-Headers"), "Datastar-Request")
+}
+
+func TestAPI(t *testing.T) {
+	var ctx *Context
+	v := New()
+	v.Page("/", func(c *Context) {
+		ctx = c
+		c.View(func() h.H { return h.Div() })
+	})
+	v.API("GET /api/items/{id}", func(c *APIContext) (any, error) {
+		if c.PathParam("id") != "1" {
+			return nil, NewAPIError(http.StatusNotFound, "item %s not found", c.PathParam("id"))
+		}
+		return map[string]string{"id": "1"}, nil
+	})
+	v.API("POST /api/items", func(c *APIContext) (any, error) {
+		var item struct{ Name string }
+		if err := c.Decode(&item); err != nil {
+			return nil, err
+		}
+		c.Status(http.StatusCreated)
+		return item, nil
+	})
+	v.API("GET /api/page", func(c *APIContext) (any, error) {
+		pc, err := c.PageContext()
+		if err != nil {
+			return nil, err
+		}
+		return pc.route, nil
+	})
+	v.API("GET /api/fail", func(c *APIContext) (any, error) {
+		return nil, errors.New("db down")
+	})
+	v.mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+		resp   string
+	}{
+		{"found", "GET", "/api/items/1", "", http.StatusOK, `{"id":"1"}`},
+		{"not found", "GET", "/api/items/2", "", http.StatusNotFound, `{"error":"item 2 not found"}`},
+		{"create", "POST", "/api/items", `{"Name":"a"}`, http.StatusCreated, `{"Name":"a"}`},
+		{"invalid body", "POST", "/api/items", `{`, http.StatusBadRequest, `{"error":"invalid JSON body: unexpected EOF"}`},
+		{"page context", "GET", "/api/page?via-ctx=" + url.QueryEscape(ctx.id), "", http.StatusOK, `"/"`},
+		{"unknown page context", "GET", "/api/page?via-ctx=x", "", http.StatusNotFound, `{"error":"page context not found"}`},
+		{"internal error", "GET", "/api/fail", "", http.StatusInternalServerError, `{"error":"Internal Server Error"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			v.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)))
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, tt.resp, w.Body.String())
+		})
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	var applied []string
+	v := New()
+	v.Page("/builds", func(c *Context) { c.View(func() h.H { return h.Div() }) })
+	v.Page("/other", func(c *Context) { c.View(func() h.H { return h.Div() }) })
+	secret := []byte("secret")
+	v.Webhook("/hooks/ci", HMACSHA256Verifier("X-Hub-Signature-256", "sha256=", secret), func(payload []byte) LiveUpdate {
+		return LiveUpdate{Routes: []string{"/builds"}, Apply: func(c *Context) { applied = append(applied, c.route) }}
+	})
+	v.mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/builds", nil))
+	v.mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/other", nil))
+
+	payload := `{"status":"ok"}`
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(payload))
+
+	req := httptest.NewRequest("POST", "/hooks/ci", strings.NewReader(payload))
+	req.Header.Set("X-Hub-Signature-256", "sha256=deadbeef")
+	w := httptest.NewRecorder()
+	v.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Empty(t, applied)
+
+	req = httptest.NewRequest("POST", "/hooks/ci", strings.NewReader(payload))
+	req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	w = httptest.NewRecorder()
+	v.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, []string{"/builds"}, applied)
+}
+
+func TestNotifySession(t *testing.T) {
+	var ctx *Context
+	v := New()
+	v.Page("/", func(c *Context) {
+		ctx = c
+		c.View(func() h.H { return h.Div() })
+	})
+	v.mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	done := make(chan *Context)
+	go func() {
+		assert.NoError(t, v.NotifySession(ctx.id, func(c *Context) { done <- c }))
+	}()
+	assert.Equal(t, ctx, <-done)
+	assert.Error(t, v.NotifySession("unknown", func(c *Context) {}))
+}
+
+func TestNotifySessionDuringAction(t *testing.T) {
+	type ping struct{}
+	pings := NewTopic[ping]("pings")
+	var ctx *Context
+	var actionID string
+	var steps []string
+	started, release := make(chan struct{}), make(chan struct{})
+	v := New()
+	v.Page("/", func(c *Context) {
+		ctx = c
+		pings.Subscribe(c, func(ping) {
+			if c == ctx {
+				steps = append(steps, "event")
+			}
+		})
+		actionID = c.Action(func() {
+			close(started)
+			<-release
+			// delivered to this page after the action, not deadlocked
+			pings.Publish(v.Events(), ping{})
+			steps = append(steps, "action")
+		}).id
+		c.View(func() h.H { return h.Div() })
+	})
+	v.mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		req := httptest.NewRequest("GET", "/_action/"+actionID+"?datastar="+url.QueryEscape(`{"via-ctx":"`+ctx.id+`"}`), nil)
+		v.mux.ServeHTTP(httptest.NewRecorder(), req)
+	}()
+	<-started
+	assert.NoError(t, v.NotifySession(ctx.id, func(c *Context) { steps = append(steps, "notify") }))
+	close(release)
+	<-done
+	assert.Equal(t, []string{"action", "notify", "event"}, steps)
+
+	assert.NoError(t, v.NotifySession(ctx.id, func(c *Context) { steps = append(steps, "idle") }))
+	assert.Equal(t, "idle", steps[len(steps)-1])
+}
+
+func TestJobs(t *testing.T) {
+	var ctx *Context
+	v := New()
+	v.Page("/", func(c *Context) {
+		ctx = c
+		c.View(func() h.H { return h.Div() })
+	})
+	v.mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	release := make(chan struct{})
+	v.Jobs().Register("export", func(_ context.Context, job *Job) (any, error) {
+		job.SetProgress(0.5)
+		<-release
+		if job.Payload == "fail" {
+			return nil, errors.New("disk full")
+		}
+		return "export.csv", nil
+	})
+
+	_, err := v.Jobs().Enqueue("unknown", nil)
+	assert.Error(t, err)
+
+	job, err := v.Jobs().EnqueueFor(ctx, "export", "ok")
+	assert.NoError(t, err)
+	failing, err := v.Jobs().Enqueue("export", "fail")
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool { return job.Progress() == 0.5 }, time.Second, time.Millisecond)
+	assert.Equal(t, JobRunning, job.Status())
+
+	close(release)
+	assert.Eventually(t, func() bool { return job.Status() == JobDone && failing.Status() == JobFailed }, time.Second, time.Millisecond)
+	assert.Equal(t, "export.csv", job.Result())
+	assert.Equal(t, 1.0, job.Progress())
+	assert.EqualError(t, failing.Err(), "disk full")
+
+	got, ok := v.Jobs().Get(job.ID)
+	assert.True(t, ok)
+	assert.Equal(t, job, got)
+	v.Jobs().Remove(job.ID)
+	_, ok = v.Jobs().Get(job.ID)
+	assert.False(t, ok)
+
+	// finished jobs are removed once the retention period has passed
+	v.Jobs().retention = 0
+	v.Jobs().Register("noop", func(context.Context, *Job) (any, error) { return nil, nil })
+	next, err := v.Jobs().Enqueue("noop", nil)
+	assert.NoError(t, err)
+	_, ok = v.Jobs().Get(failing.ID)
+	assert.False(t, ok)
+	_, ok = v.Jobs().Get(next.ID)
+	assert.True(t, ok)
+
+	assert.NoError(t, v.Shutdown(t.Context()))
+	assert.ErrorIs(t, v.Jobs().ctx.Err(), context.Canceled)
+}
+
+func TestSubscribeChangeFeed(t *testing.T) {
+	v := New()
+	v.Page("/orders", func(c *Context) { c.View(func() h.H { return h.Div() }) })
+	v.mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/orders", nil))
+
+	var reported []string
+	v.Config(Options{OnError: func(c *Context, phase string, err error) { reported = append(reported, phase+": "+err.Error()) }})
+
+	stopped := make(chan struct{})
+	feed := ChangeFeedFunc(func(ctx context.Context, notify func(channel, payload string)) error {
+		notify("orders", "bad")
+		notify("orders", "42")
+		<-ctx.Done()
+		close(stopped)
+		return ctx.Err()
+	})
+	updated := make(chan string, 1)
+	v.Subscribe(feed, func(channel, payload string) LiveUpdate {
+		if payload == "bad" {
+			panic("malformed payload")
+		}
+		return LiveUpdate{Routes: []string{"/orders"}, Apply: func(c *Context) { updated <- channel + ":" + payload }}
+	})
+	v.startScheduler()
+	assert.Equal(t, "orders:42", <-updated)
+	assert.Equal(t, []string{"update: panic: malformed payload"}, reported)
+
+	assert.NoError(t, v.Shutdown(t.Context()))
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("change feed not stopped on shutdown")
+	}
+}
+
+func TestLazyComponent(t *testing.T) {
+	var ctx *Context
+	var view func() h.H
+	inits := 0
+	v := New()
+	v.Page("/", func(c *Context) {
+		ctx = c
+		view = c.LazyComponent(func(c *Context) {
+			inits++
+			c.View(func() h.H { return h.P(h.Text("report")) })
+		}, h.Text("loading"))
+		c.View(func() h.H { return view() })
+	})
+	inits = 0
+	w := httptest.NewRecorder()
+	v.mux.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	body := w.Body.String()
+	assert.Contains(t, body, "loading")
+	assert.Contains(t, body, "data-on-intersect")
+	assert.NotContains(t, body, "report")
+	assert.Equal(t, 0, inits)
+
+	var actionID string
+	for id := range ctx.actionRegistry {
+		actionID = id
+	}
+	for range 2 {
+		req := httptest.NewRequest("GET", "/_action/"+actionID+"?datastar="+url.QueryEscape(`{"via-ctx":"`+ctx.id+`"}`), nil)
+		v.mux.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	assert.Equal(t, 1, inits)
+	var buf bytes.Buffer
+	assert.NoError(t, view().Render(&buf))
+	assert.Contains(t, buf.String(), "<p>report</p>")
+}
+
+func TestLazyComponentLoadDuringActions(t *testing.T) {
+	var ctx *Context
+	var loadID, pingID string
+	var pings atomic.Int32
+	v := New()
+	v.Page("/", func(c *Context) {
+		ctx = c
+		lazy := c.LazyComponent(func(c *Context) {
+			for range 100 {
+				c.ActionWithTimeout(time.Second, func() {})
+			}
+			c.View(func() h.H { return h.P(h.Text("report")) })
+		})
+		loadID = ctx.actionOrder[len(ctx.actionOrder)-1]
+		pingID = c.Action(func() { pings.Add(1) }).id
+		c.View(func() h.H { return lazy() })
+	})
+	v.mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	action := func(id string) {
+		req := httptest.NewRequest("GET", "/_action/"+id+"?datastar="+url.QueryEscape(`{"via-ctx":"`+ctx.id+`"}`), nil)
+		v.mux.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Go(func() { action(pingID) })
+	}
+	wg.Go(func() { action(loadID) })
+	wg.Wait()
+	assert.Equal(t, int32(20), pings.Load())
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+	assert.Len(t, ctx.actionRegistry, 102)
+}
+
+func TestComponentParams(t *testing.T) {
+	var ctx *Context
+	var got []string
+	params := func(c *Context) {
+		got = append(got, c.GetQueryParam("page")+" "+c.GetPathParam("id"))
+		c.View(func() h.H { return h.Div() })
+	}
+	v := New()
+	v.Page("/items/{id}", func(c *Context) {
+		ctx = c
+		comp := c.Component(func(c *Context) {
+			params(c)
+			c.Component(params) // nested
+		})
+		lazy := c.LazyComponent(params)
+		c.View(func() h.H { return h.Div(comp(), lazy()) })
+	})
+	got = nil
+	v.mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/items/7?page=3", nil))
+	var actionID string
+	for id := range ctx.actionRegistry {
+		actionID = id
+	}
+	req := httptest.NewRequest("GET", "/_action/"+actionID+"?datastar="+url.QueryEscape(`{"via-ctx":"`+ctx.id+`"}`), nil)
+	v.mux.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, []string{"3 7", "3 7", "3 7"}, got)
+}
+
+func TestAsync(t *testing.T) {
+	c := newContext("ctx", "/", New())
+	c.View(func() h.H { return h.Div() })
+	release := make(chan struct{})
+	fail := false
+	val := Async(c, func() (int, error) {
+		<-release
+		if fail {
+			return 0, errors.New("unavailable")
+		}
+		return 42, nil
+	})
+	assert.True(t, val.Loading())
+	assert.Equal(t, 0, val.Value())
+
+	release <- struct{}{}
+	assert.Eventually(t, func() bool { return !val.Loading() }, time.Second, time.Millisecond)
+	assert.Equal(t, 42, val.Value())
+	assert.NoError(t, val.Err())
+
+	fail = true
+	val.Reload()
+	assert.True(t, val.Loading())
+	assert.Equal(t, 42, val.Value())
+	release <- struct{}{}
+	assert.Eventually(t, func() bool { return !val.Loading() }, time.Second, time.Millisecond)
+	assert.EqualError(t, val.Err(), "unavailable")
+	assert.Equal(t, 0, val.Value())
+}
+
+func TestRenderHooks(t *testing.T) {
+	var ctx *Context
+	var calls []string
+	v := New()
+	v.Page("/", func(c *Context) {
+		ctx = c
+		role := ""
+		c.BeforeRender(func() {
+			role = "admin"
+			calls = append(calls, "before")
+		})
+		c.AfterRender(func(html []byte, dur time.Duration) []byte {
+			calls = append(calls, "after:"+string(html))
+			assert.GreaterOrEqual(t, dur, time.Duration(0))
+			return html
+		})
+		c.AfterRender(func(html []byte, dur time.Duration) []byte {
+			return bytes.ReplaceAll(html, []byte("admin"), []byte("<em>admin</em>"))
+		})
+		c.View(func() h.H { return h.P(h.Text(role)) })
+	})
+	calls = nil
+
+	w := httptest.NewRecorder()
+	v.mux.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.Contains(t, w.Body.String(), "<p><em>admin</em></p>")
+	assert.Equal(t, []string{"before", `after:<div id="` + ctx.id + `"><p>admin</p></div>`}, calls)
+
+	ctx.Sync()
+	assert.Len(t, calls, 4)
+	html, err := ctx.renderView()
+	assert.NoError(t, err)
+	assert.Equal(t, `<div id="`+ctx.id+`"><p><em>admin</em></p></div>`, html)
+}
+
+func TestOnError(t *testing.T) {
+	var ctx *Context
+	var phases []string
+	var errs []error
+	v := New()
+	v.Config(Options{OnError: func(c *Context, phase string, err error) {
+		assert.Equal(t, ctx, c)
+		phases = append(phases, phase)
+		errs = append(errs, err)
+	}})
+	v.Page("/", func(c *Context) {
+		ctx = c
+		c.ReportError(errors.New("query failed"))
+		c.ReportError(nil)
+		boom := c.Action(func() {
+			c.ReportError(errors.New("save failed"))
+			panic("boom")
+		})
+		c.View(func() h.H { return h.Button(boom.OnClick()) })
+	})
+	phases, errs = nil, nil // of the registration of the page
+	v.mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	var actionID string
+	for id := range ctx.actionRegistry {
+		actionID = id
+	}
+	req := httptest.NewRequest("GET", "/_action/"+actionID+"?datastar="+url.QueryEscape(`{"via-ctx":"`+ctx.id+`"}`), nil)
+	v.mux.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, []string{PhaseRender, PhaseAction, PhaseAction}, phases)
+	assert.EqualError(t, errs[0], "query failed")
+	assert.EqualError(t, errs[1], "save failed")
+	assert.EqualError(t, errs[2], "panic: boom")
+}
+
+func TestClientErrors(t *testing.T) {
+	var ctx *Context
+	var phases []string
+	var errs []error
+	v := New()
+	v.Config(Options{OnError: func(c *Context, phase string, err error) {
+		assert.Equal(t, ctx, c)
+		phases = append(phases, phase)
+		errs = append(errs, err)
+	}})
+	v.Page("/{$}", func(c *Context) {
+		ctx = c
+		c.View(func() h.H { return h.Div() })
+	})
+	w := httptest.NewRecorder()
+	v.mux.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.Contains(t, w.Body.String(), clientErrorsPath)
+
+	tests := []struct {
+		name string
+		body string
+		code int
+	}{
+		{"reported", `{"ctx":"` + ctx.id + `","kind":"error","message":"x is not defined","url":"http://example.com/"}`, http.StatusNoContent},
+		{"sanitized", `{"ctx":"` + ctx.id + `","kind":"evil\n","message":"forged\n[error] line\u001b[31m` + strings.Repeat("x", 2000) + `","url":"http://user:pw@example.com/p?via-desktop-token=s3cret#frag"}`, http.StatusNoContent},
+		{"unknown context", `{"ctx":"missing","kind":"error","message":"x"}`, http.StatusNotFound},
+		{"invalid body", `{`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			v.mux.ServeHTTP(w, httptest.NewRequest("POST", clientErrorsPath, strings.NewReader(tt.body)))
+			assert.Equal(t, tt.code, w.Code)
+		})
+	}
+
+	assert.Equal(t, []string{PhaseClient, PhaseClient}, phases)
+	var cErr *ClientError
+	assert.ErrorAs(t, errs[0], &cErr)
+	assert.Equal(t, "http://example.com/", cErr.URL)
+	assert.EqualError(t, errs[0], "client error: x is not defined")
+
+	assert.ErrorAs(t, errs[1], &cErr)
+	assert.Equal(t, "error", cErr.Kind)
+	assert.Equal(t, "http://example.com/p", cErr.URL)
+	assert.True(t, strings.HasPrefix(cErr.Message, "forged\uFFFD[error] line\uFFFD[31mxxx"), cErr.Message)
+	assert.LessOrEqual(t, len(cErr.Message), maxClientErrorMessage+len("…"))
+
+	// the browser reports at most maxClientErrors, so further reports are dropped
+	for range maxClientErrors - 2 {
+		w := httptest.NewRecorder()
+		v.mux.ServeHTTP(w, httptest.NewRequest("POST", clientErrorsPath, strings.NewReader(`{"ctx":"`+ctx.id+`","message":"x"}`)))
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	}
+	w = httptest.NewRecorder()
+	v.mux.ServeHTTP(w, httptest.NewRequest("POST", clientErrorsPath, strings.NewReader(`{"ctx":"`+ctx.id+`","message":"x"}`)))
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Len(t, phases, maxClientErrors)
+}
+
+func TestCtx(t *testing.T) {
+	var ctx *Context
+	var actionCtx context.Context
+	v := New()
+	v.Page("/", func(c *Context) {
+		ctx = c
+		act := c.Action(func() {
+			actionCtx = c.Ctx()
+			assert.NoError(t, actionCtx.Err())
+		})
+		c.View(func() h.H { return h.Button(act.OnClick()) })
+	})
+	v.mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	pageCtx := ctx.Ctx()
+	assert.NoError(t, pageCtx.Err())
+
+	var actionID string
+	for id := range ctx.actionRegistry {
+		actionID = id
+	}
+	req := httptest.NewRequest("GET", "/_action/"+actionID+"?datastar="+url.QueryEscape(`{"via-ctx":"`+ctx.id+`"}`), nil)
+	v.mux.ServeHTTP(httptest.NewRecorder(), req)
+	assert.ErrorIs(t, actionCtx.Err(), context.Canceled)
+	assert.Equal(t, pageCtx, ctx.Ctx())
+
+	v.mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/_session/close", strings.NewReader(ctx.id)))
+	assert.ErrorIs(t, pageCtx.Err(), context.Canceled)
+}
+
+func TestActionTimeout(t *testing.T) {
+	var ctx *Context
+	var slow, fast *actionTrigger
+	var reported error
+	var order []string
+	release := make(chan struct{})
+	v := New()
+	v.Config(Options{
+		ActionTimeout: time.Hour,
+		SSE:           SSEOptions{PatchBuffer: 1},
+		OnError:       func(c *Context, phase string, err error) { reported = err },
+	})
+	v.Page("/", func(c *Context) {
+		ctx = c
+		slow = c.ActionWithTimeout(20*time.Millisecond, func() {
+			<-c.Ctx().Done()
+			order = append(order, "slow canceled")
+			<-release // keeps running after the timeout
+			order = append(order, "slow done")
+		})
+		fast = c.Action(func() { order = append(order, "fast") })
+		c.View(func() h.H { return h.Div(h.Button(slow.OnClick()), h.Button(fast.OnClick())) })
+	})
+	v.mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	ctx.sendPatch(patch{typ: patchTypeSignals, content: `{"a":1}`}) // fills the buffer
+
+	action := func(id string) {
+		req := httptest.NewRequest("GET", "/_action/"+id+"?datastar="+url.QueryEscape(`{"via-ctx":"`+ctx.id+`"}`), nil)
+		v.mux.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	action(slow.id)
+	assert.ErrorIs(t, reported, ErrActionTimeout)
+
+	// the next action waits until the timed out action returns
+	fastDone := make(chan struct{})
+	go func() {
+		action(fast.id)
+		close(fastDone)
+	}()
+	select {
+	case <-fastDone:
+		t.Fatal("action ran concurrently with the timed out action")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+	<-fastDone
+	assert.Equal(t, []string{"slow canceled", "slow done", "fast"}, order)
+
+	// the timeout signal is not dropped when the buffer is full
+	assert.Equal(t, `{"a":1}`, (<-ctx.patchChan).content)
+	assert.Equal(t, `{"_viaTimeout":"`+slow.id+`"}`, (<-ctx.patchChan).content)
+}
+
+func TestSSEOptions(t *testing.T) {
+	var ctx *Context
+	v := New()
+	v.Config(Options{SSE: SSEOptions{RetryInterval: 5 * time.Second, PatchBuffer: 4}})
+	v.Page("/", func(c *Context) {
+		ctx = c
+		c.View(func() h.H { return h.Div() })
+	})
+	v.mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, 4, cap(ctx.patchChan))
+
+	w := httptest.NewRecorder()
+	sse := datastar.NewSSE(w, httptest.NewRequest("GET", "/_sse", nil))
+	assert.NoError(t, v.writePatch(sse, ctx, patch{typ: patchTypeSignals, content: `{"a":1}`}))
+	assert.Contains(t, w.Body.String(), "retry: 5000")
+}
+
+func TestThemes(t *testing.T) {
+	var ctx *Context
+	v := New()
+	v.Config(Options{Themes: []Theme{
+		{Name: "light", ColorScheme: "light", Vars: map[string]string{"--bg": "#fff"}},
+		{Name: "dark", ColorScheme: "dark", Vars: map[string]string{"--bg": "#000"}},
+	}})
+	v.Page("/", func(c *Context) {
+		ctx = c
+		c.View(func() h.H { return h.Div() })
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.AddCookie(&http.Cookie{Name: themeCookie, Value: "dark"})
+	w := httptest.NewRecorder()
+	v.mux.ServeHTTP(w, req)
+	body := w.Body.String()
+	assert.Equal(t, "dark", ctx.Theme())
+	assert.Contains(t, body, `<html data-theme="dark">`)
+	assert.Contains(t, body, `:root[data-theme="dark"]{color-scheme:dark;--bg:#000;}`)
+	assert.Contains(t, body, `:root:not([data-theme]){color-scheme:light;--bg:#fff;}`)
+	assert.Contains(t, body, `@media (prefers-color-scheme:dark){:root:not([data-theme]){color-scheme:dark;--bg:#000;}}`)
+
+	ctx.SetTheme("sepia")
+	assert.Equal(t, "dark", ctx.Theme())
+	assert.Empty(t, ctx.patchChan)
+
+	ctx.SetTheme("light")
+	assert.Equal(t, "light", ctx.Theme())
+	script := (<-ctx.patchChan).content
+	assert.Contains(t, script, `document.cookie="via-theme"+'='+t`)
+	assert.Contains(t, script, `new BroadcastChannel("via-theme").postMessage(t)`)
+
+	req = httptest.NewRequest("GET", "/", nil)
+	req.AddCookie(&http.Cookie{Name: themeCookie, Value: "unknown"})
+	v.mux.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, "", ctx.Theme())
+}
+
+func TestHTMLFormat(t *testing.T) {
+	var ctx *Context
+	v := New()
+	v.Config(Options{HTMLFormat: HTMLFormatPretty})
+	v.Page("/", func(c *Context) {
+		ctx = c
+		c.View(func() h.H { return h.Div(h.P(h.Text("hi"))) })
+	})
+
+	w := httptest.NewRecorder()
+	v.mux.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.Contains(t, w.Body.String(), "\n      <div>\n        <p>hi</p>\n      </div>\n")
+
+	ctx.SyncElements(h.Div(h.ID("x"), h.P(h.Text("a"))))
+	assert.Equal(t, "<div id=\"x\">\n  <p>a</p>\n</div>\n", (<-ctx.patchChan).content)
+
+	v.Config(Options{HTMLFormat: HTMLFormatCompact})
+	ctx.SyncElements(h.Div(h.ID("x"), h.P(h.Text("a"))))
+	assert.Equal(t, `<div id="x"><p>a</p></div>`, (<-ctx.patchChan).content)
+}
+
+func TestSitemap(t *testing.T) {
+	v := New()
+	v.Sitemap(SitemapOptions{
+		BaseURL: "https://example.com/",
+		Route: func(route string) (SitemapEntry, bool) {
+			if route == "/admin" {
+				return SitemapEntry{}, false
+			}
+			return SitemapEntry{Priority: 0.8, ChangeFreq: "daily"}, true
+		},
+		Expand: map[string]func() []SitemapEntry{
+			"/posts/{slug}": func() []SitemapEntry {
+				return []SitemapEntry{{Loc: "/posts/hello", LastMod: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)}}
+			},
+		},
+	})
+	for _, route := range []string{"/{$}", "/admin", "/posts/{slug}", "/users/{id}"} {
+		v.Page(route, func(c *Context) { c.View(func() h.H { return h.Div() }) })
+	}
+
+	w := httptest.NewRecorder()
+	v.mux.ServeHTTP(w, httptest.NewRequest("GET", "/sitemap.xml", nil))
+	assert.Equal(t, "application/xml; charset=utf-8", w.Header().Get("Content-Type"))
+	body := w.Body.String()
+	assert.Contains(t, body, "<url>\n    <loc>https://example.com/</loc>\n    <changefreq>daily</changefreq>\n    <priority>0.8</priority>\n  </url>")
+	assert.Contains(t, body, "<url>\n    <loc>https://example.com/posts/hello</loc>\n    <lastmod>2025-01-02T03:04:05Z</lastmod>\n  </url>")
+	assert.NotContains(t, body, "/admin")
+	assert.NotContains(t, body, "/users")
+}
+
+func TestSEO(t *testing.T) {
+	v := New()
+	v.Page("/posts/{slug}", func(c *Context) {
+		c.SEO(seo.Meta{
+			Title:       "Hello & welcome",
+			Description: "A post",
+			Canonical:   "/posts/" + c.GetPathParam("slug"),
+			OGImage:     "https://cdn.example.com/cover.png",
+		})
+		c.View(func() h.H { return h.Div() })
+	})
+
+	w := httptest.NewRecorder()
+	v.mux.ServeHTTP(w, httptest.NewRequest("GET", "http://example.com/posts/hello", nil))
+	body := w.Body.String()
+	assert.Contains(t, body, "<title>Hello &amp; welcome</title>")
+	assert.Contains(t, body, `<meta name="description" content="A post">`)
+	assert.Contains(t, body, `<link rel="canonical" href="http://example.com/posts/hello">`)
+	assert.Contains(t, body, `<meta property="og:url" content="http://example.com/posts/hello">`)
+	assert.Contains(t, body, `<meta property="og:image" content="https://cdn.example.com/cover.png">`)
+	assert.Contains(t, body, `<meta name="twitter:card" content="summary_large_image">`)
+}
+
+func TestFeed(t *testing.T) {
+	calls := 0
+	published := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	items := func() []feed.Item {
+		calls++
+		return []feed.Item{{Title: "Hello", Link: "/posts/hello", Description: "First <post>", Published: published}}
+	}
+	v := New()
+	v.Feed("/blog.xml", items, feed.Meta{Title: "Blog"})
+	v.Feed("/atom.xml", items, feed.Meta{Format: feed.Atom, Author: "Ann"})
+
+	w := httptest.NewRecorder()
+	v.mux.ServeHTTP(w, httptest.NewRequest("GET", "http://example.com/blog.xml", nil))
+	body := w.Body.String()
+	assert.Equal(t, "application/rss+xml; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(t, body, `<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom">`)
+	assert.Contains(t, body, `<atom:link href="http://example.com/blog.xml" rel="self" type="application/rss+xml"></atom:link>`)
+	assert.Contains(t, body, "<title>Blog</title>")
+	assert.Contains(t, body, `<guid isPermaLink="true">http://example.com/posts/hello</guid>`)
+	assert.Contains(t, body, "<description>First &lt;post&gt;</description>")
+	assert.Contains(t, body, "<pubDate>Thu, 02 Jan 2025 03:04:05 +0000</pubDate>")
+
+	req := httptest.NewRequest("GET", "http://example.com/blog.xml", nil)
+	req.Header.Set("If-None-Match", w.Header().Get("ETag"))
+	w = httptest.NewRecorder()
+	v.mux.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Equal(t, 1, calls)
+
+	w = httptest.NewRecorder()
+	v.mux.ServeHTTP(w, httptest.NewRequest("GET", "http://example.com/atom.xml", nil))
+	body = w.Body.String()
+	assert.Equal(t, "application/atom+xml; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(t, body, `<feed xmlns="http://www.w3.org/2005/Atom">`)
+	assert.Contains(t, body, "<title>⚡ Via</title>")
+	assert.Contains(t, body, "<updated>2025-01-02T03:04:05Z</updated>")
+	assert.Contains(t, body, "<author>\n      <name>Ann</name>\n    </author>")
+
+	// feeds without a link are cached for a few base URLs only
+	calls = 0
+	get := func(url string) string {
+		w := httptest.NewRecorder()
+		v.mux.ServeHTTP(w, httptest.NewRequest("GET", url, nil))
+		return w.Body.String()
+	}
+	for i := range maxFeedHosts + 1 {
+		get(fmt.Sprintf("http://host%d.example/blog.xml", i))
+	}
+	assert.Equal(t, maxFeedHosts+1, calls)
+	get(fmt.Sprintf("http://host%d.example/blog.xml", maxFeedHosts))
+	assert.Equal(t, maxFeedHosts+1, calls)
+	get("http://host0.example/blog.xml")
+	assert.Equal(t, maxFeedHosts+2, calls, "least recently used base URL not evicted")
+
+	// feeds with a link are rendered once for all hosts
+	calls = 0
+	v.Feed("/news.xml", items, feed.Meta{Link: "https://example.com/"})
+	body = get("http://a.example/news.xml")
+	assert.Contains(t, body, `<atom:link href="https://example.com/news.xml" rel="self" type="application/rss+xml"></atom:link>`)
+	assert.Equal(t, body, get("http://b.example/news.xml"))
+	assert.Equal(t, 1, calls)
+}
+
+func TestExport(t *testing.T) {
+	v := New()
+	v.Page("/{$}", func(c *Context) {
+		c.View(func() h.H { return h.H1(h.Text("Home")) })
+	})
+	v.Page("/posts/{slug}", func(c *Context) {
+		c.View(func() h.H { return h.H1(h.Text("Post " + c.GetPathParam("slug"))) })
+	})
+
+	dir := t.TempDir()
+	assert.NoError(t, v.Export(dir, "/", "/posts/hello"))
+	assert.Zero(t, v.currSessionNum())
+
+	home, err := os.ReadFile(filepath.Join(dir, "index.html"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(home), "<h1>Home</h1>")
+	assert.NotContains(t, string(home), "/_sse")
+	assert.NotContains(t, string(home), "via-ctx")
+
+	post, err := os.ReadFile(filepath.Join(dir, "posts", "hello", "index.html"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(post), "<h1>Post hello</h1>")
+
+	bundle, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(v.datastarSrc())))
+	assert.NoError(t, err)
+	assert.Equal(t, datastarJS, bundle)
+
+	assert.ErrorContains(t, v.Export(dir, "/missing/page/here"), "status 404")
+}
+
+func TestStaticSnapshot(t *testing.T) {
+	v := New()
+	v.Config(Options{StaticSnapshot: IsBot})
+	v.Page("/", func(c *Context) {
+		c.View(func() h.H { return h.H1(h.Text("Hello")) })
+	})
+
+	testcases := []struct {
+		desc      string
+		userAgent string
+		static    bool
+	}{
+		{"browser", "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0 Safari/537.36", false},
+		{"googlebot", "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)", true},
+		{"slack unfurler", "Slackbot-LinkExpanding 1.0 (+https://api.slack.com/robots)", true},
+		{"facebook", "facebookexternalhit/1.1", true},
+		{"no user agent", "", false},
+	}
+	for _, testcase := range testcases {
+		t.Run(testcase.desc, func(t *testing.T) {
+			before := v.currSessionNum()
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("User-Agent", testcase.userAgent)
+			w := httptest.NewRecorder()
+			v.mux.ServeHTTP(w, req)
+			assert.Contains(t, w.Body.String(), "<h1>Hello</h1>")
+			assert.Equal(t, !testcase.static, strings.Contains(w.Body.String(), "/_sse"))
+			if testcase.static {
+				assert.Equal(t, before, v.currSessionNum())
+			} else {
+				assert.Equal(t, before+1, v.currSessionNum())
+			}
+		})
+	}
+}
+
+func TestIsBot(t *testing.T) {
+	testcases := []struct {
+		desc      string
+		userAgent string
+		bot       bool
+	}{
+		{"googlebot", "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)", true},
+		{"bingbot", "Mozilla/5.0 (compatible; bingbot/2.0; +http://www.bing.com/bingbot.htm)", true},
+		{"slack unfurler", "Slackbot-LinkExpanding 1.0 (+https://api.slack.com/robots)", true},
+		{"facebook", "facebookexternalhit/1.1 (+http://www.facebook.com/externalhit_uatext.php)", true},
+		{"whatsapp", "WhatsApp/2.23.20.0 A", true},
+		{"internet archive", "Mozilla/5.0 (compatible; archive.org_bot +http://archive.org/details/archive.org_bot)", true},
+		{"chrome", "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0 Safari/537.36", false},
+		{"cubot phone", "Mozilla/5.0 (Linux; Android 11; CUBOT X50 Build/RP1A.200720.011) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Mobile Safari/537.36", false},
+		{"duckduckgo browser", "Mozilla/5.0 (Linux; Android 14) AppleWebKit/537.36 (KHTML, like Gecko) Version/4.0 Chrome/124.0 Mobile Safari/537.36 DuckDuckGo/5", false},
+		{"pinterest app", "Mozilla/5.0 (Linux; Android 13; Pixel 7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Mobile Safari/537.36 [Pinterest/Android]", false},
+		{"yandex browser", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/122.0 YaBrowser/24.4 Yowser/2.5 Safari/537.36", false},
+		{"http client with contact url", "MyFeedReader/1.0 (+https://example.com/about)", false},
+		{"name inside a word", "Mozilla/5.0 (Linux; Android 12; YetiPhone X1) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Mobile Safari/537.36", false},
+		{"no user agent", "", false},
+	}
+	for _, testcase := range testcases {
+		t.Run(testcase.desc, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("User-Agent", testcase.userAgent)
+			assert.Equal(t, testcase.bot, IsBot(req))
+		})
+	}
+}
+
+func TestGroup(t *testing.T) {
+	v := New()
+	var order []string
+	admin := v.Group("/admin", func(c *Context) bool {
+		order = append(order, "admin")
+		if c.Cookie("session") == "" {
+			c.Redirect("/login")
+			return false
+		}
+		return true
+	})
+	admin.Page("/", func(c *Context) {
+		order = append(order, "dashboard")
+		c.View(func() h.H { return h.H1(h.Text("Dashboard")) })
+	})
+	owner := admin.Group("/owner", func(c *Context) bool {
+		order = append(order, "owner")
+		return c.Cookie("session") == "owner"
+	})
+	owner.Page("/billing", func(c *Context) {
+		order = append(order, "billing")
+		c.View(func() h.H { return h.H1(h.Text("Billing")) })
+	})
+
+	testcases := []struct {
+		desc     string
+		path     string
+		session  string
+		status   int
+		location string
+		body     string
+		order    []string
+	}{
+		{"redirected without session", "/admin", "", http.StatusFound, "/login", "", []string{"admin"}},
+		{"group page", "/admin", "user", http.StatusOK, "", "Dashboard", []string{"admin", "dashboard"}},
+		{"nested group forbidden", "/admin/owner/billing", "user", http.StatusForbidden, "", "", []string{"admin", "owner"}},
+		{"nested group page", "/admin/owner/billing", "owner", http.StatusOK, "", "Billing", []string{"admin", "owner", "billing"}},
+	}
+	for _, testcase := range testcases {
+		t.Run(testcase.desc, func(t *testing.T) {
+			order = nil
+			before := v.currSessionNum()
+			req := httptest.NewRequest("GET", testcase.path, nil)
+			if testcase.session != "" {
+				req.AddCookie(&http.Cookie{Name: "session", Value: testcase.session})
+			}
+			w := httptest.NewRecorder()
+			v.mux.ServeHTTP(w, req)
+			assert.Equal(t, testcase.status, w.Code)
+			assert.Equal(t, testcase.location, w.Header().Get("Location"))
+			assert.Equal(t, testcase.order, order)
+			if testcase.body != "" {
+				assert.Contains(t, w.Body.String(), testcase.body)
+				assert.Equal(t, before+1, v.currSessionNum())
+			} else {
+				assert.Equal(t, before, v.currSessionNum())
+			}
+		})
+	}
+}
+
+func TestHost(t *testing.T) {
+	v := New()
+	admin := v.Host("admin.example.com")
+	admin.Page("/{$}", func(c *Context) {
+		c.View(func() h.H { return h.H1(h.Text("Admin")) })
+	})
+	admin.Page("/users", func(c *Context) {
+		c.View(func() h.H { return h.H1(h.Text("Users")) })
+	})
+	v.Page("/{$}", func(c *Context) {
+		c.View(func() h.H { return h.H1(h.Text("Home")) })
+	})
+	v.Sitemap(SitemapOptions{})
+
+	testcases := []struct {
+		desc   string
+		host   string
+		path   string
+		status int
+		body   string
+	}{
+		{"host page", "admin.example.com", "/", http.StatusOK, "Admin"},
+		{"host page with port", "admin.example.com:8080", "/users", http.StatusOK, "Users"},
+		{"other host", "www.example.com", "/", http.StatusOK, "Home"},
+		{"host only page on other host", "www.example.com", "/users", http.StatusNotFound, ""},
+		{"host sitemap", "admin.example.com", "/sitemap.xml", http.StatusOK, "<loc>http://admin.example.com/users</loc>"},
+	}
+	for _, testcase := range testcases {
+		t.Run(testcase.desc, func(t *testing.T) {
+			req := httptest.NewRequest("GET", testcase.path, nil)
+			req.Host = testcase.host
+			w := httptest.NewRecorder()
+			v.mux.ServeHTTP(w, req)
+			assert.Equal(t, testcase.status, w.Code)
+			assert.Contains(t, w.Body.String(), testcase.body)
+		})
+	}
+
+	req := httptest.NewRequest("GET", "/sitemap.xml", nil)
+	req.Host = "www.example.com"
+	w := httptest.NewRecorder()
+	v.mux.ServeHTTP(w, req)
+	assert.NotContains(t, w.Body.String(), "users")
+}
+
+func TestTenancy(t *testing.T) {
+	var ctx *Context
+	v := New()
+	v.Config(Options{TenantResolver: func(r *http.Request) string {
+		return strings.Split(r.Host, ".")[0]
+	}})
+	stores := NewPerTenant(func(tenant string) map[string]int {
+		return map[string]int{}
+	})
+	v.Page("/", func(c *Context) {
+		ctx = c
+		stores.Get(c)["visits"]++
+		c.View(func() h.H { return h.Div() })
+	})
+
+	for _, host := range []string{"acme.example.com", "acme.example.com", "globex.example.com"} {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Host = host
+		v.mux.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	assert.Equal(t, "globex", ctx.Tenant())
+	assert.Equal(t, 2, stores.For("acme")["visits"])
+	assert.Equal(t, 1, stores.For("globex")["visits"])
+	assert.Len(t, v.liveContexts([]string{"/"}, nil, "acme"), 2)
+
+	actionID := ctx.Action(func() {}).id
+	tests := []struct {
+		name   string
+		host   string
+		status int
+	}{
+		{"same tenant", "globex.example.com", http.StatusOK},
+		{"other tenant", "acme.example.com", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/_action/"+actionID+"?datastar="+url.QueryEscape(`{"via-ctx":"`+ctx.id+`"}`), nil)
+			req.Host = tt.host
+			w := httptest.NewRecorder()
+			v.mux.ServeHTTP(w, req)
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestHooks(t *testing.T) {
+	var ctx *Context
+	var events []string
+	v := New()
+	v.Config(Options{Plugins: []Plugin{func(v *V) {
+		v.BeforePage(func(c *Context) bool {
+			events = append(events, "before page")
+			return c.GetQueryParam("deny") == ""
+		})
+		v.AfterPageRender(func(c *Context, html []byte, dur time.Duration) {
+			events = append(events, "after render")
+			assert.Contains(t, string(html), "<h1>Hooked</h1>")
+		})
+		v.BeforeAction(func(c *Context, actionID string) bool {
+			events = append(events, "before action")
+			return c.GetQueryParam("readonly") == ""
+		})
+		v.AfterAction(func(c *Context, actionID string, dur time.Duration, err error) {
+			events = append(events, fmt.Sprintf("after action err=%v", err))
+		})
+	}}})
+	v.Page("/", func(c *Context) {
+		ctx = c
+		events = append(events, "init")
+		c.View(func() h.H { return h.H1(h.Text("Hooked")) })
+	})
+
+	events = nil
+	w := httptest.NewRecorder()
+	v.mux.ServeHTTP(w, httptest.NewRequest("GET", "/?deny=1", nil))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, []string{"before page"}, events)
+
+	events = nil
+	v.mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, []string{"before page", "init", "after render"}, events)
+
+	events = nil
+	ok := ctx.Action(func() {}).id
+	fail := ctx.Action(func() { panic("boom") }).id
+	for _, id := range []string{ok, fail} {
+		req := httptest.NewRequest("GET", "/_action/"+id+"?datastar="+url.QueryEscape(`{"via-ctx":"`+ctx.id+`"}`), nil)
+		v.mux.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	assert.Equal(t, []string{"before action", "after action err=<nil>", "before action", "after action err=panic: boom"}, events)
+
+	events = nil
+	ctx.queryParams["readonly"] = "1"
+	w = httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/_action/"+ok+"?datastar="+url.QueryEscape(`{"via-ctx":"`+ctx.id+`"}`), nil)
+	v.mux.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, []string{"before action"}, events)
+}
+
+func TestEvents(t *testing.T) {
+	type bid struct{ Amount int }
+	bids := NewTopic[bid]("bids")
+	v := New()
+	var ctxs []*Context
+	var mu sync.Mutex
+	received := map[*Context]int{}
+	v.Page("/", func(c *Context) {
+		ctxs = append(ctxs, c)
+		bids.Subscribe(c, func(b bid) {
+			mu.Lock()
+			received[c] += b.Amount
+			mu.Unlock()
+		})
+		c.View(func() h.H { return h.Div() })
+	})
+	var logged []int
+	unsubscribe := bids.SubscribeFunc(v.Events(), func(b bid) { logged = append(logged, b.Amount) })
+
+	ctxs = nil
+	for range 2 {
+		v.mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	}
+	bids.Publish(v.Events(), bid{Amount: 10})
+	assert.Equal(t, 10, received[ctxs[0]])
+	assert.Equal(t, 10, received[ctxs[1]])
+
+	v.unregisterCtx(ctxs[0])
+	unsubscribe()
+	assert.Eventually(t, func() bool {
+		v.Events().mu.RLock()
+		defer v.Events().mu.RUnlock()
+		return len(v.Events().subs["bids"]) == 1
+	}, time.Second, time.Millisecond)
+	bids.Publish(v.Events(), bid{Amount: 5})
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 10, received[ctxs[0]])
+	assert.Equal(t, 15, received[ctxs[1]])
+	assert.Equal(t, []int{10}, logged)
+}
+
+func TestEmitOn(t *testing.T) {
+	v := New()
+	var page, list, detail *Context
+	var events []string
+	v.Page("/", func(c *Context) {
+		page = c
+		c.On("row-selected", func(payload any) { events = append(events, "page:"+payload.(string)) })
+		listComp := c.Component(func(c *Context) {
+			list = c
+			c.On("row-selected", func(payload any) { events = append(events, "list:"+payload.(string)) })
+			c.View(func() h.H { return h.Div() })
+		})
+		detailComp := c.Component(func(c *Context) {
+			detail = c
+			c.On("row-selected", func(payload any) { events = append(events, "detail:"+payload.(string)) })
+			c.On("other", func(payload any) { events = append(events, "other") })
+			c.View(func() h.H { return h.Div() })
+		})
+		c.View(func() h.H { return h.Div(listComp(), detailComp()) })
+	})
+	v.mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	list.Emit("row-selected", "42")
+	assert.Equal(t, []string{"page:42", "detail:42"}, events)
+
+	events = nil
+	page.Emit("row-selected", "7")
+	assert.Equal(t, []string{"list:7", "detail:7"}, events)
+
+	events = nil
+	detail.Emit("unknown", nil)
+	assert.Empty(t, events)
+}
+
+func TestIDGenerator(t *testing.T) {
+	render := func() (string, *Context) {
+		var ctx *Context
+		v := New()
+		v.Config(Options{IDGenerator: SequentialIDs()})
+		v.Page("/", func(c *Context) {
+			ctx = c
+			name := c.Signal("via")
+			greet := c.Action(func() {})
+			id := c.NewID()
+			c.View(func() h.H { return h.Div(h.ID(id), h.Input(name.Bind()), h.Button(greet.OnClick())) })
+		})
+		w := httptest.NewRecorder()
+		v.mux.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		return w.Body.String(), ctx
+	}
+	first, ctx := render()
+	second, _ := render()
+	assert.Equal(t, first, second)
+	assert.Equal(t, "/_/00000004", ctx.id)
+	assert.Contains(t, first, `<div id="00000007">`)
+
+	v := New()
+	v.Page("/", func(c *Context) {
+		ctx = c
+		c.View(func() h.H { return h.Div() })
+	})
+	v.mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	assert.Len(t, strings.TrimPrefix(ctx.id, "/_/"), 32)
+}
+
+func TestShareURL(t *testing.T) {
+	var ctx *Context
+	v := New()
+	v.Page("/boards/{id}", func(c *Context) {
+		ctx = c
+		c.View(func() h.H { return h.Div() })
+	})
+	req := httptest.NewRequest("GET", "/boards/7?view=list", nil)
+	req.Host = "example.com"
+	v.mux.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, "http://example.com/boards/7?view=list", ctx.ShareURL())
+}
+
+func TestViewerURL(t *testing.T) {
+	var owner *Context
+	var shareURL string
+	resets := 0
+	v := New()
+	v.Page("/", func(c *Context) {
+		owner = c
+		count := c.Signal(3)
+		inc := c.Action(func() { count.SetValue(count.Int() + 1) })
+		reset := c.Action(func() { resets++ })
+		upload := c.Upload(func([]UploadedFile) {})
+		shareURL = c.ViewerURL(reset)
+		c.View(func() h.H {
+			return h.Div(h.P(count.Text()), h.Button(inc.OnClick()), h.Button(reset.OnClick()),
+				h.Form(h.Attr("action", upload)))
+		})
+	})
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Host = "example.com"
+	v.mux.ServeHTTP(httptest.NewRecorder(), req)
+	assert.True(t, strings.HasPrefix(shareURL, "http://example.com/_viewer/"))
+
+	w := httptest.NewRecorder()
+	v.mux.ServeHTTP(w, httptest.NewRequest("GET", strings.TrimPrefix(shareURL, "http://example.com"), nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, owner.viewers, 1)
+	viewer := owner.viewers[0]
+	assert.Equal(t, owner, viewer.viewing)
+	// viewers never learn the ID of the shared context, which authorizes its requests
+	assert.NotContains(t, w.Body.String(), owner.id)
+	assert.NotContains(t, w.Body.String(), url.QueryEscape(owner.id))
+	assert.NotContains(t, viewer.id, owner.id)
+	assert.Contains(t, w.Body.String(), `<div id="`+viewer.id+`"><div><p`)
+	assert.Contains(t, w.Body.String(), "via-ctx="+url.QueryEscape(viewer.id))
+
+	go viewer.syncViewer()
+	<-viewer.patchChan
+	p := <-viewer.patchChan
+	assert.Contains(t, p.content, ":3")
+
+	actions := slices.Collect(maps.Keys(owner.actionRegistry))
+	for _, id := range actions {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/_action/"+id+"?datastar="+url.QueryEscape(`{"via-ctx":"`+viewer.id+`"}`), nil)
+		v.mux.ServeHTTP(w, req)
+		if _, allowed := viewer.actionRegistry[id]; allowed {
+			assert.Equal(t, http.StatusOK, w.Code)
+		} else {
+			assert.Equal(t, http.StatusForbidden, w.Code)
+		}
+	}
+	assert.Equal(t, 1, resets)
+
+	owner.SyncElements(h.Div(h.ID("x")))
+	assert.Contains(t, (<-viewer.patchChan).content, `id="x"`)
+	owner.Sync()
+	p = <-viewer.patchChan
+	assert.Contains(t, p.content, `<div id="`+viewer.id+`">`)
+	assert.NotContains(t, p.content, owner.id)
+
+	v.unregisterCtx(viewer)
+	v.unregisterCtx(owner)
+	assert.Eventually(t, func() bool {
+		owner.mu.RLock()
+		defer owner.mu.RUnlock()
+		v.viewerGrants.mu.RLock()
+		defer v.viewerGrants.mu.RUnlock()
+		return len(owner.viewers) == 0 && len(v.viewerGrants.grants) == 0
+	}, time.Second, time.Millisecond)
+	w = httptest.NewRecorder()
+	v.mux.ServeHTTP(w, httptest.NewRequest("GET", strings.TrimPrefix(shareURL, "http://example.com"), nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestAudit(t *testing.T) {
+	var ctx *Context
+	var buf bytes.Buffer
+	var entries []AuditEntry
+	sink := AuditLog(&buf)
+	v := New()
+	v.Config(Options{Audit: func(e AuditEntry) {
+		entries = append(entries, e)
+		sink(e)
+	}})
+	var name *signal
+	v.Page("/", func(c *Context) {
+		ctx = c
+		c.SetUser("alice")
+		name = c.Signal("bob")
+		c.ActionNamed("delete-user", func() { name.SetValue("") })
+		c.Action(func() {})
+		c.View(func() h.H { return h.Div() })
+	})
+	v.mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	ids := slices.Collect(maps.Keys(ctx.actionRegistry))
+	slices.SortFunc(ids, func(a, b string) int { return len(ctx.actionNames[a]) - len(ctx.actionNames[b]) })
+	for _, id := range ids {
+		req := httptest.NewRequest("GET", "/_action/"+id+"?datastar="+url.QueryEscape(`{"via-ctx":"`+ctx.id+`","`+name.ID()+`":"bob"}`), nil)
+		v.mux.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	assert.Len(t, entries, 2)
+	unnamed, named := entries[0], entries[1]
+	assert.Equal(t, unnamed.ActionID, unnamed.Action)
+	assert.Empty(t, unnamed.Changed)
+	assert.Equal(t, "delete-user", named.Action)
+	assert.Equal(t, "alice", named.User)
+	assert.Equal(t, ctx.id, named.ContextID)
+	assert.Equal(t, []string{name.ID()}, named.Changed)
+	assert.Equal(t, 2, strings.Count(buf.String(), "\n"))
+	assert.Contains(t, buf.String(), `"action":"delete-user"`)
+}
+
+func TestRecordReplay(t *testing.T) {
+	t.Chdir(t.TempDir())
+	var ctx *Context
+	v := New()
+	v.Config(Options{DevMode: true})
+	v.Page("/notes/{id}", func(c *Context) {
+		ctx = c
+		var notes []string
+		note := c.Signal("")
+		c.ActionNamed("add", func() {
+			if note.String() == "boom" {
+				panic("boom")
+			}
+			notes = append(notes, c.GetPathParam("id")+":"+note.String())
+		})
+		c.Action(func() { notes = nil })
+		c.View(func() h.H { return h.P(h.Text(strings.Join(notes, ","))) })
+	})
+	v.mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/notes/7", nil))
+	invoke := func(action int, note string) {
+		req := httptest.NewRequest("GET", "/_action/"+ctx.actionOrder[action]+"?datastar="+
+			url.QueryEscape(`{"via-ctx":"`+ctx.id+`","`+ctx.signalOrder[0]+`":"`+note+`"}`), nil)
+		v.mux.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	invoke(0, "a")
+	invoke(1, "")
+	invoke(0, "b")
+	invoke(0, "c")
+
+	w := httptest.NewRecorder()
+	v.mux.ServeHTTP(w, httptest.NewRequest("GET", "/_recording?id="+url.QueryEscape(ctx.id), nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var rec Recording
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &rec))
+	assert.Len(t, rec.Actions, 4)
+	assert.Equal(t, "add", rec.Actions[0].Name)
+	assert.Equal(t, "/notes/7", rec.RequestURI)
+
+	body := w.Body.String()
+	w = httptest.NewRecorder()
+	v.mux.ServeHTTP(w, httptest.NewRequest("POST", "/_replay", strings.NewReader(body)))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "<p>7:b,7:c</p>")
+
+	rec.Actions = append(rec.Actions, RecordedAction{Action: 0, Name: "add", Signals: map[int]any{0: "boom"}})
+	_, err := v.Replay(rec)
+	assert.ErrorContains(t, err, "action 4 (add): panic: boom")
+}
+
+func TestRoutinesStopOnDispose(t *testing.T) {
+	var ticks atomic.Int64
+	tick := func() { ticks.Add(1) }
+	stopped := func() bool {
+		time.Sleep(5 * time.Millisecond) // let running ticks finish
+		n := ticks.Load()
+		time.Sleep(10 * time.Millisecond)
+		return n == ticks.Load()
+	}
+
+	v := New()
+	c := newContext("/_/test", "/", v)
+	var routines []*OnIntervalRoutine
+	for range 3 {
+		r := c.OnInterval(time.Millisecond, tick)
+		r.Start()
+		routines = append(routines, r)
+	}
+	assert.False(t, stopped())
+	c.dispose()
+	assert.True(t, stopped(), "all routines stop with the context")
+	routines[0].Stop() // does not block once the routine stopped
+
+	// contexts of failed replays stop their routines too
+	v.Page("/", func(c *Context) {
+		c.OnInterval(time.Millisecond, tick).Start()
+		c.View(func() h.H { return h.Div() })
+	})
+	assert.True(t, stopped(), "routine of the registration context stopped")
+	_, err := v.Replay(Recording{Route: "/", RequestURI: "/", Actions: []RecordedAction{{Action: 9}}})
+	assert.Error(t, err)
+	assert.True(t, stopped(), "routine of the replay context stopped")
+}
+
+func TestOptimistic(t *testing.T) {
+	var ctx *Context
+	var likes *signal
+	var like *actionTrigger
+	v := New()
+	v.Page("/", func(c *Context) {
+		ctx = c
+		likes = c.Signal(5)
+		like = c.Action(func() { panic("db down") })
+		c.View(func() h.H { return h.Button(like.OnClick(Optimistic(likes, "$"+likes.ID()+"+1"))) })
+	})
+	v.mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	html, _ := h.Render(like.OnClick(Optimistic(likes, "$"+likes.ID()+"+1")))
+	assert.Equal(t, fmt.Sprintf(` data-on:click="@get(&#39;/_action/%[1]s?reconcile=%[2]s&#39;);$%[2]s=($%[2]s+1)"`, like.id, likes.ID()), html)
+
+	// the action failed, so the server rolls back the optimistic change
+	req := httptest.NewRequest("GET", "/_action/"+like.id+"?reconcile="+likes.ID()+"&datastar="+
+		url.QueryEscape(`{"via-ctx":"`+ctx.id+`","`+likes.ID()+`":5}`), nil)
+	v.mux.ServeHTTP(httptest.NewRecorder(), req)
+	p := <-ctx.patchChan
+	assert.Equal(t, patchType(patchTypeSignals), p.typ)
+	assert.Equal(t, `{"`+likes.ID()+`":5}`, p.content)
+}
+
+func TestSyncSignalAndID(t *testing.T) {
+	var ctx *Context
+	var count, other *signal
+	var list *ListSignal
+	views, regions := 0, 0
+	v := New()
+	v.Config(Options{SSE: SSEOptions{PatchBuffer: 4}})
+	v.Page("/{$}", func(c *Context) {
+		ctx = c
+		count = c.Signal(1)
+		other = c.Signal("a")
+		list = c.ListSignal()
+		total := c.Region("total", func() h.H {
+			regions++
+			return h.Text(count.String())
+		})
+		c.View(func() h.H {
+			views++
+			return h.Div(total())
+		})
+	})
+	v.mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	ctx.prepareSignalsForPatch() // the initial values are in the browser
+	views, regions = 0, 0
+
+	count.SetValue(5)
+	other.SetValue("b")
+	ctx.SyncSignal(count)
+	p := <-ctx.patchChan
+	assert.Equal(t, `{"`+count.ID()+`":5}`, p.content)
+	ctx.SyncSignals()
+	p = <-ctx.patchChan
+	assert.Equal(t, `{"`+other.ID()+`":"b"}`, p.content)
+
+	ctx.SyncSignal(list) // unchanged collections are not sent
+	assert.Len(t, ctx.patchChan, 0)
+
+	ctx.SyncID("total")
+	p = <-ctx.patchChan
+	assert.Equal(t, patchType(patchTypeElements), p.typ)
+	assert.Equal(t, `<div id="total">5</div>`, p.content)
+	assert.Equal(t, 0, views)
+	assert.Equal(t, 1, regions)
+
+	ctx.SyncID("missing")
+	assert.Len(t, ctx.patchChan, 0)
+}
+
+func TestSyncElementsPatchMode(t *testing.T) {
+	var ctx *Context
+	v := New()
+	v.Page("/{$}", func(c *Context) {
+		ctx = c
+		c.View(func() h.H { return h.Ul(h.ID("log")) })
+	})
+	v.mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	tests := []struct {
+		name     string
+		elem     []h.H
+		content  string
+		selector string
+		mode     datastar.ElementPatchMode
+	}{
+		{"morph", []h.H{h.Li(h.ID("a"))}, `<li id="a"></li>`, "", ""},
+		{"append", []h.H{h.Li(), PatchTarget("#log"), PatchAppend}, `<li></li>`, "#log", datastar.ElementPatchModeAppend},
+		{"prepend", []h.H{PatchPrepend, PatchTarget("#log"), h.Li()}, `<li></li>`, "#log", datastar.ElementPatchModePrepend},
+		{"inner", []h.H{h.Li(), PatchTarget("#log"), PatchInner}, `<li></li>`, "#log", datastar.ElementPatchModeInner},
+		{"remove", []h.H{PatchTarget("#log"), PatchRemove}, ``, "#log", datastar.ElementPatchModeRemove},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx.SyncElements(tt.elem...)
+			p := <-ctx.patchChan
+			assert.Equal(t, tt.content, p.content)
+			assert.Equal(t, tt.selector, p.selector)
+			assert.Equal(t, tt.mode, p.mode)
+		})
+	}
+
+	// inserting modes need a target
+	ctx.SyncElements(h.Li(), PatchAfter)
+	assert.Len(t, ctx.patchChan, 0)
+}
+
+func TestDebug(t *testing.T) {
+	var ctx *Context
+	v := New()
+	v.Page("/{$}", func(c *Context) {
+		ctx = c
+		c.View(func() h.H { return h.Div() })
+	})
+	v.mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	// outside of DevMode lines are only logged
+	ctx.Debug("loaded", "count", 2)
+	assert.Len(t, ctx.patchChan, 0)
+
+	v.cfg.DevMode = true
+	ctx.Debug("loaded </script>", "count", 2, "ok", true, "odd")
+	p := <-ctx.patchChan
+	assert.Equal(t, patchType(patchTypeScript), p.typ)
+	assert.Equal(t, `console.debug("[via] ctx=`+ctx.id+`","loaded \u003c/script\u003e",{"!BADKEY":"odd","count":2,"ok":true})`, p.content)
+
+	ctx.Debug("plain")
+	p = <-ctx.patchChan
+	assert.Equal(t, `console.debug("[via] ctx=`+ctx.id+`","plain")`, p.content)
+}
+
+func TestMetrics(t *testing.T) {
+	var ctx *Context
+	var count *signal
+	var inc *actionTrigger
+	v := New()
+	v.Config(Options{SSE: SSEOptions{PatchBuffer: 4}})
+	v.Page("/{$}", func(c *Context) {
+		ctx = c
+		count = c.Signal(0)
+		inc = c.Action(func() {
+			count.SetValue(count.Int() + 1)
+			c.Sync()
+		})
+		c.View(func() h.H { return h.Div(count.Text()) })
+	})
+	v.mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	ctx.prepareSignalsForPatch()
+
+	req := httptest.NewRequest("GET", "/_action/"+inc.id+"?datastar="+
+		url.QueryEscape(`{"via-ctx":"`+ctx.id+`","`+count.ID()+`":0}`), nil)
+	v.mux.ServeHTTP(httptest.NewRecorder(), req)
+	// flush the patches like the SSE stream does
+	elements, signals := <-ctx.patchChan, <-ctx.patchChan
+	ctx.metrics.flushed(elements, time.Now())
+	ctx.metrics.flushed(signals, time.Now())
+
+	m := ctx.Metrics()
+	assert.Equal(t, ctx.id, m.ContextID)
+	assert.Equal(t, "/{$}", m.Route)
+	assert.Equal(t, int64(2), m.Renders) // page and sync
+	assert.Equal(t, int64(1), m.ElementPatches)
+	assert.Equal(t, len(elements.content), m.LastElementBytes)
+	assert.Equal(t, int64(1), m.SignalPatches)
+	assert.Equal(t, 1, m.LastSignals)
+	assert.Greater(t, m.LastActionLatency, time.Duration(0))
+
+	w := httptest.NewRecorder()
+	v.MetricsHandler()(w, httptest.NewRequest("GET", "/metrics", nil))
+	var served []PageMetrics
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &served))
+	assert.Equal(t, []PageMetrics{ctx.Metrics()}, served)
+	assert.Equal(t, patchType(patchTypeSignals), ctx.metricsPatch().typ)
+}
+
+func TestNamedRoutes(t *testing.T) {
+	v := New()
+	v.PageNamed("home", "/{$}", func(c *Context) {
+		c.View(func() h.H { return h.A(h.LinkTo(c, "user-detail", 42), h.Text("user")) })
+	})
+	v.PageNamed("user-detail", "/users/{id}", func(c *Context) { c.View(func() h.H { return h.Div() }) })
+	v.PageNamed("file", "/files/{owner}/{path...}", func(c *Context) { c.View(func() h.H { return h.Div() }) })
+	admin := v.Group("/admin", nil)
+	admin.PageNamed("admin-user", "/users/{id}", func(c *Context) { c.View(func() h.H { return h.Div() }) })
+
+	tests := []struct {
+		name   string
+		params []any
+		want   string
+	}{
+		{"home", nil, "/"},
+		{"user-detail", []any{42}, "/users/42"},
+		{"user-detail", []any{"a b/c"}, "/users/a%20b%2Fc"},
+		{"file", []any{"ann", "docs/a.txt"}, "/files/ann/docs/a.txt"},
+		{"file", []any{"ann", "/docs/a b?.txt"}, "/files/ann/docs/a%20b%3F.txt"},
+		{"file", []any{"ann", "//evil.example/x"}, "/files/ann/evil.example/x"},
+		{"admin-user", []any{"7"}, "/admin/users/7"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, v.URL(tt.name, tt.params...))
+	}
+
+	w := httptest.NewRecorder()
+	v.mux.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.Contains(t, w.Body.String(), `<a href="/users/42">user</a>`)
+
+	assert.Panics(t, func() { v.URL("missing") })
+	assert.Panics(t, func() { v.URL("user-detail") })
+	assert.Panics(t, func() { v.URL("user-detail", 1, 2) })
+	assert.Panics(t, func() { v.PageNamed("home", "/home", func(c *Context) {}) })
+
+	// links to pages registered later are checked on start
+	assert.NoError(t, v.checkLinks())
+	v.Page("/broken", func(c *Context) {
+		c.View(func() h.H {
+			return h.Div(h.A(h.LinkTo(c, "missing")), h.A(h.LinkTo(c, "later", 1, 2)), h.A(h.LinkTo(c, "later", 1)))
+		})
+	})
+	v.PageNamed("later", "/later/{id}", func(c *Context) { c.View(func() h.H { return h.Div() }) })
+	err := v.ServeListener(nil)
+	assert.ErrorContains(t, err, `unknown route name "missing"`)
+	assert.ErrorContains(t, err, `route "later" (/later/{id}) takes 1 params, got 2`)
+	assert.NotContains(t, err.Error(), "got 1")
+}
+
+func TestPageForm(t *testing.T) {
+	var ctx *Context
+	var submitted []url.Values
+	v := New()
+	v.PageForm("/signup", func(c *Context) {
+		ctx = c
+		c.View(func() h.H {
+			return c.Form(h.Input(h.Attr("name", "email")), h.P(h.Text("count="+strconv.Itoa(len(submitted)))))
+		})
+	}, func(c *Context, form url.Values) {
+		submitted = append(submitted, form)
+		if form.Get("email") == "" {
+			return
+		}
+		c.Redirect("/welcome")
+	})
+
+	w := httptest.NewRecorder()
+	v.mux.ServeHTTP(w, httptest.NewRequest("GET", "/signup", nil))
+	body := w.Body.String()
+	var postTo func(path, form string, header ...string) *httptest.ResponseRecorder
+	assert.Contains(t, body, `<form method="post" data-on:submit__prevent="@post(&#39;/_form&#39;, {contentType: &#39;form&#39;})"><input type="hidden" name="via-ctx" value="`+ctx.id+`">`)
+
+	post := func(form string, header ...string) *httptest.ResponseRecorder {
+		return postTo("/signup", form, header...)
+	}
+	postTo = func(path, form string, header ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", path, strings.NewReader(form))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		for i := 0; i < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		w := httptest.NewRecorder()
+		v.mux.ServeHTTP(w, req)
+		return w
+	}
+
+	// without JS
+	w = post("via-ctx=" + ctx.id + "&email=a%40b.c")
+	assert.Equal(t, http.StatusSeeOther, w.Code)
+	assert.Equal(t, "/welcome", w.Header().Get("Location"))
+	assert.Equal(t, url.Values{"email": {"a@b.c"}}, submitted[0])
+	w = post("email=")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "count=2")
+	w = post("email=a%40b.c", "Sec-Fetch-Site", "cross-site")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Len(t, submitted, 2)
+
+	// with JS
+	w = postTo("/_form", "via-ctx="+ctx.id+"&email=x%40y.z")
+	assert.Equal(t, http.StatusOK, w.Code)
+	if assert.Len(t, submitted, 3) {
+		assert.Equal(t, url.Values{"email": {"x@y.z"}}, submitted[2])
+	}
+	signals := 0
+	ctx.signals.Range(func(_, _ any) bool { signals++; return true })
+	assert.Zero(t, signals, "form values must not be kept in signals")
+	w = postTo("/_form", "via-ctx="+ctx.id+"&email=x%40y.z", "Sec-Fetch-Site", "cross-site")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = postTo("/_form", "via-ctx=unknown&email=x%40y.z")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Len(t, submitted, 3)
+
+	assert.Panics(t, func() { newContext("", "", v).Form() })
+}
+
+func TestNoJSFallback(t *testing.T) {
+	var ctx *Context
+	var name *signal
+	var inc, leave *actionTrigger
+	count := 0
+	v := New()
+	v.Config(Options{NoJSFallback: true})
+	v.Page("/{$}", func(c *Context) {
+		ctx = c
+		name = c.Signal("")
+		inc = c.Action(func() { count++ })
+		leave = c.Action(func() { c.Redirect("/bye") })
+		c.View(func() h.H {
+			return h.Div(h.Input(name.Bind()), h.Button(inc.OnClick()), h.Button(leave.OnClick()),
+				h.P(h.Textf("%s %d", name.String(), count)))
+		})
+	})
+	w := httptest.NewRecorder()
+	v.mux.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	body := w.Body.String()
+	assert.Contains(t, body, `<body><form id="via-nojs" method="post" action="/_via/action" hidden><button type="submit" disabled></button><input type="hidden" name="via-ctx" value="`+ctx.id+`"></form>`)
+	assert.Contains(t, body, `<input data-bind="`+name.ID()+`" form="via-nojs" name="`+name.ID()+`">`)
+	assert.Contains(t, body, `form="via-nojs" name="via-action" value="`+inc.id+`"`)
+	assert.Contains(t, body, "window.viaLive")
+
+	post := func(form url.Values, header ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", noJSPath, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		for i := 0; i < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		w := httptest.NewRecorder()
+		v.mux.ServeHTTP(w, req)
+		return w
+	}
+	w = post(url.Values{"via-ctx": {ctx.id}, "via-action": {inc.id}, name.ID(): {"Ann"}, "unknown": {"x"}})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "<p>Ann 1</p>")
+	assert.Contains(t, w.Body.String(), `value="`+ctx.id+`"`)
+
+	w = post(url.Values{"via-ctx": {ctx.id}, "via-action": {leave.id}})
+	assert.Equal(t, http.StatusSeeOther, w.Code)
+	assert.Equal(t, "/bye", w.Header().Get("Location"))
+
+	w = post(url.Values{"via-ctx": {"expired"}, "via-action": {inc.id}}, "Referer", "http://example.com/page?x=1")
+	assert.Equal(t, http.StatusSeeOther, w.Code)
+	assert.Equal(t, "/page?x=1", w.Header().Get("Location"))
+
+	w = post(url.Values{"via-ctx": {ctx.id}, "via-action": {inc.id}}, "Sec-Fetch-Site", "cross-site")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, 1, count)
+}
+
+// benchmarkContext returns the context of a dashboard page with a table of 100 rows and
+// 10 signals.
+func benchmarkContext(b *testing.B) (*Context, []*signal) {
+	var ctx *Context
+	var sigs []*signal
+	v := New()
+	v.Config(Options{SSE: SSEOptions{PatchBuffer: 4}})
+	v.Page("/", func(c *Context) {
+		ctx = c
+		for i := range 10 {
+			sigs = append(sigs, c.Signal(i))
+		}
+		c.View(func() h.H {
+			rows := make([]h.H, 100)
+			for i := range rows {
+				rows[i] = h.Tr(h.Td(h.Textf("row %d", i)), h.Td(sigs[i%10].Text()))
+			}
+			return h.Table(h.TBody(rows...))
+		})
+	})
+	v.mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	return ctx, sigs
+}
+
+func BenchmarkSync(b *testing.B) {
+	ctx, sigs := benchmarkContext(b)
+	b.ReportAllocs()
+	for i := 0; b.Loop(); i++ {
+		sigs[0].SetValue(i)
+		ctx.Sync()
+		<-ctx.patchChan
+		<-ctx.patchChan
+	}
+}
+
+func BenchmarkSyncSignals(b *testing.B) {
+	ctx, sigs := benchmarkContext(b)
+	b.ReportAllocs()
+	for i := 0; b.Loop(); i++ {
+		sigs[0].SetValue(i)
+		ctx.SyncSignals()
+		<-ctx.patchChan
+	}
+}
+
+func BenchmarkSyncElements(b *testing.B) {
+	ctx, _ := benchmarkContext(b)
+	el := h.Div(h.ID("status"), h.P(h.Text("All systems operational")))
+	b.ReportAllocs()
+	for b.Loop() {
+		ctx.SyncElements(el)
+		<-ctx.patchChan
+	}
+}
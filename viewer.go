@@ -0,0 +1,149 @@
+package via
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/go-via/via/h"
+)
+
+// viewerGrant is a view-only share of a page context, issued with Context.ViewerURL.
+type viewerGrant struct {
+	owner   *Context
+	allowed []string
+}
+
+// viewerGrants holds the grants of the app by token.
+type viewerGrants struct {
+	mu     sync.RWMutex
+	grants map[string]*viewerGrant
+}
+
+// ViewerURL returns an absolute URL that opens a read-only view of this page, e.g. to
+// share a screen with another user. Viewers see the view of this context and receive
+// its patches live, but their actions are rejected, except the given allowed actions,
+// which run on this context. The URL stays valid until this context is disposed.
+//
+// Example:
+//
+//	share := c.Action(func() {
+//		shareURL.SetValue(c.ViewerURL())
+//		c.SyncSignals()
+//	})
+func (c *Context) ViewerURL(allowed ...*actionTrigger) string {
+	if c.isComponent() {
+		return c.parentPageCtx.ViewerURL(allowed...)
+	}
+	grant := &viewerGrant{owner: c}
+	for _, a := range allowed {
+		if a != nil {
+			grant.allowed = append(grant.allowed, a.id)
+		}
+	}
+	token := c.app.genContextID()
+	g := &c.app.viewerGrants
+	g.mu.Lock()
+	if g.grants == nil {
+		g.grants = make(map[string]*viewerGrant)
+	}
+	g.grants[token] = grant
+	g.mu.Unlock()
+
+	c.ctxMu.Lock()
+	life := c.lifeContext()
+	c.ctxMu.Unlock()
+	context.AfterFunc(life, func() {
+		g.mu.Lock()
+		delete(g.grants, token)
+		g.mu.Unlock()
+	})
+	return c.baseURL + "/_viewer/" + token
+}
+
+// serveViewer serves the read-only view of the context shared with the token.
+func (v *V) serveViewer(w http.ResponseWriter, r *http.Request) {
+	v.viewerGrants.mu.RLock()
+	grant, ok := v.viewerGrants.grants[r.PathValue("token")]
+	v.viewerGrants.mu.RUnlock()
+	if !ok || grant.owner.tenant != v.tenant(r) {
+		http.NotFound(w, r)
+		return
+	}
+	owner := grant.owner
+
+	c := v.newPageContext(w, r, fmt.Sprintf("%s_/%s", owner.route, v.genContextID()), owner.route)
+	c.viewing = owner
+	c.title, c.headTags = owner.title, owner.headTags
+	// the view of the owner is rendered as is and its context ID replaced, see hideOwner
+	c.view = func() h.H { return owner.view() }
+	owner.mu.RLock()
+	for _, id := range grant.allowed {
+		if f, ok := owner.actionRegistry[id]; ok {
+			c.actionRegistry[id] = f
+			if d, ok := owner.actionTimeouts[id]; ok {
+				c.actionTimeouts[id] = d
+			}
+		}
+	}
+	owner.mu.RUnlock()
+
+	owner.mu.Lock()
+	owner.viewers = append(owner.viewers, c)
+	owner.mu.Unlock()
+	c.ctxMu.Lock()
+	life := c.lifeContext()
+	c.ctxMu.Unlock()
+	context.AfterFunc(life, func() {
+		owner.mu.Lock()
+		defer owner.mu.Unlock()
+		owner.viewers = slices.DeleteFunc(owner.viewers, func(viewer *Context) bool { return viewer == c })
+	})
+
+	v.servePage(w, r, c)
+}
+
+// hideOwner replaces the ID of the shared context in HTML and patches rendered for a
+// viewer with the ID of the viewer, so viewers never learn the ID that authorizes
+// requests to the shared context, e.g. in upload URLs. Elements of the shared view keep
+// matching the patches sent to the viewer.
+func (c *Context) hideOwner(s string) string {
+	owner := c.viewing
+	s = strings.ReplaceAll(s, owner.id, c.id)
+	return strings.ReplaceAll(s, url.QueryEscape(owner.id), url.QueryEscape(c.id))
+}
+
+// syncViewer sends the view and all signals of the shared context to a viewer that
+// connected, leaving the pending changes of the shared context untouched.
+func (c *Context) syncViewer() {
+	html, err := c.renderView()
+	if err != nil {
+		c.app.logErr(c, "sync view failed: %v", err)
+		c.app.reportErr(c, PhaseRender, err)
+		return
+	}
+	c.sendPatch(patch{typ: patchTypeElements, content: html})
+	sigs := make(map[string]any)
+	c.viewing.mu.RLock()
+	c.viewing.signals.Range(func(sigID, value any) bool {
+		switch sig := value.(type) {
+		case *signal:
+			if sig.err == nil {
+				sigs[sigID.(string)] = sig.val
+			}
+		case collectionSignal:
+			sigs[sigID.(string)] = sig.snapshot()
+		}
+		return true
+	})
+	c.viewing.mu.RUnlock()
+	if len(sigs) != 0 {
+		outgoingSigs, _ := json.Marshal(sigs)
+		c.sendPatch(patch{typ: patchTypeSignals, content: string(outgoingSigs)})
+	}
+}
@@ -0,0 +1,78 @@
+package via
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxWebhookBodySize limits the size of webhook payloads.
+const maxWebhookBodySize = 5 << 20
+
+// WebhookVerifier verifies that a webhook request was sent by the expected sender,
+// usually by checking a signature of the payload.
+type WebhookVerifier func(r *http.Request, payload []byte) error
+
+// ErrInvalidSignature is returned by WebhookVerifiers for requests with a missing or
+// invalid signature.
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// HMACSHA256Verifier returns a WebhookVerifier that checks the hex encoded HMAC-SHA256
+// of the payload, keyed with secret, sent in the given header after the given prefix.
+// e.g. HMACSHA256Verifier("X-Hub-Signature-256", "sha256=", secret) for GitHub.
+func HMACSHA256Verifier(header, prefix string, secret []byte) WebhookVerifier {
+	return func(r *http.Request, payload []byte) error {
+		sig, ok := strings.CutPrefix(r.Header.Get(header), prefix)
+		if !ok {
+			return ErrInvalidSignature
+		}
+		got, err := hex.DecodeString(sig)
+		if err != nil {
+			return ErrInvalidSignature
+		}
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(payload)
+		if !hmac.Equal(got, mac.Sum(nil)) {
+			return ErrInvalidSignature
+		}
+		return nil
+	}
+}
+
+// Webhook registers a POST route that receives webhooks from external services, e.g.
+// payment providers or CI servers. Requests are verified with verify, then handle maps
+// the payload to the live contexts to update, so the event shows up in open pages.
+// Requests that fail verification are answered with 401 Unauthorized.
+//
+// Example:
+//
+//	v.Webhook("/hooks/ci", via.HMACSHA256Verifier("X-Signature", "", secret),
+//		func(payload []byte) via.LiveUpdate {
+//			var build Build
+//			_ = json.Unmarshal(payload, &build)
+//			builds.Store(build)
+//			return via.LiveUpdate{Routes: []string{"/builds"}}
+//		})
+func (v *V) Webhook(route string, verify WebhookVerifier, handle func(payload []byte) LiveUpdate) {
+	v.HandleFunc("POST "+route, func(w http.ResponseWriter, r *http.Request) {
+		payload, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBodySize))
+		if err != nil {
+			v.logWarn(nil, "webhook %s failed: %v", route, err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if verify != nil {
+			if err := verify(r, payload); err != nil {
+				v.logWarn(nil, "webhook %s rejected: %v", route, err)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+		v.applyLiveUpdate(handle(payload))
+		w.WriteHeader(http.StatusNoContent)
+	})
+}